@@ -0,0 +1,246 @@
+package main
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"net/http"
+	"net/mail"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/flosch/pongo2/v4"
+)
+
+// playerRole is the role of self-registered accounts. Players can keep a
+// checklist and submit variations for review but have no staff privileges.
+const playerRole = "player"
+
+// verificationTTL is how long an email verification link stays valid
+const verificationTTL = 24 * time.Hour
+
+var usernamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]{3,32}$`)
+
+// reservedUsernames cannot be registered; "default" is the checklist template's user_id
+var reservedUsernames = map[string]bool{checklistTemplateUser: true, "admin": true}
+
+// isStaffRole reports whether role is a staff role (author, mod or admin)
+func isStaffRole(role string) bool {
+	return role == "author" || role == "mod" || role == "admin"
+}
+
+// validateRegistration returns a message describing what is wrong with a
+// registration form, or "" when it is valid
+func validateRegistration(username, email, password, confirm string) string {
+	switch {
+	case !usernamePattern.MatchString(username):
+		return "Username must be 3–32 letters, digits, underscores or dashes."
+	case reservedUsernames[strings.ToLower(username)]:
+		return "That username is not available."
+	case email == "":
+		return "Email is required."
+	case len(password) < 8:
+		return "Password must be at least 8 characters."
+	case password != confirm:
+		return "Passwords do not match."
+	}
+	if addr, err := mail.ParseAddress(email); err != nil || addr.Address != email {
+		return "Enter a valid email address."
+	}
+	return ""
+}
+
+// ensureColumn adds a column to a table of the admin database if it is missing
+func ensureColumn(db *sql.DB, table, column, definition string) error {
+	rows, err := db.Query(fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var cid, notNull, pk int
+		var name, typ string
+		var dflt sql.NullString
+		if err := rows.Scan(&cid, &name, &typ, &notNull, &dflt, &pk); err != nil {
+			return err
+		}
+		if name == column {
+			return nil
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	_, err = db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, definition))
+	return err
+}
+
+// newToken returns a random URL-safe token
+func newToken() string {
+	b := make([]byte, 32)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// siteURL returns the scheme and host links in emails point to
+func siteURL(r *http.Request) string {
+	scheme := "https"
+	if strings.HasPrefix(r.Host, "localhost") || strings.HasPrefix(r.Host, "127.0.0.1") {
+		scheme = "http"
+	}
+	return scheme + "://" + r.Host
+}
+
+// sendVerificationEmail sends the link a new player confirms their email with
+func sendVerificationEmail(toEmail, username, verifyURL string) error {
+	subject := "Confirm your email - PokeMMO Raid Book"
+	body := fmt.Sprintf(`Hello %s,
+
+Thanks for creating an account. Click the link below to confirm your email address:
+%s
+
+This link will expire in 24 hours.
+
+If you did not create an account, please ignore this email.
+
+Best regards,
+PokeMMO Raid Book Team`, username, verifyURL)
+	return sendEmail(toEmail, subject, body)
+}
+
+// startVerification replaces a user's pending verification links with a new one and emails it
+func (a *App) startVerification(r *http.Request, username, email string) error {
+	token := newToken()
+	if _, err := a.adminDB.Exec("DELETE FROM email_verifications WHERE username = ?", username); err != nil {
+		return err
+	}
+	expires := time.Now().Add(verificationTTL).Unix()
+	if _, err := a.adminDB.Exec("INSERT INTO email_verifications (username, token, expires_at) VALUES (?, ?, ?)", username, token, expires); err != nil {
+		return err
+	}
+	verifyURL := fmt.Sprintf("%s/auth/verify?token=%s", siteURL(r), token)
+	if err := sendVerificationEmail(email, username, verifyURL); err != nil {
+		log.Printf("Failed to send verification email to %s: %v", email, err)
+	}
+	return nil
+}
+
+// authRegisterHandler serves the registration form (GET) and creates an
+// unverified player account (POST)
+func (a *App) authRegisterHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		renderTemplate(w, a.templates["auth_register.html"], pongo2.Context{"commit_hash": a.commitHash})
+	case http.MethodPost:
+		username := strings.TrimSpace(r.FormValue("username"))
+		email := strings.TrimSpace(r.FormValue("email"))
+		password := r.FormValue("password")
+		confirm := r.FormValue("confirm_password")
+		fail := func(msg string) {
+			renderTemplate(w, a.templates["auth_register.html"], pongo2.Context{
+				"commit_hash": a.commitHash,
+				"error":       msg,
+				"username":    username,
+				"email":       email,
+			})
+		}
+		if msg := validateRegistration(username, email, password, confirm); msg != "" {
+			fail(msg)
+			return
+		}
+
+		var taken int
+		row := a.adminDB.QueryRow("SELECT COUNT(1) FROM users WHERE username = ? COLLATE NOCASE OR email = ? COLLATE NOCASE", username, email)
+		if err := row.Scan(&taken); err != nil {
+			http.Error(w, "db error", http.StatusInternalServerError)
+			return
+		}
+		if taken > 0 {
+			fail("That username or email is already registered.")
+			return
+		}
+
+		hash, err := bcryptGenerateHash(password)
+		if err != nil {
+			http.Error(w, "failed to hash password", http.StatusInternalServerError)
+			return
+		}
+		if _, err := a.adminDB.Exec("INSERT INTO users (username, password_hash, role, email, email_verified) VALUES (?, ?, ?, ?, 0)",
+			username, hash, playerRole, email); err != nil {
+			fail("That username or email is already registered.")
+			return
+		}
+		if err := a.startVerification(r, username, email); err != nil {
+			http.Error(w, "failed to create verification link", http.StatusInternalServerError)
+			return
+		}
+		renderTemplate(w, a.templates["auth_reset_sent.html"], pongo2.Context{
+			"title":   "Check Your Email",
+			"message": "Your account has been created. Follow the link we sent to " + email + " to confirm your email, then sign in.",
+		})
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// authVerifyHandler confirms a player's email from the emailed link
+func (a *App) authVerifyHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	token := r.URL.Query().Get("token")
+	if token == "" {
+		http.Error(w, "token required", http.StatusBadRequest)
+		return
+	}
+	var username string
+	var expires int64
+	row := a.adminDB.QueryRow("SELECT username, expires_at FROM email_verifications WHERE token = ?", token)
+	if err := row.Scan(&username, &expires); err != nil {
+		http.Error(w, "invalid or already used verification link", http.StatusBadRequest)
+		return
+	}
+	if time.Now().Unix() > expires {
+		http.Error(w, "verification link has expired, request a new one from the sign in page", http.StatusBadRequest)
+		return
+	}
+	if _, err := a.adminDB.Exec("UPDATE users SET email_verified = 1 WHERE username = ?", username); err != nil {
+		http.Error(w, "failed to verify email", http.StatusInternalServerError)
+		return
+	}
+	_, _ = a.adminDB.Exec("DELETE FROM email_verifications WHERE username = ?", username)
+	renderTemplate(w, a.templates["auth_reset_sent.html"], pongo2.Context{
+		"title":   "Email Confirmed",
+		"message": "Thanks, your email is confirmed. You can now sign in.",
+	})
+}
+
+// authVerifyResendHandler emails a new verification link to an unverified player
+func (a *App) authVerifyResendHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	username := strings.TrimSpace(r.FormValue("username"))
+	if username == "" {
+		http.Error(w, "username required", http.StatusBadRequest)
+		return
+	}
+	var email sql.NullString
+	var verified bool
+	row := a.adminDB.QueryRow("SELECT email, email_verified FROM users WHERE username = ? AND role = ?", username, playerRole)
+	if err := row.Scan(&email, &verified); err == nil && !verified && email.String != "" {
+		if err := a.startVerification(r, username, email.String); err != nil {
+			log.Printf("Failed to create verification link for %s: %v", username, err)
+		}
+	}
+	// do not leak whether the account exists
+	renderTemplate(w, a.templates["auth_reset_sent.html"], pongo2.Context{
+		"title":   "Check Your Email",
+		"message": "If the account exists and is not yet confirmed, a new verification link has been sent to its email.",
+	})
+}
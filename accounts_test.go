@@ -0,0 +1,25 @@
+package main
+
+import "testing"
+
+func TestValidateRegistration(t *testing.T) {
+	tests := []struct {
+		name, username, email, password, confirm string
+		ok                                       bool
+	}{
+		{"valid", "ash_ketchum", "ash@example.com", "pikachu123", "pikachu123", true},
+		{"short username", "as", "ash@example.com", "pikachu123", "pikachu123", false},
+		{"bad username", "ash ketchum", "ash@example.com", "pikachu123", "pikachu123", false},
+		{"reserved", "Default", "ash@example.com", "pikachu123", "pikachu123", false},
+		{"bad email", "ash", "not-an-email", "pikachu123", "pikachu123", false},
+		{"named email", "ash", "Ash <ash@example.com>", "pikachu123", "pikachu123", false},
+		{"short password", "ash", "ash@example.com", "pika", "pika", false},
+		{"mismatch", "ash", "ash@example.com", "pikachu123", "pikachu124", false},
+	}
+	for _, tt := range tests {
+		msg := validateRegistration(tt.username, tt.email, tt.password, tt.confirm)
+		if (msg == "") != tt.ok {
+			t.Errorf("%s: got %q", tt.name, msg)
+		}
+	}
+}
@@ -0,0 +1,327 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// attemptLimiter limits how many attempts a user can log per hour
+var attemptLimiter = newRateLimiter(30, time.Hour)
+
+// AttemptDeviation is something a player did differently from the plan
+type AttemptDeviation struct {
+	Turn   int    `json:"turn" bson:"turn"`                         // 1-based
+	Player string `json:"player,omitempty" bson:"player,omitempty"` // P1–P4, empty for the whole party
+	Note   string `json:"note" bson:"note"`
+}
+
+// RaidAttempt is a logged run of a variation and how it went
+type RaidAttempt struct {
+	ID                primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	Season            string             `json:"season" bson:"season"`
+	BossID            string             `json:"boss_id" bson:"boss_id"`
+	VariationID       string             `json:"variation_id" bson:"variation_id"`
+	VariationRevision int                `json:"variation_revision" bson:"variation_revision"` // the revision that was played
+	Cleared           bool               `json:"cleared" bson:"cleared"`
+	TurnReached       int                `json:"turn_reached" bson:"turn_reached"` // last turn played, 1-based
+	Health            []float64          `json:"health" bson:"health"`             // actual boss HP % after each turn
+	Deviations        []AttemptDeviation `json:"deviations" bson:"deviations"`
+	LoggedBy          string             `json:"logged_by" bson:"logged_by"`
+	LoggedAt          time.Time          `json:"logged_at" bson:"logged_at"`
+}
+
+// VariationStats summarises the logged attempts of a variation. AvgTurns is the
+// average number of turns of cleared attempts.
+type VariationStats struct {
+	VariationID string  `json:"variation_id"`
+	Variation   int     `json:"variation"` // 1-based, as shown on the boss page
+	Attempts    int     `json:"attempts"`
+	Clears      int     `json:"clears"`
+	ClearRate   float64 `json:"clear_rate"` // 0–1
+	AvgTurns    float64 `json:"avg_turns"`
+}
+
+// attemptStore keeps raid attempts in the raid_attempts collection
+type attemptStore struct {
+	coll *mongo.Collection
+}
+
+func newAttemptStore(db *mongo.Database) *attemptStore {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	coll := db.Collection("raid_attempts")
+	_, err := coll.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "boss_id", Value: 1}, {Key: "variation_id", Value: 1}, {Key: "logged_at", Value: -1}},
+	})
+	if err != nil {
+		log.Printf("warning: failed to create raid_attempts index: %v", err)
+	}
+	return &attemptStore{coll: coll}
+}
+
+func (s *attemptStore) Create(ctx context.Context, at *RaidAttempt) error {
+	res, err := s.coll.InsertOne(ctx, at)
+	if err != nil {
+		return err
+	}
+	at.ID = res.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+// List returns the latest attempts matching filter, newest first
+func (s *attemptStore) List(ctx context.Context, filter bson.M, limit int64) ([]RaidAttempt, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "logged_at", Value: -1}}).SetLimit(limit)
+	cursor, err := s.coll.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	attempts := []RaidAttempt{}
+	if err := cursor.All(ctx, &attempts); err != nil {
+		return nil, err
+	}
+	return attempts, nil
+}
+
+// Outcomes returns the result fields of every attempt of a boss, enough for variationStats
+func (s *attemptStore) Outcomes(ctx context.Context, bossID string) ([]RaidAttempt, error) {
+	opts := options.Find().SetProjection(bson.M{"variation_id": 1, "cleared": 1, "turn_reached": 1})
+	cursor, err := s.coll.Find(ctx, bson.M{"boss_id": bossID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	attempts := []RaidAttempt{}
+	if err := cursor.All(ctx, &attempts); err != nil {
+		return nil, err
+	}
+	return attempts, nil
+}
+
+// variationStats summarises attempts for each variation of a boss, in the
+// boss's order; variations without attempts have zero stats
+func variationStats(boss RaidBoss, attempts []RaidAttempt) []VariationStats {
+	stats := make([]VariationStats, len(boss.Variations))
+	index := make(map[string]int, len(boss.Variations))
+	for i, v := range boss.Variations {
+		stats[i] = VariationStats{VariationID: v.ID, Variation: i + 1}
+		index[v.ID] = i
+	}
+	clearTurns := make([]int, len(stats))
+	for _, at := range attempts {
+		i, ok := index[at.VariationID]
+		if !ok {
+			continue
+		}
+		stats[i].Attempts++
+		if at.Cleared {
+			stats[i].Clears++
+			clearTurns[i] += at.TurnReached
+		}
+	}
+	for i := range stats {
+		if stats[i].Attempts > 0 {
+			stats[i].ClearRate = float64(stats[i].Clears) / float64(stats[i].Attempts)
+		}
+		if stats[i].Clears > 0 {
+			stats[i].AvgTurns = float64(clearTurns[i]) / float64(stats[i].Clears)
+		}
+	}
+	return stats
+}
+
+// sortVariationStats orders stats by "clear_rate", "attempts" or "avg_turns"
+// (fewest first, variations without clears last); ties keep the boss's order
+func sortVariationStats(stats []VariationStats, by string) error {
+	var less func(a, b VariationStats) bool
+	switch by {
+	case "", "variation":
+		return nil
+	case "clear_rate":
+		less = func(a, b VariationStats) bool {
+			if a.ClearRate != b.ClearRate {
+				return a.ClearRate > b.ClearRate
+			}
+			return a.Attempts > b.Attempts
+		}
+	case "attempts":
+		less = func(a, b VariationStats) bool { return a.Attempts > b.Attempts }
+	case "avg_turns":
+		less = func(a, b VariationStats) bool {
+			if (a.Clears == 0) != (b.Clears == 0) {
+				return b.Clears == 0
+			}
+			return a.AvgTurns < b.AvgTurns
+		}
+	default:
+		return fmt.Errorf("sort must be clear_rate, attempts or avg_turns")
+	}
+	sort.SliceStable(stats, func(i, j int) bool { return less(stats[i], stats[j]) })
+	return nil
+}
+
+// maxAttemptTurns bounds the turn a logged raid attempt can reach
+const maxAttemptTurns = 50
+
+// validateAttempt checks a logged raid attempt: a turn reached within
+// 1–maxAttemptTurns, no more boss HP entries than turns, HP within 0–100 and
+// deviations on a played turn with a P1–P4 player (or none) and a short note
+func validateAttempt(at RaidAttempt) []FieldError {
+	var errs []FieldError
+	add := func(field, format string, args ...interface{}) {
+		errs = append(errs, FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+	if at.TurnReached < 1 || at.TurnReached > maxAttemptTurns {
+		add("turn_reached", "must be between 1 and %d, got %d", maxAttemptTurns, at.TurnReached)
+	}
+	if len(at.Health) > at.TurnReached {
+		add("health", "has %d entries for %d turns", len(at.Health), at.TurnReached)
+	}
+	for i, h := range at.Health {
+		if h < 0 || h > 100 || math.IsNaN(h) {
+			add(fmt.Sprintf("health[%d]", i), "must be between 0 and 100, got %v", h)
+		}
+	}
+	if len(at.Deviations) > 20 {
+		add("deviations", "at most 20 deviations, got %d", len(at.Deviations))
+	}
+	for i, d := range at.Deviations {
+		field := fmt.Sprintf("deviations[%d].", i)
+		if d.Turn < 1 || d.Turn > at.TurnReached {
+			add(field+"turn", "must be between 1 and turn_reached, got %d", d.Turn)
+		}
+		if d.Player != "" && !slices.Contains(playerPositions[:], d.Player) {
+			add(field+"player", "must be P1, P2, P3 or P4, got %q", d.Player)
+		}
+		if d.Note == "" || len(d.Note) > 300 {
+			add(field+"note", "must be 1–300 characters")
+		}
+	}
+	return errs
+}
+
+// attemptsHandler lists recent attempts of a boss or variation (GET) or logs an
+// attempt of a variation of the current season for the signed-in user (POST)
+func (a *App) attemptsHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	switch r.Method {
+	case http.MethodGet:
+		filter := bson.M{"boss_id": r.URL.Query().Get("boss_id")}
+		if id := r.URL.Query().Get("variation_id"); id != "" {
+			filter["variation_id"] = id
+		}
+		attempts, err := a.attempts.List(ctx, filter, 50)
+		if err != nil {
+			log.Printf("Error listing attempts: %v", err)
+			http.Error(w, "failed to load attempts", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(attempts)
+	case http.MethodPost:
+		username := getUsernameFromRequest(r)
+		if username == "" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		var at RaidAttempt
+		if err := json.NewDecoder(r.Body).Decode(&at); err != nil {
+			http.Error(w, "invalid request body", http.StatusBadRequest)
+			return
+		}
+		current := a.store.Current()
+		boss := findBossByID(&current, at.BossID)
+		if boss == nil {
+			http.Error(w, errBossNotFound.Error(), http.StatusNotFound)
+			return
+		}
+		i := findVariationIndex(boss, at.VariationID)
+		if i < 0 {
+			http.Error(w, errVariationNotFound.Error(), http.StatusNotFound)
+			return
+		}
+		for j := range at.Deviations {
+			at.Deviations[j].Note = strings.TrimSpace(at.Deviations[j].Note)
+		}
+		if errs := validateAttempt(at); len(errs) > 0 {
+			writeValidationErrors(w, errs)
+			return
+		}
+		if !attemptLimiter.Allow(username) {
+			http.Error(w, "too many attempts logged, try again later", http.StatusTooManyRequests)
+			return
+		}
+
+		at.ID = primitive.NilObjectID
+		at.Season = a.getSeasonName()
+		at.VariationRevision = boss.Variations[i].Revision
+		at.LoggedBy = username
+		at.LoggedAt = time.Now()
+		if at.Health == nil {
+			at.Health = []float64{}
+		}
+		if at.Deviations == nil {
+			at.Deviations = []AttemptDeviation{}
+		}
+		if err := a.attempts.Create(ctx, &at); err != nil {
+			log.Printf("Error saving attempt: %v", err)
+			http.Error(w, "failed to save attempt", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(at)
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// attemptStatsHandler returns attempt count, clear rate and average turns to
+// clear for each variation of a boss, optionally sorted with ?sort=
+func (a *App) attemptStatsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	current := a.store.Current()
+	boss := findBossByID(&current, r.URL.Query().Get("boss_id"))
+	if boss == nil {
+		http.Error(w, errBossNotFound.Error(), http.StatusNotFound)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	attempts, err := a.attempts.Outcomes(ctx, boss.ID)
+	if err != nil {
+		log.Printf("Error listing attempts: %v", err)
+		http.Error(w, "failed to load attempts", http.StatusInternalServerError)
+		return
+	}
+	stats := variationStats(*boss, attempts)
+	if err := sortVariationStats(stats, r.URL.Query().Get("sort")); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"boss_id": boss.ID, "variations": stats})
+}
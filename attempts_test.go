@@ -0,0 +1,70 @@
+package main
+
+import "testing"
+
+func TestVariationStats(t *testing.T) {
+	boss := RaidBoss{Variations: []Variation{{ID: "v1"}, {ID: "v2"}, {ID: "v3"}}}
+	attempts := []RaidAttempt{
+		{VariationID: "v1", Cleared: true, TurnReached: 6},
+		{VariationID: "v1", Cleared: false, TurnReached: 3},
+		{VariationID: "v2", Cleared: true, TurnReached: 4},
+		{VariationID: "v2", Cleared: true, TurnReached: 5},
+		{VariationID: "removed", Cleared: true, TurnReached: 2},
+	}
+	stats := variationStats(boss, attempts)
+	if s := stats[0]; s.Attempts != 2 || s.Clears != 1 || s.ClearRate != 0.5 || s.AvgTurns != 6 {
+		t.Errorf("v1: %+v", s)
+	}
+	if s := stats[1]; s.Attempts != 2 || s.ClearRate != 1 || s.AvgTurns != 4.5 {
+		t.Errorf("v2: %+v", s)
+	}
+	if s := stats[2]; s.Attempts != 0 || s.Variation != 3 {
+		t.Errorf("v3: %+v", s)
+	}
+
+	order := func(by string) []string {
+		sorted := append([]VariationStats(nil), stats...)
+		if err := sortVariationStats(sorted, by); err != nil {
+			t.Fatal(err)
+		}
+		ids := make([]string, len(sorted))
+		for i, s := range sorted {
+			ids[i] = s.VariationID
+		}
+		return ids
+	}
+	for by, want := range map[string][]string{
+		"clear_rate": {"v2", "v1", "v3"},
+		"avg_turns":  {"v2", "v1", "v3"},
+		"attempts":   {"v1", "v2", "v3"},
+	} {
+		got := order(by)
+		for i := range want {
+			if got[i] != want[i] {
+				t.Errorf("%s: got %v, want %v", by, got, want)
+				break
+			}
+		}
+	}
+	if err := sortVariationStats(stats, "name"); err == nil {
+		t.Error("unknown sort accepted")
+	}
+}
+
+func TestValidateAttempt(t *testing.T) {
+	ok := RaidAttempt{TurnReached: 3, Health: []float64{70, 40}, Deviations: []AttemptDeviation{{Turn: 2, Player: "P3", Note: "missed"}}}
+	if errs := validateAttempt(ok); len(errs) != 0 {
+		t.Fatalf("valid attempt rejected: %+v", errs)
+	}
+	bad := RaidAttempt{TurnReached: 1, Health: []float64{120, 10}, Deviations: []AttemptDeviation{{Turn: 4, Player: "P5"}}}
+	want := []string{"health", "health[0]", "deviations[0].turn", "deviations[0].player", "deviations[0].note"}
+	errs := validateAttempt(bad)
+	if len(errs) != len(want) {
+		t.Fatalf("got %+v, want errors for %v", errs, want)
+	}
+	for i, e := range errs {
+		if e.Field != want[i] {
+			t.Errorf("error %d on %q, want %q", i, e.Field, want[i])
+		}
+	}
+}
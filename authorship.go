@@ -0,0 +1,139 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// stampVariation records editor as the last editor of v at now, and as its
+// creator when creating. Editors of existing variations keep the stored
+// creator, see keepAuthorship.
+func stampVariation(v *Variation, editor string, now time.Time, creating bool) {
+	v.UpdatedBy, v.UpdatedAt = editor, &now
+	if creating {
+		v.CreatedBy, v.CreatedAt = editor, &now
+	}
+}
+
+// keepAuthorship copies the creator of the stored variation prev into v, and
+// its last editor too when v did not change it
+func keepAuthorship(v *Variation, prev Variation, edited bool) {
+	v.CreatedBy, v.CreatedAt = prev.CreatedBy, prev.CreatedAt
+	if !edited {
+		v.UpdatedBy, v.UpdatedAt = prev.UpdatedBy, prev.UpdatedAt
+	}
+}
+
+// CreatedDate and UpdatedDate format the authorship timestamps for the boss page
+func (v Variation) CreatedDate() string { return formatDate(v.CreatedAt) }
+func (v Variation) UpdatedDate() string { return formatDate(v.UpdatedAt) }
+
+// Edited reports whether the variation was edited after it was created
+func (v Variation) Edited() bool {
+	return v.UpdatedAt != nil && (v.CreatedAt == nil || !v.UpdatedAt.Equal(*v.CreatedAt))
+}
+
+func formatDate(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format("2 Jan 2006")
+}
+
+// VariationAuthorship is a variation's authorship as listed in the admin panel
+type VariationAuthorship struct {
+	BossID      string     `json:"boss_id"`
+	BossName    string     `json:"boss_name"`
+	VariationID string     `json:"variation_id"`
+	Variation   int        `json:"variation"` // 1-based, as shown on the boss page
+	Revision    int        `json:"revision"`
+	CreatedBy   string     `json:"created_by"`
+	CreatedAt   *time.Time `json:"created_at"`
+	UpdatedBy   string     `json:"updated_by"`
+	UpdatedAt   *time.Time `json:"updated_at"`
+	Credits     string     `json:"credits"`
+}
+
+// variationAuthorFilter selects variations in the admin panel. Author matches the
+// creator, the last editor or the credits, ignoring case; Since keeps variations
+// updated on or after it. Empty fields match everything.
+type variationAuthorFilter struct {
+	Author string
+	BossID string
+	Since  time.Time
+}
+
+// listVariationAuthorship returns the authorship of the season's variations
+// matching f, in boss and variation order
+func listVariationAuthorship(season Season, f variationAuthorFilter) []VariationAuthorship {
+	author := strings.ToLower(strings.TrimSpace(f.Author))
+	rows := []VariationAuthorship{}
+	for _, b := range season.RaidBosses {
+		if f.BossID != "" && b.ID != f.BossID {
+			continue
+		}
+		for i, v := range b.Variations {
+			if author != "" &&
+				strings.ToLower(v.CreatedBy) != author &&
+				strings.ToLower(v.UpdatedBy) != author &&
+				!strings.Contains(strings.ToLower(v.Credits), author) {
+				continue
+			}
+			if !f.Since.IsZero() {
+				updated := v.UpdatedAt
+				if updated == nil {
+					updated = v.CreatedAt
+				}
+				if updated == nil || updated.Before(f.Since) {
+					continue
+				}
+			}
+			rows = append(rows, VariationAuthorship{
+				BossID:      b.ID,
+				BossName:    b.Name,
+				VariationID: v.ID,
+				Variation:   i + 1,
+				Revision:    v.Revision,
+				CreatedBy:   v.CreatedBy,
+				CreatedAt:   v.CreatedAt,
+				UpdatedBy:   v.UpdatedBy,
+				UpdatedAt:   v.UpdatedAt,
+				Credits:     v.Credits,
+			})
+		}
+	}
+	return rows
+}
+
+// adminVariationsHandler lists who created and last edited each variation of a
+// season, filtered by ?author=, ?boss_id= and ?since=YYYY-MM-DD
+func (a *App) adminVariationsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !isStaffRole(getRoleFromRequest(r)) {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	q := r.URL.Query()
+	season, ok := a.store.FindSeason(q.Get("season"))
+	if !ok {
+		http.Error(w, "season not found", http.StatusNotFound)
+		return
+	}
+	filter := variationAuthorFilter{Author: q.Get("author"), BossID: q.Get("boss_id")}
+	if since := q.Get("since"); since != "" {
+		t, err := time.Parse("2006-01-02", since)
+		if err != nil {
+			http.Error(w, "since must be a date like 2025-01-31", http.StatusBadRequest)
+			return
+		}
+		filter.Since = t
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(listVariationAuthorship(season, filter))
+}
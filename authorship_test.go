@@ -0,0 +1,94 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestUpdateBossInKeepsAuthorship(t *testing.T) {
+	created := time.Date(2025, 1, 10, 0, 0, 0, 0, time.UTC)
+	edited := time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+	stored := Variation{ID: "v1", Revision: 2, HealthRemaining: []float64{50}, CreatedBy: "ash", CreatedAt: &created, UpdatedBy: "misty", UpdatedAt: &edited}
+	seasons := []Season{{SeasonName: "Winter", Year: 2025, RaidBosses: []RaidBoss{{
+		ID: "b1", Revision: 1, Variations: []Variation{stored, {ID: "v2", Revision: 1, HealthRemaining: []float64{60}}},
+	}}}}
+
+	now := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	boss := RaidBoss{ID: "b1", Variations: []Variation{
+		{ID: "v1", HealthRemaining: []float64{50}},                           // unchanged
+		{ID: "v2", HealthRemaining: []float64{60}, Credits: "Discord guide"}, // credits added
+		{ID: "v3", HealthRemaining: []float64{70}},                           // new
+	}}
+	for i := range boss.Variations {
+		stampVariation(&boss.Variations[i], "brock", now, true)
+	}
+	if err := updateBossIn(seasons, seasonCode(seasons[0]), &boss, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	if v := boss.Variations[0]; v.CreatedBy != "ash" || v.UpdatedBy != "misty" || !v.UpdatedAt.Equal(edited) || v.Revision != 2 {
+		t.Errorf("unchanged variation: %+v", v)
+	}
+	if v := boss.Variations[1]; v.CreatedBy != "" || v.CreatedAt != nil || v.UpdatedBy != "brock" || v.Revision != 2 {
+		t.Errorf("edited variation: %+v", v)
+	}
+	if v := boss.Variations[2]; v.CreatedBy != "brock" || !v.CreatedAt.Equal(now) || v.Edited() {
+		t.Errorf("new variation: %+v", v)
+	}
+
+	update := Variation{ID: "v1", Revision: 3, HealthRemaining: []float64{40}}
+	stampVariation(&update, "gary", now, false)
+	if err := updateVariationIn(seasons, seasonCode(seasons[0]), "b1", &update, 2); err != nil {
+		t.Fatal(err)
+	}
+	if update.CreatedBy != "ash" || update.UpdatedBy != "gary" || !update.Edited() {
+		t.Errorf("updated variation: %+v", update)
+	}
+}
+
+func TestListVariationAuthorship(t *testing.T) {
+	jan := time.Date(2025, 1, 10, 0, 0, 0, 0, time.UTC)
+	mar := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	season := Season{RaidBosses: []RaidBoss{
+		{ID: "b1", Name: "Glaceon", Variations: []Variation{
+			{ID: "v1", CreatedBy: "Ash", CreatedAt: &jan, UpdatedBy: "Ash", UpdatedAt: &jan},
+			{ID: "v2", CreatedBy: "misty", CreatedAt: &jan, UpdatedBy: "ash", UpdatedAt: &mar},
+		}},
+		{ID: "b2", Name: "Leafeon", Variations: []Variation{
+			{ID: "v3", Credits: "Guide by Ashley on Discord"},
+			{ID: "v4", CreatedBy: "brock", CreatedAt: &mar, UpdatedBy: "brock", UpdatedAt: &mar},
+		}},
+	}}
+
+	ids := func(f variationAuthorFilter) []string {
+		var out []string
+		for _, row := range listVariationAuthorship(season, f) {
+			out = append(out, row.VariationID)
+		}
+		return out
+	}
+	for name, tc := range map[string]struct {
+		filter variationAuthorFilter
+		want   []string
+	}{
+		"all":    {variationAuthorFilter{}, []string{"v1", "v2", "v3", "v4"}},
+		"author": {variationAuthorFilter{Author: "ASH"}, []string{"v1", "v2", "v3"}},
+		"boss":   {variationAuthorFilter{BossID: "b2"}, []string{"v3", "v4"}},
+		"since":  {variationAuthorFilter{Since: time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)}, []string{"v2", "v4"}},
+	} {
+		got := ids(tc.filter)
+		if len(got) != len(tc.want) {
+			t.Errorf("%s: got %v, want %v", name, got, tc.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tc.want[i] {
+				t.Errorf("%s: got %v, want %v", name, got, tc.want)
+				break
+			}
+		}
+	}
+	if row := listVariationAuthorship(season, variationAuthorFilter{BossID: "b2"})[1]; row.Variation != 2 || row.BossName != "Leafeon" {
+		t.Errorf("row: %+v", row)
+	}
+}
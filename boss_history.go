@@ -0,0 +1,546 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Actions recorded in the boss revision history
+const (
+	actionCreateBoss      = "create_boss"
+	actionUpdateBoss      = "update_boss"
+	actionDeleteBoss      = "delete_boss"
+	actionCreateVariation = "create_variation"
+	actionUpdateVariation = "update_variation"
+	actionRestoreBoss     = "restore_boss"
+	actionBaseline        = "baseline" // the boss as it was when its history started
+)
+
+var errRevisionNotFound = errors.New("revision not found")
+
+// BossRevision is an immutable record of a boss after a change. Deletes store
+// the last state of the boss so it can be restored.
+type BossRevision struct {
+	ID          primitive.ObjectID `json:"-" bson:"_id,omitempty"`
+	BossID      string             `json:"boss_id" bson:"boss_id"`
+	Season      string             `json:"season" bson:"season"`
+	Revision    int                `json:"revision" bson:"revision"`
+	Action      string             `json:"action" bson:"action"`
+	VariationID string             `json:"variation_id,omitempty" bson:"variation_id,omitempty"`
+	Author      string             `json:"author" bson:"author"`
+	CreatedAt   time.Time          `json:"created_at" bson:"created_at"`
+	Snapshot    *RaidBoss          `json:"snapshot,omitempty" bson:"snapshot"`
+}
+
+// bossHistory stores boss revisions in the boss_revisions collection
+type bossHistory struct {
+	coll *mongo.Collection
+}
+
+func newBossHistory(db *mongo.Database) *bossHistory {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	coll := db.Collection("boss_revisions")
+	_, err := coll.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "boss_id", Value: 1}, {Key: "revision", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		log.Printf("warning: failed to create boss_revisions index: %v", err)
+	}
+	return &bossHistory{coll: coll}
+}
+
+func (h *bossHistory) Append(ctx context.Context, rev BossRevision) error {
+	_, err := h.coll.InsertOne(ctx, rev)
+	return err
+}
+
+// List returns the revisions of a boss, newest first, without snapshots
+func (h *bossHistory) List(ctx context.Context, bossID string) ([]BossRevision, error) {
+	opts := options.Find().
+		SetSort(bson.D{{Key: "revision", Value: -1}}).
+		SetProjection(bson.M{"snapshot": 0})
+	cursor, err := h.coll.Find(ctx, bson.M{"boss_id": bossID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	revs := []BossRevision{}
+	if err := cursor.All(ctx, &revs); err != nil {
+		return nil, err
+	}
+	return revs, nil
+}
+
+func (h *bossHistory) Get(ctx context.Context, bossID string, revision int) (*BossRevision, error) {
+	var rev BossRevision
+	err := h.coll.FindOne(ctx, bson.M{"boss_id": bossID, "revision": revision}).Decode(&rev)
+	if err == mongo.ErrNoDocuments {
+		return nil, errRevisionNotFound
+	} else if err != nil {
+		return nil, err
+	}
+	return &rev, nil
+}
+
+// Latest returns the newest revision of a boss
+func (h *bossHistory) Latest(ctx context.Context, bossID string) (*BossRevision, error) {
+	var rev BossRevision
+	opts := options.FindOne().SetSort(bson.D{{Key: "revision", Value: -1}})
+	err := h.coll.FindOne(ctx, bson.M{"boss_id": bossID}, opts).Decode(&rev)
+	if err == mongo.ErrNoDocuments {
+		return nil, errRevisionNotFound
+	} else if err != nil {
+		return nil, err
+	}
+	return &rev, nil
+}
+
+// RenameSeason moves the revisions recorded under a season code to its new
+// code, so deleted bosses of a renamed season can still be restored
+func (h *bossHistory) RenameSeason(ctx context.Context, from, to string) error {
+	_, err := h.coll.UpdateMany(ctx, bson.M{"season": from}, bson.M{"$set": bson.M{"season": to}})
+	return err
+}
+
+// Backfill records a baseline revision for every boss in seasons that has no
+// history yet, so its first recorded edit can be rolled back. It returns how
+// many baselines were added.
+func (h *bossHistory) Backfill(ctx context.Context, seasons []Season) (int, error) {
+	ids, err := h.coll.Distinct(ctx, "boss_id", bson.M{})
+	if err != nil {
+		return 0, err
+	}
+	tracked := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		if s, ok := id.(string); ok {
+			tracked[s] = true
+		}
+	}
+	revs := baselineRevisions(seasons, tracked, time.Now())
+	for _, rev := range revs {
+		if err := h.Append(ctx, rev); err != nil {
+			return 0, err
+		}
+	}
+	return len(revs), nil
+}
+
+// baselineRevisions snapshots the bosses of seasons whose IDs are not tracked,
+// numbered with their current revision
+func baselineRevisions(seasons []Season, tracked map[string]bool, now time.Time) []BossRevision {
+	var revs []BossRevision
+	for _, season := range seasons {
+		for _, boss := range season.RaidBosses {
+			if tracked[boss.ID] {
+				continue
+			}
+			snapshot := boss.clone()
+			revs = append(revs, BossRevision{
+				BossID:    boss.ID,
+				Season:    seasonCode(season),
+				Revision:  boss.Revision,
+				Action:    actionBaseline,
+				CreatedAt: now,
+				Snapshot:  &snapshot,
+			})
+		}
+	}
+	return revs
+}
+
+// recordBossRevision appends a history entry for a change that was already
+// persisted. Failures are logged rather than undoing the edit.
+func (a *App) recordBossRevision(r *http.Request, season, action, variationID string, boss RaidBoss) {
+	if a.history == nil {
+		return
+	}
+	rev := BossRevision{
+		BossID:      boss.ID,
+		Season:      season,
+		Revision:    boss.Revision,
+		Action:      action,
+		VariationID: variationID,
+		Author:      getUsernameFromRequest(r),
+		CreatedAt:   time.Now(),
+		Snapshot:    &boss,
+	}
+	// a delete does not bump the boss, so it takes the next number itself
+	if action == actionDeleteBoss {
+		rev.Revision++
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := a.history.Append(ctx, rev); err != nil {
+		log.Printf("warning: failed to record %s revision %d of boss %s: %v", action, rev.Revision, boss.ID, err)
+	}
+}
+
+// bossSnapshot returns a copy of a boss as stored in seasons
+func bossSnapshot(seasons []Season, code, bossID string) RaidBoss {
+	idx := findSeasonIndex(seasons, code)
+	if idx < 0 {
+		return RaidBoss{}
+	}
+	boss := findBossByID(&seasons[idx], bossID)
+	if boss == nil {
+		return RaidBoss{}
+	}
+	return boss.clone()
+}
+
+// BossChange is one entry of a structured diff between two boss revisions.
+// Path addresses the field, with variations keyed by ID, e.g.
+// "variations[<id>].players.P1[2].move".
+type BossChange struct {
+	Path string      `json:"path"`
+	Op   string      `json:"op"` // "added", "removed" or "changed"
+	Old  interface{} `json:"old,omitempty"`
+	New  interface{} `json:"new,omitempty"`
+}
+
+// diffBosses lists the differences between two boss snapshots, ignoring revision counters
+func diffBosses(from, to RaidBoss) ([]BossChange, error) {
+	var a, b interface{}
+	if err := roundTripJSON(from, &a); err != nil {
+		return nil, err
+	}
+	if err := roundTripJSON(to, &b); err != nil {
+		return nil, err
+	}
+	changes := []BossChange{}
+	diffValues("", a, b, &changes)
+	return changes, nil
+}
+
+func roundTripJSON(v interface{}, out *interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, out)
+}
+
+func diffValues(path string, a, b interface{}, changes *[]BossChange) {
+	switch av := a.(type) {
+	case map[string]interface{}:
+		if bv, ok := b.(map[string]interface{}); ok {
+			diffObjects(path, av, bv, changes)
+			return
+		}
+	case []interface{}:
+		if bv, ok := b.([]interface{}); ok {
+			diffArrays(path, av, bv, changes)
+			return
+		}
+	}
+	if !reflect.DeepEqual(a, b) {
+		*changes = append(*changes, BossChange{Path: path, Op: "changed", Old: a, New: b})
+	}
+}
+
+func diffObjects(path string, a, b map[string]interface{}, changes *[]BossChange) {
+	keys := map[string]bool{}
+	for k := range a {
+		keys[k] = true
+	}
+	for k := range b {
+		keys[k] = true
+	}
+	sorted := make([]string, 0, len(keys))
+	for k := range keys {
+		if k != "revision" {
+			sorted = append(sorted, k)
+		}
+	}
+	sort.Strings(sorted)
+
+	for _, k := range sorted {
+		p := k
+		if path != "" {
+			p = path + "." + k
+		}
+		av, inA := a[k]
+		bv, inB := b[k]
+		switch {
+		case !inA:
+			*changes = append(*changes, BossChange{Path: p, Op: "added", New: bv})
+		case !inB:
+			*changes = append(*changes, BossChange{Path: p, Op: "removed", Old: av})
+		default:
+			diffValues(p, av, bv, changes)
+		}
+	}
+}
+
+// diffArrays compares lists of objects with an "id" by ID and everything else by position
+func diffArrays(path string, a, b []interface{}, changes *[]BossChange) {
+	if aIDs, ok := elementIDs(a); ok {
+		if bIDs, ok := elementIDs(b); ok {
+			for _, el := range a {
+				id := elementID(el)
+				p := fmt.Sprintf("%s[%s]", path, id)
+				if j, found := bIDs[id]; found {
+					diffValues(p, el, b[j], changes)
+				} else {
+					*changes = append(*changes, BossChange{Path: p, Op: "removed", Old: el})
+				}
+			}
+			for _, el := range b {
+				id := elementID(el)
+				if _, found := aIDs[id]; !found {
+					*changes = append(*changes, BossChange{Path: fmt.Sprintf("%s[%s]", path, id), Op: "added", New: el})
+				}
+			}
+			return
+		}
+	}
+
+	for i := 0; i < len(a) || i < len(b); i++ {
+		p := fmt.Sprintf("%s[%d]", path, i)
+		switch {
+		case i >= len(a):
+			*changes = append(*changes, BossChange{Path: p, Op: "added", New: b[i]})
+		case i >= len(b):
+			*changes = append(*changes, BossChange{Path: p, Op: "removed", Old: a[i]})
+		default:
+			diffValues(p, a[i], b[i], changes)
+		}
+	}
+}
+
+// elementIDs maps element IDs to positions when every element is an object with a string id
+func elementIDs(list []interface{}) (map[string]int, bool) {
+	if len(list) == 0 {
+		return nil, false
+	}
+	byID := map[string]int{}
+	for i, el := range list {
+		id := elementID(el)
+		if id == "" {
+			return nil, false
+		}
+		byID[id] = i
+	}
+	return byID, true
+}
+
+// elementID returns the "id" of a decoded JSON object, or "" if it has none
+func elementID(el interface{}) string {
+	obj, _ := el.(map[string]interface{})
+	id, _ := obj["id"].(string)
+	return id
+}
+
+// adminBossHistoryHandler lists a boss's revisions, or returns one with its snapshot when ?revision= is set
+func (a *App) adminBossHistoryHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !isStaffRole(getRoleFromRequest(r)) {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	bossID := r.URL.Query().Get("boss_id")
+	if !validID(bossID) {
+		http.Error(w, "valid boss_id required", http.StatusBadRequest)
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	w.Header().Set("Content-Type", "application/json")
+
+	if param := r.URL.Query().Get("revision"); param != "" {
+		n, err := strconv.Atoi(param)
+		if err != nil {
+			http.Error(w, "invalid revision", http.StatusBadRequest)
+			return
+		}
+		rev, err := a.history.Get(ctx, bossID, n)
+		if errors.Is(err, errRevisionNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		} else if err != nil {
+			log.Printf("Error loading boss revision: %v", err)
+			http.Error(w, "failed to load revision", http.StatusInternalServerError)
+			return
+		}
+		json.NewEncoder(w).Encode(rev)
+		return
+	}
+
+	revs, err := a.history.List(ctx, bossID)
+	if err != nil {
+		log.Printf("Error listing boss revisions: %v", err)
+		http.Error(w, "failed to load revisions", http.StatusInternalServerError)
+		return
+	}
+	json.NewEncoder(w).Encode(revs)
+}
+
+// adminBossHistoryDiffHandler returns the structured diff between two revisions of a boss
+func (a *App) adminBossHistoryDiffHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !isStaffRole(getRoleFromRequest(r)) {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	q := r.URL.Query()
+	bossID := q.Get("boss_id")
+	from, errFrom := strconv.Atoi(q.Get("from"))
+	to, errTo := strconv.Atoi(q.Get("to"))
+	if !validID(bossID) || errFrom != nil || errTo != nil {
+		http.Error(w, "boss_id, from and to required", http.StatusBadRequest)
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var snaps [2]RaidBoss
+	for i, n := range []int{from, to} {
+		rev, err := a.history.Get(ctx, bossID, n)
+		if errors.Is(err, errRevisionNotFound) {
+			http.Error(w, fmt.Sprintf("revision %d not found", n), http.StatusNotFound)
+			return
+		} else if err != nil {
+			log.Printf("Error loading boss revision: %v", err)
+			http.Error(w, "failed to load revision", http.StatusInternalServerError)
+			return
+		}
+		snaps[i] = *rev.Snapshot
+	}
+	changes, err := diffBosses(snaps[0], snaps[1])
+	if err != nil {
+		http.Error(w, "failed to diff revisions", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"boss_id": bossID, "from": from, "to": to, "changes": changes})
+}
+
+// restoreBossIn puts a snapshot back in place of the stored boss, as its next
+// revision, in whichever season now holds the boss. A deleted boss is recreated
+// in season code and continues its numbering after latest, the newest revision
+// in its history. It returns the season restored into, whether the boss was
+// recreated and otherwise the revision it replaced.
+func restoreBossIn(seasons []Season, code string, restored *RaidBoss, latest int) (string, bool, int, error) {
+	for i := range seasons {
+		if current := findBossByID(&seasons[i], restored.ID); current != nil {
+			code = seasonCode(seasons[i])
+			expected := current.Revision
+			return code, false, expected, updateBossIn(seasons, code, restored, expected)
+		}
+	}
+	if findSeasonIndex(seasons, code) < 0 {
+		return code, false, 0, errSeasonNotFound
+	}
+	restored.Revision = latest + 1
+	for i := range restored.Variations {
+		restored.Variations[i].Revision = 1
+	}
+	return code, true, 0, createBossIn(seasons, code, *restored)
+}
+
+// adminBossRestoreHandler rolls a boss back to an earlier revision. The
+// restore is recorded as a new revision, and a deleted boss is recreated.
+func (a *App) adminBossRestoreHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	role := getRoleFromRequest(r)
+	if !isStaffRole(role) {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if role != "admin" && role != "mod" {
+		http.Error(w, "forbidden", http.StatusForbidden)
+		return
+	}
+	var req struct {
+		BossID   string `json:"boss_id"`
+		Revision int    `json:"revision"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || !validID(req.BossID) {
+		http.Error(w, "boss_id and revision required", http.StatusBadRequest)
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	rev, err := a.history.Get(ctx, req.BossID, req.Revision)
+	if errors.Is(err, errRevisionNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	} else if err != nil {
+		log.Printf("Error loading boss revision: %v", err)
+		http.Error(w, "failed to load revision", http.StatusInternalServerError)
+		return
+	}
+	if rev.Action == actionDeleteBoss {
+		http.Error(w, "cannot restore a delete revision, pick the revision before it", http.StatusBadRequest)
+		return
+	}
+	// a deleted boss continues its numbering after the delete entry
+	latest, err := a.history.Latest(ctx, req.BossID)
+	if err != nil {
+		log.Printf("Error loading boss revision: %v", err)
+		http.Error(w, "failed to load revision", http.StatusInternalServerError)
+		return
+	}
+
+	restored := rev.Snapshot.clone()
+	now := time.Now()
+	for i := range restored.Variations {
+		stampVariation(&restored.Variations[i], getUsernameFromRequest(r), now, false)
+	}
+	season := rev.Season
+	err = a.store.Update(func(tx *SeasonTx) error {
+		code, recreated, expected, err := restoreBossIn(tx.Seasons, rev.Season, &restored, latest.Revision)
+		if err != nil {
+			return err
+		}
+		season = code
+		if recreated {
+			return a.bosses.CreateBoss(ctx, season, restored)
+		}
+		return a.bosses.UpdateBoss(ctx, season, restored, expected)
+	})
+	var conflict *revisionConflictError
+	if errors.As(err, &conflict) {
+		writeRevisionConflict(w, conflict)
+		return
+	} else if errors.Is(err, errSeasonNotFound) {
+		http.Error(w, "season not found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		log.Printf("Error restoring boss: %v", err)
+		http.Error(w, "failed to restore boss", http.StatusInternalServerError)
+		return
+	}
+	a.recordBossRevision(r, season, actionRestoreBoss, "", restored)
+
+	w.Header().Set("Content-Type", "application/json")
+	setETag(w, restored.Revision)
+	json.NewEncoder(w).Encode(map[string]interface{}{"status": "restored", "revision": restored.Revision, "restored_from": rev.Revision})
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func historyTestBoss() RaidBoss {
+	return RaidBoss{
+		ID: "glaceon", Revision: 2, Name: "Glaceon",
+		Variations: []Variation{
+			{ID: "v1", Revision: 1, Players: map[string][]Player{"P1": {{Pokemon: "Golduck", Move: "Surf"}}}, HealthRemaining: []float64{80}},
+			{ID: "v2", Revision: 1, HealthRemaining: []float64{50}},
+		},
+	}
+}
+
+func TestDiffBosses(t *testing.T) {
+	from := historyTestBoss()
+	to := from.clone()
+	to.Revision = 3
+	to.Name = "Shiny Glaceon"
+	to.Variations[0].Revision = 2
+	to.Variations[0].Players["P1"][0].Move = "Scald"
+	to.Variations = append(to.Variations[:1], Variation{ID: "v3", HealthRemaining: []float64{10}})
+
+	changes, err := diffBosses(from, to)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []BossChange{
+		{Path: "name", Op: "changed", Old: "Glaceon", New: "Shiny Glaceon"},
+		{Path: "variations[v1].players.P1[0].move", Op: "changed", Old: "Surf", New: "Scald"},
+		{Path: "variations[v2]", Op: "removed"},
+		{Path: "variations[v3]", Op: "added"},
+	}
+	if len(changes) != len(want) {
+		t.Fatalf("got %d changes, want %d: %+v", len(changes), len(want), changes)
+	}
+	for i, w := range want {
+		c := changes[i]
+		if c.Path != w.Path || c.Op != w.Op {
+			t.Errorf("change %d = %s %s, want %s %s", i, c.Op, c.Path, w.Op, w.Path)
+		}
+		if w.Op == "changed" && (c.Old != w.Old || c.New != w.New) {
+			t.Errorf("change %d: %v -> %v, want %v -> %v", i, c.Old, c.New, w.Old, w.New)
+		}
+	}
+
+	if changes, _ := diffBosses(from, from); len(changes) != 0 {
+		t.Errorf("identical bosses differ: %+v", changes)
+	}
+}
+
+func TestDiffArrays(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b []interface{}
+		want []string // "op path"
+	}{
+		{
+			name: "by position",
+			a:    []interface{}{1.0, 2.0},
+			b:    []interface{}{1.0, 3.0, 4.0},
+			want: []string{"changed hp[1]", "added hp[2]"},
+		},
+		{
+			name: "by id",
+			a:    []interface{}{map[string]interface{}{"id": "a", "x": 1.0}, map[string]interface{}{"id": "b"}},
+			b:    []interface{}{map[string]interface{}{"id": "b"}, map[string]interface{}{"id": "a", "x": 2.0}},
+			want: []string{"changed hp[a].x"},
+		},
+		{
+			name: "missing id falls back to position",
+			a:    []interface{}{map[string]interface{}{"id": "a"}},
+			b:    []interface{}{map[string]interface{}{"x": 1.0}},
+			want: []string{"removed hp[0].id", "added hp[0].x"},
+		},
+	}
+	for _, tt := range tests {
+		var changes []BossChange
+		diffArrays("hp", tt.a, tt.b, &changes)
+		if len(changes) != len(tt.want) {
+			t.Errorf("%s: got %+v, want %v", tt.name, changes, tt.want)
+			continue
+		}
+		for i, c := range changes {
+			if got := c.Op + " " + c.Path; got != tt.want[i] {
+				t.Errorf("%s: change %d = %q, want %q", tt.name, i, got, tt.want[i])
+			}
+		}
+	}
+}
+
+func TestBaselineRevisions(t *testing.T) {
+	seasons := testSeasons()
+	seasons[1].RaidBosses = []RaidBoss{{ID: "pumpkaboo", Revision: 4, Name: "Pumpkaboo"}}
+	now := time.Date(2025, 1, 31, 12, 0, 0, 0, time.UTC)
+
+	revs := baselineRevisions(seasons, map[string]bool{"pumpkaboo": true}, now)
+	if len(revs) != 1 {
+		t.Fatalf("got %d baselines, want 1 for the untracked boss", len(revs))
+	}
+	rev := revs[0]
+	if rev.BossID != "glaceon" || rev.Season != "christmas_2024" || rev.Revision != 0 ||
+		rev.Action != actionBaseline || !rev.CreatedAt.Equal(now) {
+		t.Errorf("baseline = %+v", rev)
+	}
+	seasons[0].RaidBosses[0].Variations[0].HealthRemaining[0] = 1
+	if rev.Snapshot.Variations[0].HealthRemaining[0] != 80 {
+		t.Error("baseline snapshot shares memory with the seasons")
+	}
+}
+
+func TestRestoreBossIn(t *testing.T) {
+	seasons := []Season{{SeasonName: "Christmas", Year: 2024, RaidBosses: []RaidBoss{historyTestBoss()}}}
+	seasons[0].RaidBosses[0].Revision = 4
+	seasons[0].RaidBosses[0].Variations[0].Revision = 2
+
+	// an earlier snapshot goes back in as the next revision
+	restored := historyTestBoss()
+	restored.Variations[0].Players["P1"][0].Move = "Scald"
+	code, recreated, expected, err := restoreBossIn(seasons, "christmas_2024", &restored, 6)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if code != "christmas_2024" || recreated || expected != 4 || restored.Revision != 5 {
+		t.Errorf("restore over existing boss: season %s, recreated %v, expected %d, revision %d", code, recreated, expected, restored.Revision)
+	}
+	stored := seasons[0].RaidBosses[0]
+	if stored.Revision != 5 || stored.Variations[0].Revision != 3 || stored.Variations[1].Revision != 1 {
+		t.Errorf("stored revisions = %d, %d, %d", stored.Revision, stored.Variations[0].Revision, stored.Variations[1].Revision)
+	}
+
+	// revisions saved before a season rename find the boss by ID
+	if err := updateSeasonIn(seasons, "christmas_2024", "Winter", 2025); err != nil {
+		t.Fatal(err)
+	}
+	restored = historyTestBoss()
+	code, recreated, _, err = restoreBossIn(seasons, "christmas_2024", &restored, 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if code != "winter_2025" || recreated || seasons[0].RaidBosses[0].Revision != 6 {
+		t.Errorf("restore after rename: season %s, recreated %v, revision %d", code, recreated, seasons[0].RaidBosses[0].Revision)
+	}
+
+	// a deleted boss continues after the newest revision in its history
+	seasons[0].RaidBosses = nil
+	restored = historyTestBoss()
+	code, recreated, _, err = restoreBossIn(seasons, "winter_2025", &restored, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if code != "winter_2025" || !recreated || restored.Revision != 9 || len(seasons[0].RaidBosses) != 1 {
+		t.Errorf("restore of deleted boss: season %s, recreated %v, revision %d", code, recreated, restored.Revision)
+	}
+	for _, v := range seasons[0].RaidBosses[0].Variations {
+		if v.Revision != 1 {
+			t.Errorf("recreated variation %s at revision %d, want 1", v.ID, v.Revision)
+		}
+	}
+
+	gone := RaidBoss{ID: "pumpkaboo", Name: "Pumpkaboo"}
+	if _, _, _, err := restoreBossIn(seasons, "halloween_2024", &gone, 2); !errors.Is(err, errSeasonNotFound) {
+		t.Errorf("deleted boss of an unknown season: err = %v", err)
+	}
+}
@@ -0,0 +1,299 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"reflect"
+	"slices"
+	"sync"
+)
+
+// bossStore selects the BossRepository backend: "json" (bosses.json) or "mongo"
+var bossStore = getEnvOrDefault("BOSS_STORE", "json")
+
+// BossRepository persists raid seasons, bosses and variations. Seasons are
+// addressed by code, bosses and variations by their stable IDs.
+type BossRepository interface {
+	// Seasons loads all seasons, assigning and persisting IDs for bosses and variations that lack one
+	Seasons(ctx context.Context) ([]Season, error)
+	CreateSeason(ctx context.Context, s Season) error
+	UpdateSeason(ctx context.Context, code, name string, year int) error
+	DeleteSeason(ctx context.Context, code string) error
+
+	// UpdateBoss and UpdateVariation fail with *revisionConflictError unless the
+	// stored copy is still at the expected revision
+	CreateBoss(ctx context.Context, season string, boss RaidBoss) error
+	UpdateBoss(ctx context.Context, season string, boss RaidBoss, expected int) error
+	DeleteBoss(ctx context.Context, season, bossID string) error
+
+	CreateVariation(ctx context.Context, season, bossID string, v Variation) error
+	UpdateVariation(ctx context.Context, season, bossID string, v Variation, expected int) error
+}
+
+// newBossRepository returns the repository configured by BOSS_STORE
+func (a *App) newBossRepository() (BossRepository, error) {
+	switch bossStore {
+	case "json":
+		return &jsonBossRepository{path: dataPath, backups: dataBackups}, nil
+	case "mongo":
+		if a.mongoDB == nil {
+			return nil, fmt.Errorf("BOSS_STORE=mongo requires a MongoDB connection")
+		}
+		return newMongoBossRepository(a.mongoDB)
+	default:
+		return nil, fmt.Errorf("unknown BOSS_STORE %q (want json or mongo)", bossStore)
+	}
+}
+
+// jsonBossRepository stores all seasons in a single JSON file
+type jsonBossRepository struct {
+	mu      sync.Mutex
+	path    string
+	backups int
+}
+
+func (r *jsonBossRepository) Seasons(ctx context.Context) ([]Season, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	// a corrupt file falls back to the newest valid backup
+	seasons, err := loadSeasonsWithFallback(r.path)
+	if err != nil {
+		return nil, err
+	}
+	changed := assignIDs(seasons)
+	if changed {
+		log.Printf("Assigning IDs to bosses and variations in %s", r.path)
+	}
+	if migratePhaseEffects(seasons) {
+		log.Printf("Converting free-text phase effects in %s", r.path)
+		changed = true
+	}
+	if changed {
+		if err := r.write(seasons); err != nil {
+			return nil, err
+		}
+	}
+	return seasons, nil
+}
+
+// modify applies fn to the file contents and atomically writes the result back.
+// A corrupt primary file is rebuilt from the newest valid backup.
+func (r *jsonBossRepository) modify(fn func(seasons []Season) ([]Season, error)) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	seasons, err := loadSeasonsWithFallback(r.path)
+	if err != nil {
+		return err
+	}
+	if seasons, err = fn(seasons); err != nil {
+		return err
+	}
+	return r.write(seasons)
+}
+
+// write backs up the current file and atomically replaces it with seasons
+func (r *jsonBossRepository) write(seasons []Season) error {
+	data, err := json.MarshalIndent(seasons, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := backupFile(r.path, r.backups); err != nil {
+		log.Printf("warning: failed to back up %s: %v", r.path, err)
+	}
+	return writeFileAtomic(r.path, append(data, '\n'), 0644)
+}
+
+func (r *jsonBossRepository) CreateSeason(ctx context.Context, s Season) error {
+	return r.modify(func(seasons []Season) ([]Season, error) {
+		return createSeasonIn(seasons, s)
+	})
+}
+
+func (r *jsonBossRepository) UpdateSeason(ctx context.Context, code, name string, year int) error {
+	return r.modify(func(seasons []Season) ([]Season, error) {
+		return seasons, updateSeasonIn(seasons, code, name, year)
+	})
+}
+
+func (r *jsonBossRepository) DeleteSeason(ctx context.Context, code string) error {
+	return r.modify(func(seasons []Season) ([]Season, error) {
+		return deleteSeasonIn(seasons, code)
+	})
+}
+
+func (r *jsonBossRepository) CreateBoss(ctx context.Context, season string, boss RaidBoss) error {
+	return r.modify(func(seasons []Season) ([]Season, error) {
+		return seasons, createBossIn(seasons, season, boss)
+	})
+}
+
+func (r *jsonBossRepository) UpdateBoss(ctx context.Context, season string, boss RaidBoss, expected int) error {
+	return r.modify(func(seasons []Season) ([]Season, error) {
+		return seasons, updateBossIn(seasons, season, &boss, expected)
+	})
+}
+
+func (r *jsonBossRepository) DeleteBoss(ctx context.Context, season, bossID string) error {
+	return r.modify(func(seasons []Season) ([]Season, error) {
+		return seasons, deleteBossIn(seasons, season, bossID)
+	})
+}
+
+func (r *jsonBossRepository) CreateVariation(ctx context.Context, season, bossID string, v Variation) error {
+	return r.modify(func(seasons []Season) ([]Season, error) {
+		return seasons, createVariationIn(seasons, season, bossID, v)
+	})
+}
+
+func (r *jsonBossRepository) UpdateVariation(ctx context.Context, season, bossID string, v Variation, expected int) error {
+	return r.modify(func(seasons []Season) ([]Season, error) {
+		return seasons, updateVariationIn(seasons, season, bossID, &v, expected)
+	})
+}
+
+// The helpers below apply a single edit to a list of seasons. They are shared
+// by the in-memory SeasonStore transactions and the JSON repository so both
+// see identical validation.
+
+func createSeasonIn(seasons []Season, s Season) ([]Season, error) {
+	if findSeasonIndex(seasons, seasonCode(s)) >= 0 {
+		return seasons, errSeasonExists
+	}
+	if s.RaidBosses == nil {
+		s.RaidBosses = []RaidBoss{}
+	}
+	return append(seasons, s), nil
+}
+
+func updateSeasonIn(seasons []Season, code, name string, year int) error {
+	idx := findSeasonIndex(seasons, code)
+	if idx < 0 {
+		return errSeasonNotFound
+	}
+	newCode := seasonCode(Season{SeasonName: name, Year: year})
+	for i, s := range seasons {
+		if i != idx && seasonCode(s) == newCode {
+			return errSeasonExists
+		}
+	}
+	// preserve raid bosses while updating metadata
+	seasons[idx].SeasonName = name
+	seasons[idx].Year = year
+	return nil
+}
+
+func deleteSeasonIn(seasons []Season, code string) ([]Season, error) {
+	idx := findSeasonIndex(seasons, code)
+	if idx < 0 {
+		return seasons, errSeasonNotFound
+	}
+	return append(seasons[:idx], seasons[idx+1:]...), nil
+}
+
+func createBossIn(seasons []Season, code string, boss RaidBoss) error {
+	idx := findSeasonIndex(seasons, code)
+	if idx < 0 {
+		return errSeasonNotFound
+	}
+	seasons[idx].RaidBosses = append(seasons[idx].RaidBosses, boss)
+	return nil
+}
+
+// updateBossIn replaces the boss if it is still at the expected revision. It sets
+// boss.Revision to the next revision and bumps the revision of every variation
+// whose content changed, so concurrent variation editors notice the overwrite.
+// Existing variations keep their creator, and unchanged ones their last editor.
+func updateBossIn(seasons []Season, code string, boss *RaidBoss, expected int) error {
+	idx := findSeasonIndex(seasons, code)
+	if idx < 0 {
+		return errSeasonNotFound
+	}
+	target := findBossByID(&seasons[idx], boss.ID)
+	if target == nil {
+		return errBossNotFound
+	}
+	if target.Revision != expected {
+		return &revisionConflictError{Current: target.clone()}
+	}
+	boss.Revision = expected + 1
+	for i := range boss.Variations {
+		v := &boss.Variations[i]
+		old := findVariationIndex(target, v.ID)
+		switch {
+		case old < 0:
+			v.Revision = 1
+		case variationChanged(target.Variations[old], *v):
+			v.Revision = target.Variations[old].Revision + 1
+			keepAuthorship(v, target.Variations[old], true)
+		default:
+			v.Revision = target.Variations[old].Revision
+			keepAuthorship(v, target.Variations[old], false)
+		}
+	}
+	*target = boss.clone()
+	return nil
+}
+
+// variationChanged reports whether the stored content of two variations differs
+func variationChanged(a, b Variation) bool {
+	return !reflect.DeepEqual(a.Players, b.Players) ||
+		!slices.Equal(a.HealthRemaining, b.HealthRemaining) ||
+		!slices.Equal(a.Notes, b.Notes) ||
+		a.Credits != b.Credits
+}
+
+func deleteBossIn(seasons []Season, code, bossID string) error {
+	idx := findSeasonIndex(seasons, code)
+	if idx < 0 {
+		return errSeasonNotFound
+	}
+	bosses := seasons[idx].RaidBosses
+	for i := range bosses {
+		if bosses[i].ID == bossID {
+			seasons[idx].RaidBosses = append(bosses[:i], bosses[i+1:]...)
+			return nil
+		}
+	}
+	return errBossNotFound
+}
+
+func createVariationIn(seasons []Season, code, bossID string, v Variation) error {
+	idx := findSeasonIndex(seasons, code)
+	if idx < 0 {
+		return errSeasonNotFound
+	}
+	boss := findBossByID(&seasons[idx], bossID)
+	if boss == nil {
+		return errBossNotFound
+	}
+	boss.Variations = append(boss.Variations, v)
+	boss.Revision++
+	return nil
+}
+
+// updateVariationIn replaces the variation if it is still at the expected revision
+// and copies its creator into v. v.Revision must already be set to the next revision.
+func updateVariationIn(seasons []Season, code, bossID string, v *Variation, expected int) error {
+	idx := findSeasonIndex(seasons, code)
+	if idx < 0 {
+		return errSeasonNotFound
+	}
+	boss := findBossByID(&seasons[idx], bossID)
+	if boss == nil {
+		return errBossNotFound
+	}
+	vi := findVariationIndex(boss, v.ID)
+	if vi < 0 {
+		return errVariationNotFound
+	}
+	if boss.Variations[vi].Revision != expected {
+		return &revisionConflictError{Current: boss.Variations[vi].clone()}
+	}
+	keepAuthorship(v, boss.Variations[vi], true)
+	boss.Variations[vi] = *v
+	boss.Revision++
+	return nil
+}
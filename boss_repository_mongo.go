@@ -0,0 +1,316 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// SeasonDocument is the MongoDB form of a Season, one document per season
+type SeasonDocument struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty"`
+	Code       string             `bson:"code"`
+	Position   int                `bson:"position"` // keeps the bosses.json season order
+	SeasonName string             `bson:"season"`
+	Year       int                `bson:"year"`
+	RaidBosses []RaidBoss         `bson:"raid_bosses"`
+	UpdatedAt  time.Time          `bson:"updated_at"`
+}
+
+func newSeasonDocument(s Season, position int) SeasonDocument {
+	bosses := make([]RaidBoss, len(s.RaidBosses))
+	for i, b := range s.RaidBosses {
+		bosses[i] = normalizeBossForMongo(b)
+	}
+	return SeasonDocument{
+		Code:       seasonCode(s),
+		Position:   position,
+		SeasonName: s.SeasonName,
+		Year:       s.Year,
+		RaidBosses: bosses,
+		UpdatedAt:  time.Now(),
+	}
+}
+
+// normalizeBossForMongo replaces a nil variations slice, which BSON stores as null and $push rejects
+func normalizeBossForMongo(b RaidBoss) RaidBoss {
+	if b.Variations == nil {
+		b.Variations = []Variation{}
+	}
+	return b
+}
+
+// mongoBossRepository stores raid seasons in the raid_seasons collection
+type mongoBossRepository struct {
+	coll *mongo.Collection
+}
+
+func newMongoBossRepository(db *mongo.Database) (*mongoBossRepository, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	coll := db.Collection("raid_seasons")
+	_, err := coll.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "code", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		log.Printf("warning: failed to create raid_seasons code index: %v", err)
+	}
+	return &mongoBossRepository{coll: coll}, nil
+}
+
+func (r *mongoBossRepository) Seasons(ctx context.Context) ([]Season, error) {
+	cursor, err := r.coll.Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: "position", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var docs []SeasonDocument
+	if err := cursor.All(ctx, &docs); err != nil {
+		return nil, err
+	}
+	seasons := make([]Season, 0, len(docs))
+	for _, d := range docs {
+		bosses := d.RaidBosses
+		if bosses == nil {
+			bosses = []RaidBoss{}
+		}
+		season := Season{SeasonName: d.SeasonName, Year: d.Year, RaidBosses: bosses}
+		changed := assignIDs([]Season{season})
+		if changed {
+			log.Printf("Assigning IDs to bosses and variations in season %s", d.Code)
+		}
+		if migratePhaseEffects([]Season{season}) {
+			log.Printf("Converting free-text phase effects in season %s", d.Code)
+			changed = true
+		}
+		if changed {
+			_, err := r.coll.UpdateOne(ctx, bson.M{"_id": d.ID}, bson.M{"$set": bson.M{"raid_bosses": bosses}})
+			if err != nil {
+				return nil, err
+			}
+		}
+		seasons = append(seasons, season)
+	}
+	return seasons, nil
+}
+
+// nextPosition returns the position after the last stored season
+func (r *mongoBossRepository) nextPosition(ctx context.Context) (int, error) {
+	var last SeasonDocument
+	err := r.coll.FindOne(ctx, bson.M{}, options.FindOne().SetSort(bson.D{{Key: "position", Value: -1}})).Decode(&last)
+	if err == mongo.ErrNoDocuments {
+		return 0, nil
+	} else if err != nil {
+		return 0, err
+	}
+	return last.Position + 1, nil
+}
+
+func (r *mongoBossRepository) CreateSeason(ctx context.Context, s Season) error {
+	pos, err := r.nextPosition(ctx)
+	if err != nil {
+		return err
+	}
+	_, err = r.coll.InsertOne(ctx, newSeasonDocument(s, pos))
+	if mongo.IsDuplicateKeyError(err) {
+		return errSeasonExists
+	}
+	return err
+}
+
+func (r *mongoBossRepository) UpdateSeason(ctx context.Context, code, name string, year int) error {
+	newCode := seasonCode(Season{SeasonName: name, Year: year})
+	res, err := r.coll.UpdateOne(ctx, bson.M{"code": code}, bson.M{"$set": bson.M{
+		"code":       newCode,
+		"season":     name,
+		"year":       year,
+		"updated_at": time.Now(),
+	}})
+	if mongo.IsDuplicateKeyError(err) {
+		return errSeasonExists
+	} else if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return errSeasonNotFound
+	}
+	return nil
+}
+
+func (r *mongoBossRepository) DeleteSeason(ctx context.Context, code string) error {
+	res, err := r.coll.DeleteOne(ctx, bson.M{"code": code})
+	if err != nil {
+		return err
+	}
+	if res.DeletedCount == 0 {
+		return errSeasonNotFound
+	}
+	return nil
+}
+
+// missing reports which part of an unmatched update did not exist
+func (r *mongoBossRepository) missing(ctx context.Context, code string, notFound error) error {
+	n, err := r.coll.CountDocuments(ctx, bson.M{"code": code})
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return errSeasonNotFound
+	}
+	return notFound
+}
+
+func (r *mongoBossRepository) CreateBoss(ctx context.Context, season string, boss RaidBoss) error {
+	res, err := r.coll.UpdateOne(ctx, bson.M{"code": season}, bson.M{
+		"$push": bson.M{"raid_bosses": normalizeBossForMongo(boss)},
+		"$set":  bson.M{"updated_at": time.Now()},
+	})
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return errSeasonNotFound
+	}
+	return nil
+}
+
+// findBoss loads the stored copy of a boss, used to report revision conflicts
+func (r *mongoBossRepository) findBoss(ctx context.Context, season, bossID string) (*RaidBoss, error) {
+	var doc SeasonDocument
+	err := r.coll.FindOne(ctx, bson.M{"code": season}).Decode(&doc)
+	if err == mongo.ErrNoDocuments {
+		return nil, errSeasonNotFound
+	} else if err != nil {
+		return nil, err
+	}
+	for i := range doc.RaidBosses {
+		if doc.RaidBosses[i].ID == bossID {
+			return &doc.RaidBosses[i], nil
+		}
+	}
+	return nil, errBossNotFound
+}
+
+func (r *mongoBossRepository) UpdateBoss(ctx context.Context, season string, boss RaidBoss, expected int) error {
+	res, err := r.coll.UpdateOne(ctx,
+		bson.M{"code": season, "raid_bosses": bson.M{"$elemMatch": bson.M{"id": boss.ID, "revision": expected}}},
+		bson.M{"$set": bson.M{"raid_bosses.$": normalizeBossForMongo(boss), "updated_at": time.Now()}},
+	)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		current, err := r.findBoss(ctx, season, boss.ID)
+		if err != nil {
+			return err
+		}
+		return &revisionConflictError{Current: *current}
+	}
+	return nil
+}
+
+func (r *mongoBossRepository) DeleteBoss(ctx context.Context, season, bossID string) error {
+	res, err := r.coll.UpdateOne(ctx,
+		bson.M{"code": season, "raid_bosses.id": bossID},
+		bson.M{"$pull": bson.M{"raid_bosses": bson.M{"id": bossID}}, "$set": bson.M{"updated_at": time.Now()}},
+	)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return r.missing(ctx, season, errBossNotFound)
+	}
+	return nil
+}
+
+func (r *mongoBossRepository) CreateVariation(ctx context.Context, season, bossID string, v Variation) error {
+	res, err := r.coll.UpdateOne(ctx,
+		bson.M{"code": season, "raid_bosses.id": bossID},
+		bson.M{
+			"$push": bson.M{"raid_bosses.$.variations": v},
+			"$inc":  bson.M{"raid_bosses.$.revision": 1},
+			"$set":  bson.M{"updated_at": time.Now()},
+		},
+	)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return r.missing(ctx, season, errBossNotFound)
+	}
+	return nil
+}
+
+func (r *mongoBossRepository) UpdateVariation(ctx context.Context, season, bossID string, v Variation, expected int) error {
+	filter := bson.M{"code": season, "raid_bosses": bson.M{"$elemMatch": bson.M{
+		"id":         bossID,
+		"variations": bson.M{"$elemMatch": bson.M{"id": v.ID, "revision": expected}},
+	}}}
+	update := bson.M{
+		"$set": bson.M{"raid_bosses.$[b].variations.$[v]": v, "updated_at": time.Now()},
+		"$inc": bson.M{"raid_bosses.$[b].revision": 1},
+	}
+	opts := options.Update().SetArrayFilters(options.ArrayFilters{Filters: []interface{}{
+		bson.M{"b.id": bossID},
+		bson.M{"v.id": v.ID},
+	}})
+	res, err := r.coll.UpdateOne(ctx, filter, update, opts)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		boss, err := r.findBoss(ctx, season, bossID)
+		if err != nil {
+			return err
+		}
+		for _, cur := range boss.Variations {
+			if cur.ID == v.ID {
+				return &revisionConflictError{Current: cur}
+			}
+		}
+		return errVariationNotFound
+	}
+	return nil
+}
+
+// migrateBossesToMongo copies every season from bosses.json into the raid_seasons
+// collection. Existing seasons are skipped unless overwrite is set.
+func migrateBossesToMongo(ctx context.Context, db *mongo.Database, path string, overwrite bool) error {
+	seasons, err := readSeasonsFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	assignIDs(seasons)
+	repo, err := newMongoBossRepository(db)
+	if err != nil {
+		return err
+	}
+
+	for i, s := range seasons {
+		code := seasonCode(s)
+		doc := newSeasonDocument(s, i)
+		if overwrite {
+			_, err = repo.coll.ReplaceOne(ctx, bson.M{"code": code}, doc, options.Replace().SetUpsert(true))
+		} else {
+			_, err = repo.coll.InsertOne(ctx, doc)
+			if mongo.IsDuplicateKeyError(err) {
+				log.Printf("Skipping season %s: already in MongoDB (use -overwrite to replace)", code)
+				continue
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("failed to migrate season %s: %w", code, err)
+		}
+		log.Printf("Migrated season %s (%d bosses)", code, len(s.RaidBosses))
+	}
+	return nil
+}
@@ -0,0 +1,356 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func repoTestSeasons() []Season {
+	return []Season{
+		{SeasonName: "Christmas", Year: 2024, RaidBosses: []RaidBoss{{
+			ID: "b1", Revision: 1, Name: "Glaceon",
+			Variations: []Variation{{ID: "v1", Revision: 1, HealthRemaining: []float64{80}}},
+		}}},
+		{SeasonName: "Halloween", Year: 2024, RaidBosses: []RaidBoss{}},
+	}
+}
+
+// newTestJSONRepository writes seasons to a bosses.json in a temp dir
+func newTestJSONRepository(t *testing.T, seasons []Season) *jsonBossRepository {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "bosses.json")
+	data, err := json.Marshal(seasons)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return &jsonBossRepository{path: path, backups: 2}
+}
+
+func TestJSONBossRepository(t *testing.T) {
+	tests := []struct {
+		name    string
+		op      func(ctx context.Context, r *jsonBossRepository) error
+		wantErr error
+		check   func(t *testing.T, seasons []Season)
+	}{
+		{
+			name: "create season",
+			op: func(ctx context.Context, r *jsonBossRepository) error {
+				return r.CreateSeason(ctx, Season{SeasonName: "Easter", Year: 2025})
+			},
+			check: func(t *testing.T, seasons []Season) {
+				if len(seasons) != 3 || seasonCode(seasons[2]) != "easter_2025" || seasons[2].RaidBosses == nil {
+					t.Errorf("seasons = %+v", seasons)
+				}
+			},
+		},
+		{
+			name: "create existing season",
+			op: func(ctx context.Context, r *jsonBossRepository) error {
+				return r.CreateSeason(ctx, Season{SeasonName: "Christmas", Year: 2024})
+			},
+			wantErr: errSeasonExists,
+		},
+		{
+			name: "rename season",
+			op: func(ctx context.Context, r *jsonBossRepository) error {
+				return r.UpdateSeason(ctx, "christmas_2024", "Winter", 2025)
+			},
+			check: func(t *testing.T, seasons []Season) {
+				if seasonCode(seasons[0]) != "winter_2025" || len(seasons[0].RaidBosses) != 1 {
+					t.Errorf("renamed season = %+v", seasons[0])
+				}
+			},
+		},
+		{
+			name: "rename season onto an existing code",
+			op: func(ctx context.Context, r *jsonBossRepository) error {
+				return r.UpdateSeason(ctx, "christmas_2024", "Halloween", 2024)
+			},
+			wantErr: errSeasonExists,
+		},
+		{
+			name: "rename missing season",
+			op: func(ctx context.Context, r *jsonBossRepository) error {
+				return r.UpdateSeason(ctx, "easter_2025", "Spring", 2025)
+			},
+			wantErr: errSeasonNotFound,
+		},
+		{
+			name: "delete season",
+			op: func(ctx context.Context, r *jsonBossRepository) error {
+				return r.DeleteSeason(ctx, "halloween_2024")
+			},
+			check: func(t *testing.T, seasons []Season) {
+				if len(seasons) != 1 || seasonCode(seasons[0]) != "christmas_2024" {
+					t.Errorf("seasons = %+v", seasons)
+				}
+			},
+		},
+		{
+			name: "delete missing season",
+			op: func(ctx context.Context, r *jsonBossRepository) error {
+				return r.DeleteSeason(ctx, "easter_2025")
+			},
+			wantErr: errSeasonNotFound,
+		},
+		{
+			name: "create boss",
+			op: func(ctx context.Context, r *jsonBossRepository) error {
+				return r.CreateBoss(ctx, "halloween_2024", RaidBoss{ID: "b2", Revision: 1, Name: "Gengar"})
+			},
+			check: func(t *testing.T, seasons []Season) {
+				if len(seasons[1].RaidBosses) != 1 || seasons[1].RaidBosses[0].Name != "Gengar" {
+					t.Errorf("bosses = %+v", seasons[1].RaidBosses)
+				}
+			},
+		},
+		{
+			name: "create boss in missing season",
+			op: func(ctx context.Context, r *jsonBossRepository) error {
+				return r.CreateBoss(ctx, "easter_2025", RaidBoss{ID: "b2", Name: "Gengar"})
+			},
+			wantErr: errSeasonNotFound,
+		},
+		{
+			name: "update boss",
+			op: func(ctx context.Context, r *jsonBossRepository) error {
+				boss := repoTestSeasons()[0].RaidBosses[0]
+				boss.Name = "Shiny Glaceon"
+				return r.UpdateBoss(ctx, "christmas_2024", boss, 1)
+			},
+			check: func(t *testing.T, seasons []Season) {
+				boss := seasons[0].RaidBosses[0]
+				if boss.Name != "Shiny Glaceon" || boss.Revision != 2 || boss.Variations[0].Revision != 1 {
+					t.Errorf("boss = %+v", boss)
+				}
+			},
+		},
+		{
+			name: "update missing boss",
+			op: func(ctx context.Context, r *jsonBossRepository) error {
+				return r.UpdateBoss(ctx, "christmas_2024", RaidBoss{ID: "b9"}, 1)
+			},
+			wantErr: errBossNotFound,
+		},
+		{
+			name: "delete boss",
+			op: func(ctx context.Context, r *jsonBossRepository) error {
+				return r.DeleteBoss(ctx, "christmas_2024", "b1")
+			},
+			check: func(t *testing.T, seasons []Season) {
+				if len(seasons[0].RaidBosses) != 0 {
+					t.Errorf("bosses = %+v", seasons[0].RaidBosses)
+				}
+			},
+		},
+		{
+			name: "delete missing boss",
+			op: func(ctx context.Context, r *jsonBossRepository) error {
+				return r.DeleteBoss(ctx, "christmas_2024", "b9")
+			},
+			wantErr: errBossNotFound,
+		},
+		{
+			name: "create variation",
+			op: func(ctx context.Context, r *jsonBossRepository) error {
+				return r.CreateVariation(ctx, "christmas_2024", "b1", Variation{ID: "v2", Revision: 1, HealthRemaining: []float64{50}})
+			},
+			check: func(t *testing.T, seasons []Season) {
+				boss := seasons[0].RaidBosses[0]
+				if len(boss.Variations) != 2 || boss.Variations[1].ID != "v2" || boss.Revision != 2 {
+					t.Errorf("boss = %+v", boss)
+				}
+			},
+		},
+		{
+			name: "create variation on missing boss",
+			op: func(ctx context.Context, r *jsonBossRepository) error {
+				return r.CreateVariation(ctx, "christmas_2024", "b9", Variation{ID: "v2"})
+			},
+			wantErr: errBossNotFound,
+		},
+		{
+			name: "update variation",
+			op: func(ctx context.Context, r *jsonBossRepository) error {
+				return r.UpdateVariation(ctx, "christmas_2024", "b1", Variation{ID: "v1", Revision: 2, HealthRemaining: []float64{40}}, 1)
+			},
+			check: func(t *testing.T, seasons []Season) {
+				v := seasons[0].RaidBosses[0].Variations[0]
+				if v.Revision != 2 || v.HealthRemaining[0] != 40 || seasons[0].RaidBosses[0].Revision != 2 {
+					t.Errorf("variation = %+v", v)
+				}
+			},
+		},
+		{
+			name: "update missing variation",
+			op: func(ctx context.Context, r *jsonBossRepository) error {
+				return r.UpdateVariation(ctx, "christmas_2024", "b1", Variation{ID: "v9", Revision: 2}, 1)
+			},
+			wantErr: errVariationNotFound,
+		},
+	}
+
+	ctx := context.Background()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newTestJSONRepository(t, repoTestSeasons())
+			before, err := r.Seasons(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := tt.op(ctx, r); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			// reload from disk so the check sees what was persisted
+			after, err := (&jsonBossRepository{path: r.path}).Seasons(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.wantErr != nil {
+				if !reflect.DeepEqual(before, after) {
+					t.Errorf("failed edit changed the file: %+v", after)
+				}
+				return
+			}
+			tt.check(t, after)
+		})
+	}
+}
+
+func TestNewSeasonDocument(t *testing.T) {
+	s := Season{SeasonName: "Christmas", Year: 2024, RaidBosses: []RaidBoss{{ID: "b1", Name: "Glaceon"}}}
+	doc := newSeasonDocument(s, 3)
+	if doc.Code != "christmas_2024" || doc.Position != 3 || doc.SeasonName != "Christmas" || doc.Year != 2024 {
+		t.Errorf("document = %+v", doc)
+	}
+	if doc.RaidBosses[0].Variations == nil {
+		t.Error("nil variations are stored as null")
+	}
+	if s.RaidBosses[0].Variations != nil {
+		t.Error("newSeasonDocument changed the season")
+	}
+}
+
+// TestMigrateBossesToMongo needs a disposable MongoDB server in MONGO_TEST_URI
+func TestMigrateBossesToMongo(t *testing.T) {
+	uri := os.Getenv("MONGO_TEST_URI")
+	if uri == "" {
+		t.Skip("MONGO_TEST_URI not set")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(uri))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Disconnect(ctx)
+	db := client.Database("pokemmoraids_test_" + newID())
+	defer db.Drop(ctx)
+
+	r := newTestJSONRepository(t, repoTestSeasons())
+	if err := migrateBossesToMongo(ctx, db, r.path, false); err != nil {
+		t.Fatal(err)
+	}
+
+	// existing seasons are skipped unless overwrite is set
+	changed := repoTestSeasons()
+	changed[0].RaidBosses[0].Name = "Shiny Glaceon"
+	r = newTestJSONRepository(t, changed)
+	bossName := func() string {
+		var doc SeasonDocument
+		if err := db.Collection("raid_seasons").FindOne(ctx, bson.M{"code": "christmas_2024"}).Decode(&doc); err != nil {
+			t.Fatal(err)
+		}
+		return doc.RaidBosses[0].Name
+	}
+	if err := migrateBossesToMongo(ctx, db, r.path, false); err != nil {
+		t.Fatal(err)
+	}
+	if got := bossName(); got != "Glaceon" {
+		t.Errorf("migration without overwrite replaced the season: boss %q", got)
+	}
+	if err := migrateBossesToMongo(ctx, db, r.path, true); err != nil {
+		t.Fatal(err)
+	}
+	if got := bossName(); got != "Shiny Glaceon" {
+		t.Errorf("migration with overwrite kept boss %q", got)
+	}
+
+	repo, err := newMongoBossRepository(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	seasons, err := repo.Seasons(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(seasons) != 2 || seasonCode(seasons[0]) != "christmas_2024" || seasonCode(seasons[1]) != "halloween_2024" {
+		t.Errorf("migrated seasons = %+v", seasons)
+	}
+}
+
+func TestUpdateBossInRevisions(t *testing.T) {
+	seasons := repoTestSeasons()
+	seasons[0].RaidBosses[0].Revision = 3
+	seasons[0].RaidBosses[0].Variations = append(seasons[0].RaidBosses[0].Variations,
+		Variation{ID: "v2", Revision: 4, HealthRemaining: []float64{50}})
+
+	// a stale edit is rejected with the stored copy
+	stale := seasons[0].RaidBosses[0].clone()
+	stale.Name = "Shiny Glaceon"
+	err := updateBossIn(seasons, "christmas_2024", &stale, 2)
+	var conflict *revisionConflictError
+	if !errors.As(err, &conflict) {
+		t.Fatalf("err = %v, want a revision conflict", err)
+	}
+	current, ok := conflict.Current.(RaidBoss)
+	if !ok || current.Revision != 3 || current.Name != "Glaceon" {
+		t.Errorf("conflict carries %+v, want the stored boss", conflict.Current)
+	}
+	if seasons[0].RaidBosses[0].Name != "Glaceon" {
+		t.Error("stale edit changed the boss")
+	}
+
+	// only variations whose content changed get a new revision
+	boss := seasons[0].RaidBosses[0].clone()
+	boss.Variations[0].HealthRemaining = []float64{70}
+	boss.Variations = append(boss.Variations, Variation{ID: "v3", HealthRemaining: []float64{10}})
+	if err := updateBossIn(seasons, "christmas_2024", &boss, 3); err != nil {
+		t.Fatal(err)
+	}
+	stored := seasons[0].RaidBosses[0]
+	got := []int{stored.Revision, stored.Variations[0].Revision, stored.Variations[1].Revision, stored.Variations[2].Revision}
+	if want := []int{4, 2, 4, 1}; !reflect.DeepEqual(got, want) {
+		t.Errorf("boss, v1, v2, v3 revisions = %v, want %v", got, want)
+	}
+}
+
+func TestUpdateVariationInConflict(t *testing.T) {
+	seasons := repoTestSeasons()
+	v := Variation{ID: "v1", Revision: 2, HealthRemaining: []float64{40}}
+	err := updateVariationIn(seasons, "christmas_2024", "b1", &v, 0)
+	var conflict *revisionConflictError
+	if !errors.As(err, &conflict) {
+		t.Fatalf("err = %v, want a revision conflict", err)
+	}
+	if current, ok := conflict.Current.(Variation); !ok || current.Revision != 1 || current.HealthRemaining[0] != 80 {
+		t.Errorf("conflict carries %+v, want the stored variation", conflict.Current)
+	}
+	if seasons[0].RaidBosses[0].Revision != 1 {
+		t.Error("stale edit bumped the boss revision")
+	}
+}
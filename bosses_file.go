@@ -0,0 +1,174 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// dataBackups is how many timestamped copies of bosses.json to keep; 0 disables backups
+var dataBackups = func() int {
+	n, err := strconv.Atoi(getEnvOrDefault("DATA_BACKUPS", "10"))
+	if err != nil || n < 0 {
+		return 10
+	}
+	return n
+}()
+
+const backupTimeFormat = "20060102-150405.000000000"
+
+// backupDir returns the directory holding backups of the given data file
+func backupDir(path string) string {
+	return filepath.Join(filepath.Dir(path), "backups")
+}
+
+// backupPrefix returns the file name prefix used for backups of path, e.g. "bosses-"
+func backupPrefix(path string) string {
+	base := filepath.Base(path)
+	return strings.TrimSuffix(base, filepath.Ext(base)) + "-"
+}
+
+// readSeasonsFile decodes a seasons file, rejecting empty or truncated content
+func readSeasonsFile(path string) ([]Season, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return decodeSeasons(path, data)
+}
+
+// decodeSeasons decodes the contents of the seasons file at path
+func decodeSeasons(path string, data []byte) ([]Season, error) {
+	var seasons []Season
+	if err := json.Unmarshal(data, &seasons); err != nil {
+		return nil, err
+	}
+	if seasons == nil {
+		return nil, fmt.Errorf("%s contains no seasons list", path)
+	}
+	return seasons, nil
+}
+
+// loadSeasonsWithFallback reads path, falling back to the newest backup that decodes
+func loadSeasonsWithFallback(path string) ([]Season, error) {
+	seasons, err := readSeasonsFile(path)
+	if err == nil {
+		return seasons, nil
+	}
+	log.Printf("warning: failed to read %s: %v; trying backups", path, err)
+
+	backups, _ := listBackups(path)
+	for i := len(backups) - 1; i >= 0; i-- {
+		seasons, berr := readSeasonsFile(backups[i])
+		if berr != nil {
+			log.Printf("warning: skipping backup %s: %v", backups[i], berr)
+			continue
+		}
+		log.Printf("Recovered seasons data from backup %s", backups[i])
+		return seasons, nil
+	}
+	return nil, err
+}
+
+// listBackups returns backup files for path, oldest first
+func listBackups(path string) ([]string, error) {
+	entries, err := os.ReadDir(backupDir(path))
+	if err != nil {
+		return nil, err
+	}
+	prefix := backupPrefix(path)
+	var out []string
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasPrefix(e.Name(), prefix) && strings.HasSuffix(e.Name(), filepath.Ext(path)) {
+			out = append(out, filepath.Join(backupDir(path), e.Name()))
+		}
+	}
+	// the timestamp format sorts lexically in chronological order
+	sort.Strings(out)
+	return out, nil
+}
+
+// backupFile copies the current contents of path into the backup directory and
+// removes the oldest backups beyond keep. A missing, empty or corrupt source is
+// skipped, so it never rotates out a backup that still loads.
+func backupFile(path string, keep int) error {
+	if keep <= 0 {
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) || (err == nil && len(bytes.TrimSpace(data)) == 0) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if _, err := decodeSeasons(path, data); err != nil {
+		log.Printf("warning: not backing up %s: %v", path, err)
+		return nil
+	}
+
+	dir := backupDir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	name := backupPrefix(path) + time.Now().UTC().Format(backupTimeFormat) + filepath.Ext(path)
+	if err := writeFileAtomic(filepath.Join(dir, name), data, 0644); err != nil {
+		return err
+	}
+
+	backups, err := listBackups(path)
+	if err != nil {
+		return err
+	}
+	for len(backups) > keep {
+		if err := os.Remove(backups[0]); err != nil {
+			return err
+		}
+		backups = backups[1:]
+	}
+	return nil
+}
+
+// writeFileAtomic writes data to a temp file in the same directory, fsyncs it
+// and renames it over path, so readers see either the old or the new content.
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	dir := filepath.Dir(path)
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmpName := tmp.Name()
+	defer os.Remove(tmpName) // no-op once renamed
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(perm); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpName, path); err != nil {
+		return err
+	}
+
+	// persist the rename itself
+	if d, err := os.Open(dir); err == nil {
+		d.Sync()
+		d.Close()
+	}
+	return nil
+}
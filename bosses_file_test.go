@@ -0,0 +1,135 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func writeTestFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadSeasonsWithFallback(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "bosses.json")
+	backups := backupDir(path)
+	writeTestFile(t, path, `[{"season": "Christmas", "year": 20`)
+	writeTestFile(t, filepath.Join(backups, "bosses-20250101-120000.000000000.json"), `[{"season": "Old", "year": 2024, "raid_bosses": []}]`)
+	writeTestFile(t, filepath.Join(backups, "bosses-20250102-120000.000000000.json"), `[{"season": "Newer", "year": 2024, "raid_bosses": []}]`)
+	writeTestFile(t, filepath.Join(backups, "bosses-20250103-120000.000000000.json"), ``)
+
+	seasons, err := loadSeasonsWithFallback(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(seasons) != 1 || seasons[0].SeasonName != "Newer" {
+		t.Errorf("recovered %+v, want the newest valid backup", seasons)
+	}
+
+	// without a valid backup the primary file's error is returned
+	os.RemoveAll(backups)
+	if _, err := loadSeasonsWithFallback(path); err == nil {
+		t.Error("corrupt file without backups loaded")
+	}
+}
+
+func TestBackupFileRotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bosses.json")
+	const keep = 3
+	for i := 0; i < 5; i++ {
+		writeTestFile(t, path, `[{"season": "S`+strconv.Itoa(i)+`", "year": 2024, "raid_bosses": []}]`)
+		if err := backupFile(path, keep); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	backups, err := listBackups(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(backups) != keep {
+		t.Fatalf("kept %d backups, want %d", len(backups), keep)
+	}
+	// the oldest copies are the ones removed
+	for i, b := range backups {
+		data, err := os.ReadFile(b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := `"S` + strconv.Itoa(i+2) + `"`; !strings.Contains(string(data), want) {
+			t.Errorf("backup %d = %s, want season %s", i, data, want)
+		}
+	}
+
+	// empty or corrupt data files are not backed up over good copies
+	for _, content := range []string{"", `[{"season": "S5", "ye`, `{"season": "S5"}`} {
+		writeTestFile(t, path, content)
+		if err := backupFile(path, keep); err != nil {
+			t.Fatal(err)
+		}
+		if after, _ := listBackups(path); len(after) != keep || after[0] != backups[0] {
+			t.Errorf("data file %q changed the backups: %v", content, after)
+		}
+	}
+}
+
+func TestWriteFileAtomic(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "bosses.json")
+	writeTestFile(t, path, "old")
+
+	if err := writeFileAtomic(path, []byte("new"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "new" {
+		t.Errorf("content = %q, want %q", data, "new")
+	}
+
+	// the rename fails when the target is a non-empty directory
+	target := filepath.Join(dir, "taken")
+	writeTestFile(t, filepath.Join(target, "bosses.json"), "old")
+	if err := writeFileAtomic(target, []byte("new"), 0644); err == nil {
+		t.Fatal("write over a directory succeeded")
+	}
+	if data, _ := os.ReadFile(filepath.Join(target, "bosses.json")); string(data) != "old" {
+		t.Errorf("failed write changed the old content to %q", data)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range entries {
+		if strings.Contains(e.Name(), ".tmp-") {
+			t.Errorf("failed write left %s behind", e.Name())
+		}
+	}
+}
+
+func TestWriteFileAtomicKeepsOldFile(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("root can write to read-only directories")
+	}
+	dir := t.TempDir()
+	path := filepath.Join(dir, "bosses.json")
+	writeTestFile(t, path, "old")
+	if err := os.Chmod(dir, 0555); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chmod(dir, 0755)
+
+	if err := writeFileAtomic(path, []byte("new"), 0644); err == nil {
+		t.Fatal("write into a read-only directory succeeded")
+	}
+	if data, _ := os.ReadFile(path); string(data) != "old" {
+		t.Errorf("failed write changed the file to %q", data)
+	}
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// checklistTemplateUser is the user_id of a season's checklist template. Admins
+// edit the template; each user's checklist is seeded from it.
+const checklistTemplateUser = "default"
+
+// checklistKey identifies a checklist Pokémon; the same Pokémon may appear once per usage
+func checklistKey(p PokemonChecklistEntry) string {
+	return p.Name + "\x00" + p.Usage
+}
+
+// mergeChecklist returns the template's Pokémon with the completion state of
+// the user's own entries, so Pokémon added to or removed from the template
+// after the user's checklist was seeded are picked up
+func mergeChecklist(template, user []PokemonChecklistEntry) []PokemonChecklistEntry {
+	completed := make(map[string]bool, len(user))
+	for _, p := range user {
+		completed[checklistKey(p)] = p.Completed
+	}
+	merged := make([]PokemonChecklistEntry, len(template))
+	for i, p := range template {
+		p.Types = append([]string(nil), p.Types...)
+		p.Completed = completed[checklistKey(p)]
+		merged[i] = p
+	}
+	return merged
+}
+
+// userChecklist returns username's checklist for a season, seeded from the
+// template when the user has none yet. Anonymous visitors get the template
+// with nothing completed. It returns mongo.ErrNoDocuments when the season has
+// no template.
+func (a *App) userChecklist(ctx context.Context, season, username string) (ChecklistDocument, error) {
+	collection := a.mongoDB.Collection("checklists")
+	var template ChecklistDocument
+	if err := collection.FindOne(ctx, bson.M{"season": season, "user_id": checklistTemplateUser}).Decode(&template); err != nil {
+		return ChecklistDocument{}, err
+	}
+
+	var own ChecklistDocument
+	if username != "" {
+		err := collection.FindOne(ctx, bson.M{"season": season, "user_id": username}).Decode(&own)
+		if err != nil && err != mongo.ErrNoDocuments {
+			return ChecklistDocument{}, err
+		}
+	}
+	return ChecklistDocument{
+		ID:        own.ID,
+		Season:    season,
+		UserID:    username,
+		Pokemon:   mergeChecklist(template.Pokemon, own.Pokemon),
+		UpdatedAt: own.UpdatedAt,
+	}, nil
+}
+
+// saveUserChecklist stores a user's checklist, creating it on the first save
+func (a *App) saveUserChecklist(ctx context.Context, doc ChecklistDocument) error {
+	doc.ID = primitive.NilObjectID // the _id of an existing document is kept
+	_, err := a.mongoDB.Collection("checklists").ReplaceOne(ctx,
+		bson.M{"season": doc.Season, "user_id": doc.UserID},
+		doc,
+		options.Replace().SetUpsert(true),
+	)
+	return err
+}
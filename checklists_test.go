@@ -0,0 +1,24 @@
+package main
+
+import "testing"
+
+func TestMergeChecklist(t *testing.T) {
+	template := []PokemonChecklistEntry{
+		{Name: "Golduck", Usage: "Special", Completed: true},
+		{Name: "Golduck", Usage: "Support"},
+		{Name: "Snorlax", Usage: "Physical"},
+	}
+	user := []PokemonChecklistEntry{
+		{Name: "Golduck", Usage: "Support", Completed: true},
+		{Name: "Machamp", Usage: "Physical", Completed: true}, // since removed from the template
+	}
+	got := mergeChecklist(template, user)
+	if len(got) != 3 {
+		t.Fatalf("got %d entries, want the template's 3", len(got))
+	}
+	for i, want := range []bool{false, true, false} {
+		if got[i].Completed != want {
+			t.Errorf("%s (%s): completed %v, want %v", got[i].Name, got[i].Usage, got[i].Completed, want)
+		}
+	}
+}
@@ -0,0 +1,358 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// commentLimiter limits how many comments a user can post per hour
+var commentLimiter = newRateLimiter(20, time.Hour)
+
+// Comment is a Markdown comment in the discussion of a boss or one of its
+// variations; replies point at the comment they answer
+type Comment struct {
+	ID          primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	Season      string             `json:"season" bson:"season"`
+	BossID      string             `json:"boss_id" bson:"boss_id"`
+	VariationID string             `json:"variation_id" bson:"variation_id"`               // empty for the boss discussion
+	Turn        int                `json:"turn" bson:"turn"`                               // 1-based, 0 for the whole variation
+	ParentID    string             `json:"parent_id,omitempty" bson:"parent_id,omitempty"` // hex id of the comment replied to
+	Author      string             `json:"author" bson:"author"`
+	Body        string             `json:"body" bson:"body"`       // Markdown
+	Hidden      bool               `json:"hidden" bson:"hidden"`   // hidden by a mod, still shown to staff
+	Deleted     bool               `json:"deleted" bson:"deleted"` // kept as a placeholder so replies stay threaded
+	ModeratedBy string             `json:"moderated_by,omitempty" bson:"moderated_by,omitempty"`
+	CreatedAt   time.Time          `json:"created_at" bson:"created_at"`
+	HTML        string             `json:"html" bson:"-"` // sanitized rendering of Body
+	Replies     []*Comment         `json:"replies" bson:"-"`
+}
+
+// commentStore keeps comments in the comments collection
+type commentStore struct {
+	coll *mongo.Collection
+}
+
+func newCommentStore(db *mongo.Database) *commentStore {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	coll := db.Collection("comments")
+	_, err := coll.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "boss_id", Value: 1}, {Key: "variation_id", Value: 1}, {Key: "created_at", Value: 1}},
+	})
+	if err != nil {
+		log.Printf("warning: failed to create comments index: %v", err)
+	}
+	return &commentStore{coll: coll}
+}
+
+func (s *commentStore) Create(ctx context.Context, c *Comment) error {
+	res, err := s.coll.InsertOne(ctx, c)
+	if err != nil {
+		return err
+	}
+	c.ID = res.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+// Get returns the comment with the given hex id
+func (s *commentStore) Get(ctx context.Context, id string) (*Comment, error) {
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, mongo.ErrNoDocuments
+	}
+	var c Comment
+	if err := s.coll.FindOne(ctx, bson.M{"_id": oid}).Decode(&c); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// List returns the comments of a boss's discussion (variationID "") or of one
+// of its variations, oldest first
+func (s *commentStore) List(ctx context.Context, bossID, variationID string) ([]Comment, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}})
+	cursor, err := s.coll.Find(ctx, bson.M{"boss_id": bossID, "variation_id": variationID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	comments := []Comment{}
+	if err := cursor.All(ctx, &comments); err != nil {
+		return nil, err
+	}
+	return comments, nil
+}
+
+// Counts returns how many visible comments each variation of a boss has; the
+// boss discussion is counted under ""
+func (s *commentStore) Counts(ctx context.Context, bossID string) (map[string]int, error) {
+	cursor, err := s.coll.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"boss_id": bossID, "hidden": false, "deleted": false}}},
+		{{Key: "$group", Value: bson.M{"_id": "$variation_id", "count": bson.M{"$sum": 1}}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var rows []struct {
+		VariationID string `bson:"_id"`
+		Count       int    `bson:"count"`
+	}
+	if err := cursor.All(ctx, &rows); err != nil {
+		return nil, err
+	}
+	counts := make(map[string]int, len(rows))
+	for _, row := range rows {
+		counts[row.VariationID] = row.Count
+	}
+	return counts, nil
+}
+
+// Moderate hides, unhides or deletes a comment. Deleting clears the body but
+// keeps the comment so its replies stay in their thread.
+func (s *commentStore) Moderate(ctx context.Context, id primitive.ObjectID, action, by string) error {
+	set := bson.M{"moderated_by": by}
+	switch action {
+	case "hide":
+		set["hidden"] = true
+	case "unhide":
+		set["hidden"] = false
+	case "delete":
+		set["deleted"] = true
+		set["body"] = ""
+	}
+	res, err := s.coll.UpdateByID(ctx, id, bson.M{"$set": set})
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+// commentThreads nests comments (oldest first) under the comments they reply
+// to and renders their Markdown. Deleted comments, and hidden ones unless staff
+// is set, lose their body and author and are dropped when no visible reply
+// hangs off them. Replies to unknown comments become threads of their own.
+func commentThreads(comments []Comment, staff bool) []*Comment {
+	nodes := make(map[string]*Comment, len(comments))
+	for i := range comments {
+		c := &comments[i]
+		c.Replies = []*Comment{}
+		if c.Deleted || (c.Hidden && !staff) {
+			c.Body, c.Author = "", ""
+		} else {
+			c.HTML = renderMarkdown(c.Body)
+		}
+		nodes[c.ID.Hex()] = c
+	}
+	var roots []*Comment
+	for i := range comments {
+		c := &comments[i]
+		if parent, ok := nodes[c.ParentID]; ok && parent != c {
+			parent.Replies = append(parent.Replies, c)
+		} else {
+			roots = append(roots, c)
+		}
+	}
+
+	var prune func(cs []*Comment) []*Comment
+	prune = func(cs []*Comment) []*Comment {
+		kept := []*Comment{}
+		for _, c := range cs {
+			c.Replies = prune(c.Replies)
+			if c.HTML != "" || len(c.Replies) > 0 {
+				kept = append(kept, c)
+			}
+		}
+		return kept
+	}
+	return prune(roots)
+}
+
+// commentCounts fills in the comment count of each variation of boss and
+// returns the count of the boss discussion
+func (a *App) commentCounts(ctx context.Context, boss *RaidBoss) int {
+	counts, err := a.comments.Counts(ctx, boss.ID)
+	if err != nil {
+		log.Printf("Error counting comments for %s: %v", boss.ID, err)
+		return 0
+	}
+	for i := range boss.Variations {
+		boss.Variations[i].CommentCount = counts[boss.Variations[i].ID]
+	}
+	return counts[""]
+}
+
+// maxCommentLength bounds the Markdown body of a comment, in characters
+const maxCommentLength = 2000
+
+// validateComment checks a comment has a body of 1–maxCommentLength characters
+// and, on a variation with turns turns, a turn within 0–turns (0 for the whole
+// variation); boss discussion comments have no turn
+func validateComment(c Comment, turns int) []FieldError {
+	var errs []FieldError
+	add := func(field, format string, args ...interface{}) {
+		errs = append(errs, FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+	if n := utf8.RuneCountInString(c.Body); n == 0 || n > maxCommentLength {
+		add("body", "must be 1–%d characters, got %d", maxCommentLength, n)
+	}
+	if c.VariationID == "" && c.Turn != 0 {
+		add("turn", "only variation comments can be about a turn")
+	} else if c.Turn < 0 || c.Turn > turns {
+		add("turn", "must be between 0 and %d, got %d", turns, c.Turn)
+	}
+	return errs
+}
+
+// commentsHandler returns the comment threads of a boss's discussion or of one
+// of its variations (GET) or posts a comment or reply for the signed-in user (POST)
+func (a *App) commentsHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	switch r.Method {
+	case http.MethodGet:
+		bossID := r.URL.Query().Get("boss_id")
+		if bossID == "" {
+			http.Error(w, "boss_id is required", http.StatusBadRequest)
+			return
+		}
+		comments, err := a.comments.List(ctx, bossID, r.URL.Query().Get("variation_id"))
+		if err != nil {
+			log.Printf("Error listing comments: %v", err)
+			http.Error(w, "failed to load comments", http.StatusInternalServerError)
+			return
+		}
+		role := getRoleFromRequest(r)
+		threads := commentThreads(comments, role == "admin" || role == "mod")
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(threads)
+	case http.MethodPost:
+		username := getUsernameFromRequest(r)
+		if username == "" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		var c Comment
+		if err := json.NewDecoder(r.Body).Decode(&c); err != nil {
+			http.Error(w, "invalid request body", http.StatusBadRequest)
+			return
+		}
+		if c.ParentID != "" {
+			// replies belong to the discussion and turn of the comment they answer
+			parent, err := a.comments.Get(ctx, c.ParentID)
+			if err == mongo.ErrNoDocuments || (err == nil && parent.Deleted) {
+				http.Error(w, "comment not found", http.StatusNotFound)
+				return
+			} else if err != nil {
+				log.Printf("Error loading comment: %v", err)
+				http.Error(w, "failed to load comment", http.StatusInternalServerError)
+				return
+			}
+			c.BossID, c.VariationID, c.Turn = parent.BossID, parent.VariationID, parent.Turn
+		}
+		current := a.store.Current()
+		boss := findBossByID(&current, c.BossID)
+		if boss == nil {
+			http.Error(w, errBossNotFound.Error(), http.StatusNotFound)
+			return
+		}
+		turns := 0
+		if c.VariationID != "" {
+			i := findVariationIndex(boss, c.VariationID)
+			if i < 0 {
+				http.Error(w, errVariationNotFound.Error(), http.StatusNotFound)
+				return
+			}
+			turns = len(boss.Variations[i].HealthRemaining)
+		}
+		c.Body = strings.TrimSpace(c.Body)
+		if errs := validateComment(c, turns); len(errs) > 0 {
+			writeValidationErrors(w, errs)
+			return
+		}
+		if !commentLimiter.Allow(username) {
+			http.Error(w, "too many comments, try again later", http.StatusTooManyRequests)
+			return
+		}
+
+		c.ID = primitive.NilObjectID
+		c.Season = a.getSeasonName()
+		c.Author = username
+		c.Hidden, c.Deleted, c.ModeratedBy = false, false, ""
+		c.CreatedAt = time.Now()
+		if err := a.comments.Create(ctx, &c); err != nil {
+			log.Printf("Error saving comment: %v", err)
+			http.Error(w, "failed to save comment", http.StatusInternalServerError)
+			return
+		}
+		c.HTML = renderMarkdown(c.Body)
+		c.Replies = []*Comment{}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(c)
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// adminCommentModerateHandler hides, unhides or deletes a comment (admins and mods)
+func (a *App) adminCommentModerateHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	role := getRoleFromRequest(r)
+	if role != "admin" && role != "mod" {
+		http.Error(w, "forbidden", http.StatusForbidden)
+		return
+	}
+	var req struct {
+		ID     string `json:"id"`
+		Action string `json:"action"` // hide, unhide or delete
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.Action != "hide" && req.Action != "unhide" && req.Action != "delete" {
+		http.Error(w, "action must be hide, unhide or delete", http.StatusBadRequest)
+		return
+	}
+	id, err := primitive.ObjectIDFromHex(req.ID)
+	if err != nil {
+		http.Error(w, "comment not found", http.StatusNotFound)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	err = a.comments.Moderate(ctx, id, req.Action, getUsernameFromRequest(r))
+	if err == mongo.ErrNoDocuments {
+		http.Error(w, "comment not found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		log.Printf("Error moderating comment: %v", err)
+		http.Error(w, "failed to moderate comment", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": "success", "id": req.ID, "action": req.Action})
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestCommentThreads(t *testing.T) {
+	ids := make([]primitive.ObjectID, 6)
+	for i := range ids {
+		ids[i] = primitive.NewObjectID()
+	}
+	comments := func() []Comment {
+		return []Comment{
+			{ID: ids[0], Author: "ash", Body: "Turn 3 fails with a slow Golduck"},
+			{ID: ids[1], ParentID: ids[0].Hex(), Author: "misty", Body: "Use a Choice Scarf"},
+			{ID: ids[2], Author: "brock", Deleted: true},
+			{ID: ids[3], ParentID: ids[2].Hex(), Author: "gary", Body: "reply to a deleted comment"},
+			{ID: ids[4], Author: "team rocket", Body: "spam", Hidden: true},
+			{ID: ids[5], ParentID: primitive.NewObjectID().Hex(), Author: "oak", Body: "orphan"},
+		}
+	}
+
+	threads := commentThreads(comments(), false)
+	if len(threads) != 3 {
+		t.Fatalf("got %d threads, want 3: %+v", len(threads), threads)
+	}
+	if len(threads[0].Replies) != 1 || threads[0].Replies[0].Author != "misty" || threads[0].HTML == "" {
+		t.Errorf("thread 0: %+v", threads[0])
+	}
+	if d := threads[1]; d.Author != "" || d.HTML != "" || len(d.Replies) != 1 {
+		t.Errorf("deleted comment with a reply should stay as a placeholder: %+v", d)
+	}
+	if threads[2].Author != "oak" {
+		t.Errorf("orphaned reply should be its own thread: %+v", threads[2])
+	}
+
+	staff := commentThreads(comments(), true)
+	if len(staff) != 4 || staff[2].Author != "team rocket" || !staff[2].Hidden {
+		t.Errorf("staff should see the hidden comment: %+v", staff)
+	}
+}
+
+func TestValidateComment(t *testing.T) {
+	if errs := validateComment(Comment{VariationID: "v1", Turn: 3, Body: "turn 3 fails with a slow Golduck"}, 5); len(errs) != 0 {
+		t.Fatalf("valid comment rejected: %+v", errs)
+	}
+	for name, c := range map[string]Comment{
+		"empty body":     {VariationID: "v1"},
+		"long body":      {VariationID: "v1", Body: strings.Repeat("a", maxCommentLength+1)},
+		"turn too high":  {VariationID: "v1", Turn: 6, Body: "hi"},
+		"boss with turn": {Turn: 1, Body: "hi"},
+	} {
+		if errs := validateComment(c, 5); len(errs) != 1 {
+			t.Errorf("%s: got %+v, want one error", name, errs)
+		}
+	}
+}
@@ -0,0 +1,438 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"pokemmoraids/typechart"
+)
+
+// raidHPMultiplier scales a boss's HP stat to its raid HP pool
+var raidHPMultiplier = func() float64 {
+	f, err := strconv.ParseFloat(getEnvOrDefault("RAID_HP_MULTIPLIER", "1"), 64)
+	if err != nil || f <= 0 {
+		return 1
+	}
+	return f
+}()
+
+// calcHP applies the Gen 3+ HP formula
+func calcHP(base, iv, ev, level int) int {
+	return (2*base+iv+ev/4)*level/100 + level + 10
+}
+
+// baseDamage is the damage before random roll and modifiers
+func baseDamage(level, power, attack, defense int) int {
+	return (2*level/5+2)*power*attack/defense/50 + 2
+}
+
+// damageModifier is one multiplier applied after the base damage, in battle order
+type damageModifier struct {
+	Name  string  `json:"name"`
+	Value float64 `json:"value"`
+}
+
+// damageRolls applies the 16 random rolls (85–100%) and then each modifier,
+// rounding down after every step. Damage is at least 1 unless a modifier is 0.
+func damageRolls(base int, mods []damageModifier) []int {
+	rolls := make([]int, 0, 16)
+	for r := 85; r <= 100; r++ {
+		d := base * r / 100
+		zero := false
+		for _, m := range mods {
+			d = int(math.Floor(float64(d) * m.Value))
+			zero = zero || m.Value == 0
+		}
+		if d < 1 && !zero {
+			d = 1
+		}
+		rolls = append(rolls, d)
+	}
+	return rolls
+}
+
+// typeBoostItems raise the power of one type's moves by 20%
+var typeBoostItems = map[string]string{
+	"charcoal": "FIRE", "mysticwater": "WATER", "miracleseed": "GRASS", "magnet": "ELECTRIC",
+	"nevermeltice": "ICE", "blackbelt": "FIGHTING", "poisonbarb": "POISON", "softsand": "GROUND",
+	"sharpbeak": "FLYING", "twistedspoon": "PSYCHIC", "silverpowder": "BUG", "hardstone": "ROCK",
+	"spelltag": "GHOST", "dragonfang": "DRAGON", "blackglasses": "DARK", "metalcoat": "STEEL",
+	"silkscarf": "NORMAL",
+}
+
+// pinchAbilities boost one type's moves by 50% at or below a third of HP
+var pinchAbilities = map[string]string{
+	"blaze": "FIRE", "torrent": "WATER", "overgrow": "GRASS", "swarm": "BUG",
+}
+
+// fixedDamage returns the damage of true damage moves that do not depend on
+// stats, or false when the move's damage cannot be estimated up front.
+// currentHP is the boss's remaining HP, or 0 when unknown.
+func fixedDamage(move moveInfo, level, currentHP int) (int, bool) {
+	switch squashName(move.Name) {
+	case "seismictoss", "nightshade":
+		return level, true
+	case "dragonrage":
+		return 40, true
+	case "sonicboom":
+		return 20, true
+	case "superfang":
+		if currentHP > 0 {
+			return max(currentHP/2, 1), true
+		}
+	}
+	return 0, false
+}
+
+// activeScreens reports whether the boss has Reflect or Light Screen up at the
+// given health, from phase effects that have triggered by then
+func activeScreens(b RaidBoss, health float64) (reflect, lightScreen bool) {
+	for _, pe := range b.PhaseEffects {
+		if float64(pe.Health) < health {
+			continue
+		}
+		if pe.Kind == phaseScreen {
+			reflect = reflect || pe.Params.Screen == "reflect"
+			lightScreen = lightScreen || pe.Params.Screen == "light_screen"
+		}
+	}
+	return reflect, lightScreen
+}
+
+// attackInput describes one player attack on a boss
+type attackInput struct {
+	Pokemon       monsterInfo
+	Move          moveInfo
+	Nature        string // nature name, or "plus", "neutral" or "minus"
+	EVs, IVs      int
+	Level         int
+	Power         int      // overrides the move's base power when set
+	Effectiveness *float64 // worked out from the boss's types when nil
+	Stage         int      // attacker's attacking stat stage
+	BossStage     int      // boss's defending stat stage
+	Item, Ability string
+	Weather       string // "rain", "sun", "sand", "hail" or ""
+	Crit, Burned  bool
+	Pinch         bool    // attacker is at or below a third of its HP
+	BossHealth    float64 // boss HP % when the move lands
+	Reflect       bool
+	LightScreen   bool
+}
+
+// damageEstimate is the outcome of estimateDamage
+type damageEstimate struct {
+	Power         int
+	Attack        int
+	Defense       int
+	Effectiveness float64
+	Mods          []damageModifier
+	Rolls         []int // ascending; fixed damage moves have one value twice
+}
+
+// damageError explains why a move's damage cannot be estimated
+type damageError string
+
+func (e damageError) Error() string { return string(e) }
+
+// bossMaxHP returns the boss's raid HP from its HP base stat, falling back to
+// its Pokémon's, or 0 when unknown
+func (a *App) bossMaxHP(b RaidBoss) int {
+	baseHP := b.BaseStats.HP
+	if baseHP == 0 {
+		if m, ok := a.gameData.bossMonster(b); ok {
+			baseHP = m.Stats.HP
+		}
+	}
+	if baseHP == 0 {
+		return 0
+	}
+	return int(float64(calcHP(baseHP, 31, 0, raidLevel)) * raidHPMultiplier)
+}
+
+// estimateDamage applies the Gen 5 damage formula to an attack on boss
+func (a *App) estimateDamage(boss RaidBoss, in attackInput) (damageEstimate, error) {
+	move := in.Move
+	if move.Category == "STATUS" {
+		return damageEstimate{}, damageError("status moves deal no damage")
+	}
+	physical := move.Category == "PHYSICAL"
+	statName, defense := "sp_attack", boss.BaseStats.SpDef
+	if physical {
+		statName, defense = "attack", boss.BaseStats.Def
+	}
+	nature, err := natureMultiplier(in.Nature, statName)
+	if err != nil {
+		return damageEstimate{}, err
+	}
+
+	est := damageEstimate{Power: move.Power, Effectiveness: 1, Mods: []damageModifier{}}
+	if in.Power > 0 {
+		est.Power = in.Power
+	}
+	if in.Effectiveness != nil {
+		est.Effectiveness = *in.Effectiveness
+	} else if types := a.bossTypes(boss); len(types) > 0 {
+		est.Effectiveness = typechart.Effectiveness(move.Type, types...)
+	}
+
+	bossHP := a.bossMaxHP(boss)
+	if fixed, ok := fixedDamage(move, in.Level, int(float64(bossHP)*in.BossHealth/100)); ok {
+		if est.Effectiveness == 0 {
+			fixed = 0
+		}
+		est.Rolls = []int{fixed, fixed}
+		return est, nil
+	}
+	if move.TrueDamage {
+		return damageEstimate{}, damageError(move.Name + " deals damage that cannot be estimated")
+	}
+	if est.Power <= 1 {
+		return damageEstimate{}, damageError(move.Name + " has variable power, enter the power to use")
+	}
+	if defense == 0 {
+		return damageEstimate{}, damageError("boss has no defense stat recorded for this move's category")
+	}
+
+	item := squashName(in.Item)
+	ability := squashName(in.Ability)
+	bossAbility := squashName(boss.Ability)
+	weather := strings.ToLower(in.Weather)
+	moveType := strings.ToUpper(move.Type)
+	eff := est.Effectiveness
+
+	// a critical hit ignores the attacker's drops and the boss's boosts
+	stage, bossStage := in.Stage, in.BossStage
+	if in.Crit {
+		stage = max(stage, 0)
+		bossStage = min(bossStage, 0)
+	}
+
+	if ability == "technician" && est.Power <= 60 {
+		est.Power = est.Power * 3 / 2
+	}
+	if t, ok := typeBoostItems[item]; ok && t == moveType {
+		est.Power = est.Power * 6 / 5
+	}
+	if t, ok := pinchAbilities[ability]; ok && t == moveType && in.Pinch {
+		est.Power = est.Power * 3 / 2
+	}
+
+	base := in.Pokemon.Stats.SpAttack
+	if physical {
+		base = in.Pokemon.Stats.Attack
+	}
+	est.Attack = stageMultiply(calcStat(base, in.IVs, in.EVs, in.Level, nature), stage)
+	switch {
+	case physical && (ability == "hugepower" || ability == "purepower"):
+		est.Attack *= 2
+	case physical && (ability == "hustle" || (ability == "guts" && in.Burned)):
+		est.Attack = est.Attack * 3 / 2
+	}
+	if (physical && item == "choiceband") || (!physical && item == "choicespecs") {
+		est.Attack = est.Attack * 3 / 2
+	}
+	if bossAbility == "thickfat" && (moveType == "FIRE" || moveType == "ICE") {
+		est.Attack /= 2
+	}
+
+	est.Defense = stageMultiply(calcStat(defense, 31, 0, raidLevel, 10), bossStage)
+
+	add := func(name string, v float64) { est.Mods = append(est.Mods, damageModifier{name, v}) }
+	switch {
+	case weather == "rain" && moveType == "WATER", weather == "sun" && moveType == "FIRE":
+		add("weather", 1.5)
+	case weather == "rain" && moveType == "FIRE", weather == "sun" && moveType == "WATER":
+		add("weather", 0.5)
+	}
+	if in.Crit {
+		add("critical hit", 2)
+	}
+	for _, t := range in.Pokemon.Types {
+		if strings.ToUpper(t) == moveType {
+			stab := 1.5
+			if ability == "adaptability" {
+				stab = 2
+			}
+			add("STAB", stab)
+			break
+		}
+	}
+	if eff != 1 {
+		add("type effectiveness", eff)
+	}
+	if physical && in.Burned && ability != "guts" {
+		add("burn", 0.5)
+	}
+	if !in.Crit && ((physical && in.Reflect) || (!physical && in.LightScreen)) {
+		add("screen", 0.5)
+	}
+	if eff > 1 && (bossAbility == "filter" || bossAbility == "solidrock") {
+		add(boss.Ability, 0.75)
+	}
+	if bossAbility == "multiscale" && in.BossHealth == 100 {
+		add(boss.Ability, 0.5)
+	}
+	if eff > 0 && eff < 1 && ability == "tintedlens" {
+		add("Tinted Lens", 2)
+	}
+	switch {
+	case item == "lifeorb":
+		add("Life Orb", 1.3)
+	case item == "expertbelt" && eff > 1:
+		add("Expert Belt", 1.2)
+	case item == "muscleband" && physical, item == "wiseglasses" && !physical:
+		add(in.Item, 1.1)
+	}
+
+	est.Rolls = damageRolls(baseDamage(in.Level, est.Power, est.Attack, est.Defense), est.Mods)
+	return est, nil
+}
+
+// damageCalcHandler estimates the damage a player's move deals to a boss.
+// Query: boss_id, pokemon, move, nature, evs, ivs, level, stage, item, ability,
+// weather, crit, burned, pinch, power, effectiveness, boss_health, boss_stage,
+// reflect, light_screen.
+func (a *App) damageCalcHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	q := r.URL.Query()
+
+	current := a.store.Current()
+	boss := findBossByID(&current, q.Get("boss_id"))
+	if boss == nil {
+		http.Error(w, "boss not found", http.StatusNotFound)
+		return
+	}
+	mon, ok := a.gameData.monster(q.Get("pokemon"))
+	if !ok {
+		http.Error(w, "unknown pokemon or no base stats available", http.StatusNotFound)
+		return
+	}
+	move, ok := a.gameData.move(q.Get("move"))
+	if !ok {
+		http.Error(w, "unknown move", http.StatusNotFound)
+		return
+	}
+
+	var errs []FieldError
+	intParam := func(name string, def, lo, hi int) int {
+		s := q.Get(name)
+		if s == "" {
+			return def
+		}
+		n, err := strconv.Atoi(s)
+		if err != nil || n < lo || n > hi {
+			errs = append(errs, FieldError{Field: name, Message: fmt.Sprintf("must be a number from %d to %d", lo, hi)})
+			return def
+		}
+		return n
+	}
+	floatParam := func(name string, def, lo, hi float64) float64 {
+		s := q.Get(name)
+		if s == "" {
+			return def
+		}
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil || f < lo || f > hi {
+			errs = append(errs, FieldError{Field: name, Message: fmt.Sprintf("must be a number from %v to %v", lo, hi)})
+			return def
+		}
+		return f
+	}
+	boolParam := func(name string) bool {
+		b, _ := strconv.ParseBool(q.Get(name))
+		return b
+	}
+
+	in := attackInput{
+		Pokemon:    mon,
+		Move:       move,
+		Nature:     q.Get("nature"),
+		EVs:        intParam("evs", 0, 0, 252),
+		IVs:        intParam("ivs", 31, 0, 31),
+		Level:      intParam("level", raidLevel, 1, 100),
+		Power:      intParam("power", 0, 1, 300),
+		Stage:      intParam("stage", 0, -6, 6),
+		BossStage:  intParam("boss_stage", 0, -6, 6),
+		Item:       q.Get("item"),
+		Ability:    q.Get("ability"),
+		Weather:    q.Get("weather"),
+		Crit:       boolParam("crit"),
+		Burned:     boolParam("burned"),
+		Pinch:      boolParam("pinch"),
+		BossHealth: floatParam("boss_health", 100, 0, 100),
+	}
+	if q.Get("effectiveness") != "" {
+		eff := floatParam("effectiveness", 1, 0, 4)
+		in.Effectiveness = &eff
+	}
+	if _, err := natureMultiplier(in.Nature, "attack"); err != nil {
+		errs = append(errs, FieldError{Field: "nature", Message: err.Error()})
+	}
+	if len(errs) > 0 {
+		writeValidationErrors(w, errs)
+		return
+	}
+
+	// screens come from the boss's phase effects unless given
+	in.Reflect, in.LightScreen = activeScreens(*boss, in.BossHealth)
+	if v, err := strconv.ParseBool(q.Get("reflect")); err == nil {
+		in.Reflect = v
+	}
+	if v, err := strconv.ParseBool(q.Get("light_screen")); err == nil {
+		in.LightScreen = v
+	}
+
+	est, err := a.estimateDamage(*boss, in)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+
+	bossHP := a.bossMaxHP(*boss)
+	minDamage, maxDamage := est.Rolls[0], est.Rolls[len(est.Rolls)-1]
+	result := map[string]interface{}{
+		"move": map[string]interface{}{
+			"name":     move.Name,
+			"type":     move.Type,
+			"category": move.Category,
+			"power":    est.Power,
+		},
+		"attacker": map[string]interface{}{
+			"name":   mon.Name,
+			"level":  in.Level,
+			"attack": est.Attack,
+		},
+		"boss": map[string]interface{}{
+			"name":         boss.Name,
+			"level":        raidLevel,
+			"defense":      est.Defense,
+			"hp":           bossHP,
+			"reflect":      in.Reflect,
+			"light_screen": in.LightScreen,
+		},
+		"effectiveness": est.Effectiveness,
+		"modifiers":     est.Mods,
+		"min":           minDamage,
+		"max":           maxDamage,
+	}
+	// percentages and hits to KO need the boss's HP
+	if bossHP > 0 {
+		minPct := math.Round(float64(minDamage)*1000/float64(bossHP)) / 10
+		maxPct := math.Round(float64(maxDamage)*1000/float64(bossHP)) / 10
+		result["min_percent"] = minPct
+		result["max_percent"] = maxPct
+		if minDamage > 0 {
+			result["hits_to_ko"] = []int{(bossHP + maxDamage - 1) / maxDamage, (bossHP + minDamage - 1) / minDamage}
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
@@ -0,0 +1,36 @@
+package main
+
+import "testing"
+
+func TestDamageRolls(t *testing.T) {
+	// level 100, 90 power, 300 attack vs 250 defense
+	base := baseDamage(100, 90, 300, 250)
+	if base != 92 {
+		t.Fatalf("base damage %d, want 92", base)
+	}
+	rolls := damageRolls(base, []damageModifier{{"STAB", 1.5}, {"type effectiveness", 2}})
+	if len(rolls) != 16 || rolls[0] != 234 || rolls[15] != 276 {
+		t.Fatalf("got rolls %v", rolls)
+	}
+	if r := damageRolls(base, []damageModifier{{"type effectiveness", 0}}); r[15] != 0 {
+		t.Fatalf("immune move dealt %d", r[15])
+	}
+}
+
+func TestActiveScreens(t *testing.T) {
+	boss := RaidBoss{PhaseEffects: []PhaseEffect{testPhase(100, "Snowscape"), testPhase(66, "Reflect"), testPhase(33, "Light Screen⭐️")}}
+	tests := []struct {
+		health         float64
+		reflect, light bool
+	}{
+		{100, false, false},
+		{66, true, false},
+		{20, true, true},
+	}
+	for _, tt := range tests {
+		reflect, light := activeScreens(boss, tt.health)
+		if reflect != tt.reflect || light != tt.light {
+			t.Errorf("at %v%%: got reflect=%v light=%v", tt.health, reflect, light)
+		}
+	}
+}
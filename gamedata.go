@@ -0,0 +1,457 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+
+	"pokemmoraids/typechart"
+)
+
+// gameDataValidation controls how unknown moves, items and Pokémon are treated
+// on save: "off", "warn" (saved, reported as warnings) or "error" (rejected with 400)
+var gameDataValidation = getEnvOrDefault("GAME_DATA_VALIDATION", "warn")
+
+// nameSet is a list of canonical game names with case-insensitive lookup
+type nameSet struct {
+	byKey    map[string]string // lower-cased name -> canonical name
+	bySquash map[string]string // letters and digits only -> canonical name
+	names    []string
+}
+
+func newNameSet(names []string) *nameSet {
+	s := &nameSet{byKey: make(map[string]string), bySquash: make(map[string]string)}
+	for _, n := range names {
+		n = strings.TrimSpace(n)
+		if n == "" {
+			continue
+		}
+		if _, dup := s.byKey[strings.ToLower(n)]; dup {
+			continue
+		}
+		s.byKey[strings.ToLower(n)] = n
+		s.bySquash[squashName(n)] = n
+		s.names = append(s.names, n)
+	}
+	return s
+}
+
+// available reports whether the set was loaded; checks are skipped when its data file is missing
+func (s *nameSet) available() bool {
+	return s != nil && len(s.names) > 0
+}
+
+func (s *nameSet) known(name string) bool {
+	_, ok := s.byKey[strings.ToLower(strings.TrimSpace(name))]
+	return ok
+}
+
+// suggest returns up to three known names close to name, best first
+func (s *nameSet) suggest(name string) []string {
+	if exact, ok := s.bySquash[squashName(name)]; ok {
+		return []string{exact}
+	}
+	target := []rune(strings.ToLower(strings.TrimSpace(name)))
+	maxDist := max(2, len(target)/4)
+
+	type candidate struct {
+		name string
+		dist int
+	}
+	var found []candidate
+	for _, n := range s.names {
+		if d := levenshtein(target, []rune(strings.ToLower(n))); d <= maxDist {
+			found = append(found, candidate{n, d})
+		}
+	}
+	sort.SliceStable(found, func(i, j int) bool { return found[i].dist < found[j].dist })
+
+	out := []string{}
+	for i := 0; i < len(found) && i < 3; i++ {
+		out = append(out, found[i].name)
+	}
+	return out
+}
+
+// resolve returns the canonical spelling of name, ignoring case and decorations such as "⭐️"
+func (s *nameSet) resolve(name string) (string, bool) {
+	if s == nil {
+		return "", false
+	}
+	if n, ok := s.byKey[strings.ToLower(strings.TrimSpace(name))]; ok {
+		return n, true
+	}
+	n, ok := s.bySquash[squashName(name)]
+	return n, ok
+}
+
+// within finds the longest run of words in text that is a known name, e.g.
+// "Tailwind" in "Prankster Tailwind", and returns the remaining words as rest
+func (s *nameSet) within(text string) (name, rest string, ok bool) {
+	if s == nil {
+		return "", "", false
+	}
+	words := strings.Fields(text)
+	for n := len(words); n > 0; n-- {
+		for i := 0; i+n <= len(words); i++ {
+			if name, ok := s.resolve(strings.Join(words[i:i+n], " ")); ok {
+				rest := append(slices.Clone(words[:i]), words[i+n:]...)
+				return name, strings.Join(rest, " "), true
+			}
+		}
+	}
+	return "", "", false
+}
+
+// squashName keeps only letters and digits, so "NeverMeltIce" matches "Never-Melt Ice"
+func squashName(name string) string {
+	var sb strings.Builder
+	for _, r := range strings.ToLower(name) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
+
+func levenshtein(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+// gameData holds the known Pokémon, move and held item names from the data directory
+type gameData struct {
+	pokemon  *nameSet
+	moves    *nameSet
+	items    *nameSet
+	monsters map[string]monsterInfo // lower-cased name -> stats and types
+	moveInfo map[string]moveInfo    // lower-cased name -> power, type and category
+}
+
+// moveInfo is a moves.json entry
+type moveInfo struct {
+	Name       string `json:"name"`
+	Category   string `json:"skill_damage_type"` // PHYSICAL, SPECIAL or STATUS
+	Power      int    `json:"base_power"`        // 1 for fixed and variable damage moves
+	Priority   int    `json:"priority"`
+	Type       string `json:"type"`
+	TrueDamage bool   `json:"true_damage"`
+}
+
+// Summary describes the move for a tooltip, e.g. "Fire · special · 90 power"
+func (m moveInfo) Summary() string {
+	t, ok := typechart.Normalize(m.Type)
+	if !ok {
+		t = m.Type
+	}
+	parts := []string{t, strings.ToLower(m.Category)}
+	if m.Power > 1 {
+		parts = append(parts, fmt.Sprintf("%d power", m.Power))
+	}
+	if m.Priority != 0 {
+		parts = append(parts, fmt.Sprintf("priority %+d", m.Priority))
+	}
+	return strings.Join(parts, " · ")
+}
+
+// statBlock is a set of the six battle stats
+type statBlock struct {
+	HP        int `json:"hp"`
+	Attack    int `json:"attack"`
+	Defense   int `json:"defense"`
+	SpAttack  int `json:"sp_attack"`
+	SpDefense int `json:"sp_defense"`
+	Speed     int `json:"speed"`
+}
+
+// monsterInfo is the part of a monster.json entry used by the calculators
+type monsterInfo struct {
+	Name  string    `json:"name"`
+	Types []string  `json:"types"`
+	Stats statBlock `json:"base_stats"`
+}
+
+// statKeys lists the spellings monster.json exports may use for each stat
+var statKeys = map[string][]string{
+	"hp":         {"hp"},
+	"attack":     {"attack", "atk"},
+	"defense":    {"defense", "def"},
+	"sp_attack":  {"sp_attack", "special_attack", "spatk", "sp_atk"},
+	"sp_defense": {"sp_defense", "special_defense", "spdef", "sp_def"},
+	"speed":      {"speed", "spe", "spd"},
+}
+
+// parseMonster extracts name, types and base stats from a raw monster.json entry
+func parseMonster(m map[string]interface{}) monsterInfo {
+	info := monsterInfo{}
+	info.Name, _ = m["name"].(string)
+
+	if types, ok := m["types"].([]interface{}); ok {
+		for _, t := range types {
+			switch v := t.(type) {
+			case string:
+				info.Types = append(info.Types, v)
+			case map[string]interface{}:
+				if n, ok := v["name"].(string); ok {
+					info.Types = append(info.Types, n)
+				}
+			}
+		}
+	}
+
+	raw, _ := m["stats"].(map[string]interface{})
+	if raw == nil {
+		raw, _ = m["base_stats"].(map[string]interface{})
+	}
+	stat := func(name string) int {
+		for _, k := range statKeys[name] {
+			if v, ok := raw[k].(float64); ok {
+				return int(v)
+			}
+		}
+		return 0
+	}
+	info.Stats = statBlock{
+		HP:        stat("hp"),
+		Attack:    stat("attack"),
+		Defense:   stat("defense"),
+		SpAttack:  stat("sp_attack"),
+		SpDefense: stat("sp_defense"),
+		Speed:     stat("speed"),
+	}
+	return info
+}
+
+// monster looks up a Pokémon by name, case-insensitively
+func (g *gameData) monster(name string) (monsterInfo, bool) {
+	m, ok := g.monsters[strings.ToLower(strings.TrimSpace(name))]
+	return m, ok
+}
+
+// move looks up a move by name, case-insensitively
+func (g *gameData) move(name string) (moveInfo, bool) {
+	m, ok := g.moveInfo[strings.ToLower(strings.TrimSpace(name))]
+	return m, ok
+}
+
+// findMove looks up a move as written in a variation, e.g. "Water Pulse⭐️".
+// Of alternatives such as "Overheat/Fiery Dance" the first is used, and a move
+// written with extra words such as "Prankster Tailwind" is found within them.
+func (g *gameData) findMove(text string) (moveInfo, bool) {
+	name, ok := g.moves.resolve(strings.Split(text, "/")[0])
+	if !ok {
+		if name, _, ok = g.moves.within(text); !ok {
+			return moveInfo{}, false
+		}
+	}
+	return g.move(name)
+}
+
+// findMonster looks up a Pokémon as written in a variation
+func (g *gameData) findMonster(text string) (monsterInfo, bool) {
+	name, ok := g.pokemon.resolve(strings.Split(text, "/")[0])
+	if !ok {
+		return monsterInfo{}, false
+	}
+	return g.monster(name)
+}
+
+// bossMonster finds the Pokémon a boss is based on. Boss names may carry a
+// suffix such as "Jirachi Hard", so shorter prefixes are tried as well.
+func (g *gameData) bossMonster(b RaidBoss) (monsterInfo, bool) {
+	words := strings.Fields(b.Name)
+	for n := len(words); n > 0; n-- {
+		if m, ok := g.monster(strings.Join(words[:n], " ")); ok {
+			return m, true
+		}
+	}
+	return monsterInfo{}, false
+}
+
+// loadGameData reads monster.json, moves.json and held_items.json from dir.
+// A missing or unreadable file disables the checks for that kind of name.
+func loadGameData(dir string) *gameData {
+	g := &gameData{}
+
+	var mons []map[string]interface{}
+	if err := readJSONFile(filepath.Join(dir, "monster.json"), &mons); err != nil {
+		log.Printf("warning: Pokémon names will not be validated: %v", err)
+	}
+	g.monsters = make(map[string]monsterInfo, len(mons))
+	names := make([]string, 0, len(mons))
+	for _, raw := range mons {
+		m := parseMonster(raw)
+		if m.Name == "" {
+			continue
+		}
+		names = append(names, m.Name)
+		g.monsters[strings.ToLower(m.Name)] = m
+	}
+	g.pokemon = newNameSet(names)
+
+	var moves []moveInfo
+	if err := readJSONFile(filepath.Join(dir, "moves.json"), &moves); err != nil {
+		log.Printf("warning: move names will not be validated: %v", err)
+	}
+	g.moveInfo = make(map[string]moveInfo, len(moves))
+	names = make([]string, 0, len(moves))
+	for _, m := range moves {
+		names = append(names, m.Name)
+		g.moveInfo[strings.ToLower(m.Name)] = m
+	}
+	g.moves = newNameSet(names)
+
+	var items struct {
+		Items []string `json:"items"`
+	}
+	if err := readJSONFile(filepath.Join(dir, "held_items.json"), &items); err != nil {
+		log.Printf("warning: item names will not be validated: %v", err)
+	}
+	g.items = newNameSet(items.Items)
+	return g
+}
+
+func readJSONFile(path string, v interface{}) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// checkName reports value if it is not a known name. Alternatives written as
+// "Overheat/Fiery Dance" are checked separately.
+func checkName(set *nameSet, kind, field, value string) []FieldError {
+	if !set.available() || strings.TrimSpace(value) == "" {
+		return nil
+	}
+	var errs []FieldError
+	for _, part := range strings.Split(value, "/") {
+		if set.known(part) {
+			continue
+		}
+		e := FieldError{Field: field, Message: fmt.Sprintf("unknown %s %q", kind, strings.TrimSpace(part))}
+		if s := set.suggest(part); len(s) > 0 {
+			e.Suggestions = s
+			e.Message += fmt.Sprintf(", did you mean %q?", s[0])
+		}
+		errs = append(errs, e)
+	}
+	return errs
+}
+
+// lintVariation checks the Pokémon, moves and items of every player action
+func (g *gameData) lintVariation(v Variation, prefix string) []FieldError {
+	var errs []FieldError
+	for _, pos := range playerPositions {
+		for t, p := range v.Players[pos] {
+			field := fmt.Sprintf("%splayers.%s[%d].", prefix, pos, t)
+			errs = append(errs, checkName(g.pokemon, "Pokémon", field+"pokemon", p.Pokemon)...)
+			if p.Action == actionMove {
+				errs = append(errs, checkName(g.moves, "move", field+"move", p.Move)...)
+			}
+			errs = append(errs, checkName(g.items, "item", field+"item", p.Item)...)
+		}
+	}
+	return errs
+}
+
+// lintBoss checks the boss's moves and held item and all of its variations
+func (g *gameData) lintBoss(b RaidBoss) []FieldError {
+	var errs []FieldError
+	for i, m := range b.Moves {
+		errs = append(errs, checkName(g.moves, "move", fmt.Sprintf("moves[%d].name", i), m.Name)...)
+	}
+	errs = append(errs, checkName(g.items, "item", "held_item", b.HeldItem)...)
+	for i, v := range b.Variations {
+		errs = append(errs, g.lintVariation(v, fmt.Sprintf("variations[%d].", i))...)
+	}
+	return errs
+}
+
+// checkGameData applies GAME_DATA_VALIDATION to lint findings. In error mode
+// it responds 400 and returns false; otherwise the findings are returned as warnings.
+func checkGameData(w http.ResponseWriter, issues []FieldError) ([]FieldError, bool) {
+	switch gameDataValidation {
+	case "off":
+		return nil, true
+	case "error":
+		if len(issues) > 0 {
+			writeValidationErrors(w, issues)
+			return nil, false
+		}
+	}
+	return issues, true
+}
+
+// adminLintHandler reports unknown names across every boss of a season
+func (a *App) adminLintHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !isStaffRole(getRoleFromRequest(r)) {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	code := r.URL.Query().Get("season")
+	season, ok := a.store.FindSeason(code)
+	if !ok {
+		http.Error(w, "season not found", http.StatusNotFound)
+		return
+	}
+
+	type bossReport struct {
+		BossID   string       `json:"boss_id"`
+		BossName string       `json:"boss_name"`
+		Issues   []FieldError `json:"issues"`
+	}
+	reports := []bossReport{}
+	total := 0
+	for _, b := range season.RaidBosses {
+		if issues := a.gameData.lintBoss(b); len(issues) > 0 {
+			reports = append(reports, bossReport{BossID: b.ID, BossName: b.Name, Issues: issues})
+			total += len(issues)
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"season": code, "total": total, "bosses": reports})
+}
+
+// moveAPIHandler returns a move's stats from moves.json. Query: name, as
+// written in a variation.
+func (a *App) moveAPIHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	m, ok := a.gameData.findMove(r.URL.Query().Get("name"))
+	if !ok {
+		http.Error(w, "move not found", http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(m)
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestCheckNameSuggestions(t *testing.T) {
+	moves := newNameSet([]string{"Water Pulse", "Overheat", "Fiery Dance", "Surf"})
+
+	tests := []struct {
+		value   string
+		unknown int
+		suggest string
+	}{
+		{"Water Pulse", 0, ""},
+		{"water pulse ", 0, ""},
+		{"Overheat/Fiery Dance", 0, ""},
+		{"Water Pulse⭐️", 1, "Water Pulse"},
+		{"Watr Pulse", 1, "Water Pulse"},
+		{"Overheat/Fiery Dnace", 1, "Fiery Dance"},
+		{"Prankster Tailwind", 1, ""},
+	}
+	for _, tt := range tests {
+		errs := checkName(moves, "move", "move", tt.value)
+		if len(errs) != tt.unknown {
+			t.Errorf("%q: got %+v, want %d unknown", tt.value, errs, tt.unknown)
+			continue
+		}
+		if tt.suggest != "" && !slices.Contains(errs[0].Suggestions, tt.suggest) {
+			t.Errorf("%q: suggestions %v, want %q", tt.value, errs[0].Suggestions, tt.suggest)
+		}
+	}
+}
+
+func TestCheckNameSkipsMissingData(t *testing.T) {
+	if errs := checkName(newNameSet(nil), "Pokémon", "pokemon", "Missingno"); errs != nil {
+		t.Fatalf("got %+v, want no checks without data", errs)
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
 	"html"
 	"log"
@@ -24,48 +26,57 @@ import (
 	"go.mongodb.org/mongo-driver/mongo/options"
 	"golang.org/x/crypto/bcrypt"
 	_ "modernc.org/sqlite"
+	"pokemmoraids/typechart"
 )
 
-type Player struct {
-	Pokemon string `json:"pokemon"`
-	Move    string `json:"move"`
-	Item    string `json:"item"`
-}
 type Variation struct {
-	Players         map[string][]Player `json:"players"`
-	HealthRemaining []float64           `json:"health_remaining"`
-	Notes           []string            `json:"notes,omitempty"`
-	PlayersList     [][]Player          `json:"-"`
-	TableHTML       string              `json:"-"`
-	Index           int                 `json:"-"`
-	Index0          int                 `json:"-"`
+	ID              string              `json:"id" bson:"id"`
+	Revision        int                 `json:"revision" bson:"revision"` // bumped on every edit, used for optimistic concurrency
+	Players         map[string][]Player `json:"players" bson:"players"`
+	HealthRemaining []float64           `json:"health_remaining" bson:"health_remaining"`
+	Notes           []string            `json:"notes,omitempty" bson:"notes,omitempty"`
+	CreatedBy       string              `json:"created_by,omitempty" bson:"created_by,omitempty"`
+	CreatedAt       *time.Time          `json:"created_at,omitempty" bson:"created_at,omitempty"`
+	UpdatedBy       string              `json:"updated_by,omitempty" bson:"updated_by,omitempty"` // last editor
+	UpdatedAt       *time.Time          `json:"updated_at,omitempty" bson:"updated_at,omitempty"`
+	Credits         string              `json:"credits,omitempty" bson:"credits,omitempty"` // e.g. the author of the original guide
+	PlayersList     [][]Player          `json:"-" bson:"-"`
+	TableHTML       string              `json:"-" bson:"-"`
+	PhaseTriggers   []PhaseTrigger      `json:"-" bson:"-"` // derived from the boss's phase effects, served by the boss API
+	Index           int                 `json:"-" bson:"-"`
+	Index0          int                 `json:"-" bson:"-"`
+	Votes           VoteTally           `json:"-" bson:"-"` // filled in by rankVariations for the boss page
+	UserVote        int                 `json:"-" bson:"-"`
+	Pinned          bool                `json:"-" bson:"-"`
+	Recommended     bool                `json:"-" bson:"-"`
+	CommentCount    int                 `json:"-" bson:"-"`
 }
 
-type PhaseEffect struct {
-	Health uint8  `json:"health"`
-	Effect string `json:"effect"`
-}
 type RaidBossMove struct {
-	Name string `json:"name"`
-	Type string `json:"type"`
+	Name string `json:"name" bson:"name"`
+	Type string `json:"type" bson:"type"`
 }
 type BaseStats struct {
-	Speed int `json:"speed,omitempty"`
-	Def   int `json:"defense,omitempty"`
-	SpDef int `json:"special_defense,omitempty"`
+	HP    int `json:"hp,omitempty" bson:"hp,omitempty"`
+	Speed int `json:"speed,omitempty" bson:"speed,omitempty"`
+	Def   int `json:"defense,omitempty" bson:"defense,omitempty"`
+	SpDef int `json:"special_defense,omitempty" bson:"special_defense,omitempty"`
 }
 
 type RaidBoss struct {
-	Name         string         `json:"name"`
-	Description  string         `json:"description"`
-	Ability      string         `json:"ability,omitempty"`
-	HeldItem     string         `json:"held_item,omitempty"`
-	Stars        int            `json:"stars,omitempty"`
-	SpeedEVs     int            `json:"speed_evs,omitempty"`
-	BaseStats    BaseStats      `json:"base_stats,omitempty"`
-	Moves        []RaidBossMove `json:"moves,omitempty"`
-	PhaseEffects []PhaseEffect  `json:"phase_effects,omitempty"`
-	Variations   []Variation    `json:"variations"`
+	ID           string         `json:"id" bson:"id"`
+	Revision     int            `json:"revision" bson:"revision"` // bumped on every edit to the boss or its variations
+	Name         string         `json:"name" bson:"name"`
+	Description  string         `json:"description" bson:"description"`
+	Types        []string       `json:"types,omitempty" bson:"types,omitempty"` // defensive types; monster.json is used when empty
+	Ability      string         `json:"ability,omitempty" bson:"ability,omitempty"`
+	HeldItem     string         `json:"held_item,omitempty" bson:"held_item,omitempty"`
+	Stars        int            `json:"stars,omitempty" bson:"stars,omitempty"`
+	SpeedEVs     int            `json:"speed_evs,omitempty" bson:"speed_evs,omitempty"`
+	BaseStats    BaseStats      `json:"base_stats,omitempty" bson:"base_stats"`
+	Moves        []RaidBossMove `json:"moves,omitempty" bson:"moves,omitempty"`
+	PhaseEffects []PhaseEffect  `json:"phase_effects,omitempty" bson:"phase_effects,omitempty"`
+	Variations   []Variation    `json:"variations" bson:"variations"`
 }
 
 type Season struct {
@@ -117,17 +128,25 @@ type PokemonType struct {
 type ChecklistResponse struct {
 	Types  []PokemonType `json:"types"`
 	Season string        `json:"season"`
+	User   string        `json:"user,omitempty"` // set when completion is stored per user on the server
 }
 
 type App struct {
-	seasons       []Season
-	season        Season // current season for backwards compatibility
-	templates     map[string]*pongo2.Template
-	mongoDB       *mongo.Database
-	mongoClient   *mongo.Client
-	adminDB       *sql.DB
-	defaultSeason string // code form e.g. "christmas_2024"
-	commitHash    string // for cache busting static assets
+	store       *SeasonStore   // raid seasons, shared by public pages and admin edits
+	bosses      BossRepository // persistence for raid seasons, selected by BOSS_STORE
+	history     *bossHistory   // immutable revisions of every boss edit
+	submissions *submissionStore
+	parties     *partyStore     // four-player groups planning a variation
+	sessions    *raidSessionHub // live raid sessions, kept in memory
+	attempts    *attemptStore   // logged raid outcomes
+	votes       *voteStore      // variation votes and pins
+	comments    *commentStore   // discussion threads on bosses and variations
+	gameData    *gameData       // known Pokémon, move and item names for validation
+	templates   map[string]*pongo2.Template
+	mongoDB     *mongo.Database
+	mongoClient *mongo.Client
+	adminDB     *sql.DB
+	commitHash  string // for cache busting static assets
 }
 
 var app *App
@@ -237,6 +256,28 @@ func (a *App) openAdminDatabase() error {
 		return fmt.Errorf("failed to ensure users table: %w", err)
 	}
 
+	// players register with an email address they must verify; staff accounts count as verified
+	if err := ensureColumn(a.adminDB, "users", "email", "TEXT"); err != nil {
+		return fmt.Errorf("failed to add users.email: %w", err)
+	}
+	if err := ensureColumn(a.adminDB, "users", "email_verified", "INTEGER NOT NULL DEFAULT 1"); err != nil {
+		return fmt.Errorf("failed to add users.email_verified: %w", err)
+	}
+
+	// create email_verifications table if not exists
+	_, err = a.adminDB.Exec(`
+		CREATE TABLE IF NOT EXISTS email_verifications (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			username TEXT NOT NULL,
+			token TEXT NOT NULL UNIQUE,
+			expires_at INTEGER NOT NULL,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to ensure email_verifications table: %w", err)
+	}
+
 	// create password_resets table if not exists
 	_, err = a.adminDB.Exec(`
 		CREATE TABLE IF NOT EXISTS password_resets (
@@ -289,18 +330,9 @@ func (a *App) openAdminDatabase() error {
 	var defaultCode string
 	row2 := a.adminDB.QueryRow("SELECT value FROM settings WHERE key='default_season'")
 	if err := row2.Scan(&defaultCode); err == nil && defaultCode != "" {
-		a.defaultSeason = defaultCode
 		// apply to current season if found
-		for _, s := range a.seasons {
-			code := strings.ToLower(strings.ReplaceAll(s.SeasonName, " ", "_"))
-			if s.Year > 0 {
-				code = fmt.Sprintf("%s_%d", code, s.Year)
-			}
-			if code == defaultCode {
-				a.season = s
-				a.preprocessVariations()
-				break
-			}
+		if !a.store.SetDefault(defaultCode) {
+			log.Printf("warning: default season %q not found in bosses data", defaultCode)
 		}
 	}
 	return nil
@@ -328,8 +360,8 @@ var (
 	smtpFrom     = os.Getenv("SMTP_FROM")     // e.g., "noreply@pokemmoraids.com" or same as SMTP_USER
 )
 
-// sendResetEmail sends password reset email via SMTP
-func sendResetEmail(toEmail, username, resetURL string) error {
+// sendEmail sends a plain text email via SMTP
+func sendEmail(toEmail, subject, body string) error {
 	if smtpHost == "" || smtpPort == "" || smtpUser == "" || smtpPassword == "" {
 		return fmt.Errorf("SMTP not configured")
 	}
@@ -338,7 +370,22 @@ func sendResetEmail(toEmail, username, resetURL string) error {
 		from = smtpUser
 	}
 
-	// Compose email
+	message := fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: %s\r\n\r\n%s", from, toEmail, subject, body)
+
+	// SMTP authentication
+	auth := smtp.PlainAuth("", smtpUser, smtpPassword, smtpHost)
+
+	// Send email
+	addr := smtpHost + ":" + smtpPort
+	err := smtp.SendMail(addr, auth, from, []string{toEmail}, []byte(message))
+	if err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	return nil
+}
+
+// sendResetEmail sends password reset email via SMTP
+func sendResetEmail(toEmail, username, resetURL string) error {
 	subject := "Password Reset Request - PokeMMO Raid Book"
 	body := fmt.Sprintf(`Hello %s,
 
@@ -353,19 +400,7 @@ If you did not request this reset, please ignore this email.
 
 Best regards,
 PokeMMO Raid Book Team`, username, resetURL)
-
-	message := fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: %s\r\n\r\n%s", from, toEmail, subject, body)
-
-	// SMTP authentication
-	auth := smtp.PlainAuth("", smtpUser, smtpPassword, smtpHost)
-
-	// Send email
-	addr := smtpHost + ":" + smtpPort
-	err := smtp.SendMail(addr, auth, from, []string{toEmail}, []byte(message))
-	if err != nil {
-		return fmt.Errorf("failed to send email: %w", err)
-	}
-	return nil
+	return sendEmail(toEmail, subject, body)
 }
 
 // Admin auth configuration
@@ -484,36 +519,67 @@ func getUsernameFromRequest(r *http.Request) string {
 	return ""
 }
 
-// loadData reads and processes the raid season data from JSON
+// loadData opens the configured boss repository and loads the raid season data
 func (a *App) loadData() error {
-	file, err := os.Open(dataPath)
+	repo, err := a.newBossRepository()
 	if err != nil {
-		return fmt.Errorf("failed to open data file: %w", err)
+		return err
 	}
-	defer file.Close()
+	a.bosses = repo
+	a.history = newBossHistory(a.mongoDB)
+	a.submissions = newSubmissionStore(a.mongoDB)
+	a.parties = newPartyStore(a.mongoDB)
+	a.attempts = newAttemptStore(a.mongoDB)
+	a.votes = newVoteStore(a.mongoDB)
+	a.comments = newCommentStore(a.mongoDB)
+	a.gameData = loadGameData("data")
 
-	// bosses.json is now a list of seasons
-	if err := json.NewDecoder(file).Decode(&a.seasons); err != nil {
-		return fmt.Errorf("failed to decode seasons data: %w", err)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	seasons, err := repo.Seasons(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load seasons data: %w", err)
 	}
-
-	// Set the current season to the first one if available
-	if len(a.seasons) > 0 {
-		a.season = a.seasons[0]
+	if n, err := a.history.Backfill(ctx, seasons); err != nil {
+		log.Printf("warning: failed to record baseline boss revisions: %v", err)
+	} else if n > 0 {
+		log.Printf("Recorded baseline revisions for %d bosses", n)
 	}
 
-	a.preprocessVariations()
+	// The current season defaults to the first one until a default is configured
+	a.store = newSeasonStore(seasons, a.preprocessVariations)
 	return nil
 }
 
-// preprocessVariations builds HTML tables for all variations
-func (a *App) preprocessVariations() {
-	for bi := range a.season.RaidBosses {
-		for vi := range a.season.RaidBosses[bi].Variations {
+// refreshSeasonsLoop periodically reloads seasons from the repository so edits
+// made by other instances sharing the MongoDB backend become visible here.
+func (a *App) refreshSeasonsLoop(interval time.Duration) {
+	for range time.Tick(interval) {
+		err := a.store.Update(func(tx *SeasonTx) error {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			seasons, err := a.bosses.Seasons(ctx)
+			if err != nil {
+				return err
+			}
+			tx.Seasons = seasons
+			return nil
+		})
+		if err != nil {
+			log.Printf("warning: failed to refresh seasons: %v", err)
+		}
+	}
+}
+
+// preprocessVariations builds HTML tables for all variations of a season
+func (a *App) preprocessVariations(season *Season) {
+	for bi := range season.RaidBosses {
+		for vi := range season.RaidBosses[bi].Variations {
 			// set convenient indexes for templates (1-based and 0-based)
-			a.season.RaidBosses[bi].Variations[vi].Index = vi + 1
-			a.season.RaidBosses[bi].Variations[vi].Index0 = vi
-			a.season.RaidBosses[bi].Variations[vi].TableHTML = a.buildVariationTable(&a.season.RaidBosses[bi].Variations[vi])
+			season.RaidBosses[bi].Variations[vi].Index = vi + 1
+			season.RaidBosses[bi].Variations[vi].Index0 = vi
+			season.RaidBosses[bi].Variations[vi].PhaseTriggers = phaseTimeline(season.RaidBosses[bi].PhaseEffects, season.RaidBosses[bi].Variations[vi].HealthRemaining)
+			season.RaidBosses[bi].Variations[vi].TableHTML = a.buildVariationTable(&season.RaidBosses[bi].Variations[vi])
 		}
 	}
 }
@@ -528,7 +594,9 @@ func (a *App) buildVariationTable(v *Variation) string {
 	var sb strings.Builder
 	for ti, health := range v.HealthRemaining {
 		sb.WriteString("<tr>")
-		sb.WriteString(fmt.Sprintf("<td>%d</td>", ti+1))
+		sb.WriteString(fmt.Sprintf("<td>%d", ti+1))
+		a.writePhaseTriggers(&sb, v.PhaseTriggers, ti)
+		sb.WriteString("</td>")
 
 		for playerIdx := 0; playerIdx < maxPlayers; playerIdx++ {
 			a.writePlayerCell(&sb, playerArrays[playerIdx], playerIdx, ti)
@@ -541,6 +609,22 @@ func (a *App) buildVariationTable(v *Variation) string {
 	return sb.String()
 }
 
+// writePhaseTriggers lists the phase effects that fire after a turn; those
+// active from the start are shown on the first turn
+func (a *App) writePhaseTriggers(sb *strings.Builder, triggers []PhaseTrigger, turnIdx int) {
+	for _, t := range triggers {
+		if !t.Reached || max(t.AfterTurn-1, 0) != turnIdx {
+			continue
+		}
+		label := fmt.Sprintf("%d%%", t.Health)
+		if t.AfterTurn == 0 {
+			label = "start"
+		}
+		sb.WriteString(fmt.Sprintf("<div class=\"phase-trigger\" title=\"%s\">⚡ %s: %s</div>",
+			html.EscapeString(t.Message), label, html.EscapeString(t.Effect)))
+	}
+}
+
 // writeNoteCell writes a note input field with prefilled content from Notes
 func (a *App) writeNoteCell(sb *strings.Builder, notes []string, turnIdx int) {
 	noteValue := ""
@@ -563,25 +647,44 @@ func (a *App) writePlayerCell(sb *strings.Builder, players []Player, playerIdx,
 	sb.WriteString("<td class=\"player-cell\">")
 	sb.WriteString("<label class=\"player-action\">")
 	sb.WriteString(fmt.Sprintf("<input type=\"checkbox\" class=\"player-check\" data-player-index=\"%d\" data-turn-index=\"%d\">", playerIdx, turnIdx))
-	sb.WriteString("<div class=\"player-meta\">")
+	sb.WriteString(fmt.Sprintf("<div class=\"player-meta\" data-action=\"%s\" data-move=\"%s\" data-target=\"%s\" data-note=\"%s\">",
+		html.EscapeString(p.Action), html.EscapeString(p.Move), html.EscapeString(p.Target), html.EscapeString(p.Note)))
 	sb.WriteString(fmt.Sprintf("<div class=\"player-name\">%s</div>", html.EscapeString(p.Pokemon)))
-	sb.WriteString(fmt.Sprintf("<div class=\"player-move\">%s</div>", html.EscapeString(p.Move)))
+	switch p.Action {
+	case actionMove:
+		// the move links to its stats from moves.json
+		if m, ok := a.gameData.findMove(p.Move); ok {
+			sb.WriteString(fmt.Sprintf("<div class=\"player-move\" title=\"%s\">%s</div>",
+				html.EscapeString(m.Summary()), html.EscapeString(p.Move)))
+		} else {
+			sb.WriteString(fmt.Sprintf("<div class=\"player-move\">%s</div>", html.EscapeString(p.Move)))
+		}
+	case actionSwitch:
+		sb.WriteString("<div class=\"player-move action-other\">⇄ switch in</div>")
+	case actionItem:
+		sb.WriteString("<div class=\"player-move action-other\">uses item</div>")
+	case actionPass:
+		sb.WriteString("<div class=\"player-move action-other\">pass</div>")
+	}
+	if p.Target != "" && p.Target != "boss" {
+		sb.WriteString(fmt.Sprintf("<div class=\"player-target\">→ %s</div>", html.EscapeString(p.Target)))
+	}
 	if p.Item != "" {
 		sb.WriteString(fmt.Sprintf("<div class=\"player-item\">%s</div>", html.EscapeString(p.Item)))
 	}
+	if p.Note != "" {
+		sb.WriteString(fmt.Sprintf("<div class=\"player-note\">%s</div>", html.EscapeString(p.Note)))
+	}
 	sb.WriteString("</div></label></td>")
 }
 
 func main() {
 	app = &App{
 		templates:  make(map[string]*pongo2.Template),
+		sessions:   newRaidSessionHub(),
 		commitHash: getEnvOrDefault("GIT_COMMIT_HASH", "dev"),
 	}
 
-	if err := app.loadData(); err != nil {
-		log.Fatalf("Failed to load data: %v", err)
-	}
-
 	if err := app.openMongoDB(); err != nil {
 		log.Fatalf("Failed to open MongoDB: %v", err)
 	}
@@ -595,6 +698,25 @@ func main() {
 		}
 	}()
 
+	// one-shot commands run instead of the server
+	if len(os.Args) > 1 {
+		if err := app.runCommand(os.Args[1], os.Args[2:]); err != nil {
+			log.Fatalf("%s: %v", os.Args[1], err)
+		}
+		return
+	}
+
+	if err := app.loadData(); err != nil {
+		log.Fatalf("Failed to load data: %v", err)
+	}
+	if bossStore == "mongo" {
+		interval, err := time.ParseDuration(getEnvOrDefault("BOSS_REFRESH_INTERVAL", "30s"))
+		if err != nil || interval <= 0 {
+			log.Fatalf("Invalid BOSS_REFRESH_INTERVAL: %q", os.Getenv("BOSS_REFRESH_INTERVAL"))
+		}
+		go app.refreshSeasonsLoop(interval)
+	}
+
 	if err := app.openAdminDatabase(); err != nil {
 		log.Fatalf("Failed to open admin database: %v", err)
 	}
@@ -610,6 +732,22 @@ func main() {
 	}
 }
 
+// runCommand executes a one-shot maintenance command such as migrate-bosses
+func (a *App) runCommand(name string, args []string) error {
+	switch name {
+	case "migrate-bosses":
+		fs := flag.NewFlagSet(name, flag.ExitOnError)
+		overwrite := fs.Bool("overwrite", false, "replace seasons that already exist in MongoDB")
+		fs.Parse(args)
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+		return migrateBossesToMongo(ctx, a.mongoDB, dataPath, *overwrite)
+	default:
+		return fmt.Errorf("unknown command (available: migrate-bosses)")
+	}
+}
+
 // setupRoutes configures HTTP handlers
 func setupRoutes() {
 	http.Handle("/static/", http.StripPrefix("/static/", http.FileServer(http.Dir("static"))))
@@ -620,6 +758,24 @@ func setupRoutes() {
 	http.HandleFunc("/api/pokemon-data", app.pokemonDataHandler)
 	http.HandleFunc("/api/pokemon-info", app.pokemonInfoHandler)
 	http.HandleFunc("/api/boss-edit-data", app.bossEditDataHandler)
+	http.HandleFunc("/api/calc/speed", app.speedCalcHandler)
+	http.HandleFunc("/api/calc/damage", app.damageCalcHandler)
+	http.HandleFunc("/api/types", app.typeChartHandler)
+	http.HandleFunc("/api/types/boss", app.bossMatchupsHandler)
+	http.HandleFunc("/api/simulate", app.simulationHandler)
+	http.HandleFunc("/api/roster", app.rosterHandler)
+	http.HandleFunc("/party", app.partyPageHandler)
+	http.HandleFunc("/api/party", app.partyHandler)
+	http.HandleFunc("/api/party/action", app.partyActionHandler)
+	http.HandleFunc("/api/raid-session", app.raidSessionHandler)
+	http.HandleFunc("/api/raid-session/update", app.raidSessionUpdateHandler)
+	http.HandleFunc("/api/raid-session/events", app.raidSessionEventsHandler)
+	http.HandleFunc("/api/attempts", app.attemptsHandler)
+	http.HandleFunc("/api/attempts/stats", app.attemptStatsHandler)
+	http.HandleFunc("/api/votes", app.votesHandler)
+	http.HandleFunc("/api/comments", app.commentsHandler)
+	http.HandleFunc("/api/boss", app.bossAPIHandler)
+	http.HandleFunc("/api/move", app.moveAPIHandler)
 	http.HandleFunc("/api/checklist", app.checklistHandler)
 	http.HandleFunc("/api/checklist/toggle", app.toggleChecklistHandler)
 	http.HandleFunc("/api/checklist/save", app.saveChecklistHandler)
@@ -637,19 +793,32 @@ func setupRoutes() {
 	// password reset endpoints
 	http.HandleFunc("/auth/reset/request", app.authResetRequestHandler)
 	http.HandleFunc("/auth/reset", app.authResetHandler)
+	http.HandleFunc("/auth/register", app.authRegisterHandler)
+	http.HandleFunc("/auth/verify", app.authVerifyHandler)
+	http.HandleFunc("/auth/verify/resend", app.authVerifyResendHandler)
 	http.HandleFunc("/api/boss/save-variation", app.saveVariationHandler)
+	http.HandleFunc("/api/submissions", app.submissionsHandler)
+	http.HandleFunc("/api/admin/submissions", app.adminSubmissionsHandler)
+	http.HandleFunc("/api/admin/submissions/review", app.adminSubmissionReviewHandler)
 	http.HandleFunc("/api/admin/types", app.adminTypesHandler)
 	http.HandleFunc("/api/admin/pokemon", app.adminPokemonHandler)
 	http.HandleFunc("/api/admin/extras", app.adminExtrasHandler)
 	http.HandleFunc("/api/admin/raid-bosses", app.adminRaidBossesHandler)
+	http.HandleFunc("/api/admin/lint", app.adminLintHandler)
+	http.HandleFunc("/api/admin/boss-history", app.adminBossHistoryHandler)
+	http.HandleFunc("/api/admin/boss-history/diff", app.adminBossHistoryDiffHandler)
+	http.HandleFunc("/api/admin/boss-history/restore", app.adminBossRestoreHandler)
 	http.HandleFunc("/api/admin/seasons", app.adminSeasonsHandler)
 	http.HandleFunc("/api/admin/season/default", app.adminDefaultSeasonHandler)
 	http.HandleFunc("/api/admin/type-settings", app.adminTypeSettingsHandler)
+	http.HandleFunc("/api/admin/variation-pin", app.adminVariationPinHandler)
+	http.HandleFunc("/api/admin/comments/moderate", app.adminCommentModerateHandler)
+	http.HandleFunc("/api/admin/variations", app.adminVariationsHandler)
 }
 
 // loadTemplates loads all template files
 func (a *App) loadTemplates() error {
-	templateNames := []string{"index.html", "boss.html", "build_team.html", "base.html", "admin.html", "admin_login.html", "auth_login.html", "auth_reset.html", "auth_reset_sent.html", "auth_change_password.html", "admin_build_team.html"}
+	templateNames := []string{"index.html", "boss.html", "build_team.html", "base.html", "admin.html", "admin_login.html", "auth_login.html", "auth_reset.html", "auth_reset_sent.html", "auth_change_password.html", "auth_register.html", "party.html", "admin_build_team.html"}
 	for _, name := range templateNames {
 		tpl, err := pongo2.FromFile(templatesPath + name)
 		if err != nil {
@@ -663,37 +832,48 @@ func (a *App) loadTemplates() error {
 // indexHandler renders the main page with all bosses
 func (a *App) indexHandler(w http.ResponseWriter, r *http.Request) {
 	role := getRoleFromRequest(r)
-	renderTemplate(w, a.templates["index.html"], pongo2.Context{"season": a.season, "user_role": role, "commit_hash": a.commitHash})
+	renderTemplate(w, a.templates["index.html"], pongo2.Context{"season": a.store.Current(), "user_role": role, "commit_hash": a.commitHash})
 }
 
 // bossHandler renders a specific boss page
 func (a *App) bossHandler(w http.ResponseWriter, r *http.Request) {
 	bossName := r.URL.Query().Get("name")
-	boss := a.findBoss(bossName)
-	if boss == nil {
+	boss, ok := a.store.FindBoss(bossName)
+	if !ok {
 		http.NotFound(w, r)
 		return
 	}
 
-	bossJSON, err := json.Marshal(boss)
+	bossJSON, err := json.Marshal(newBossView(boss))
 	if err != nil {
 		renderError(w, "Failed to marshal boss data", http.StatusInternalServerError)
 		return
 	}
 
+	// rank after marshalling so the editors keep indexing variations in bosses.json order
+	rctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	a.rankBoss(rctx, &boss, getUsernameFromRequest(r))
+	bossComments := a.commentCounts(rctx, &boss)
+
 	role := getRoleFromRequest(r)
 	ctx := pongo2.Context{
-		"boss":      boss,
-		"bossJSON":  string(bossJSON),
-		"user_role": role,
+		"boss":              boss,
+		"bossJSON":          string(bossJSON),
+		"boss_comments":     bossComments,
+		"user_role":         role,
+		"allow_suggestions": anonSubmissions,
+		"matchups":          a.matchupsForBoss(boss),
+		"raid_level":        raidLevel,
+		"stat_stages":       []int{-6, -5, -4, -3, -2, -1, 0, 1, 2, 3, 4, 5, 6},
 	}
 	renderTemplate(w, a.templates["boss.html"], ctx)
 }
 
 // buildTeamHandler renders the team builder page
 func (a *App) buildTeamHandler(w http.ResponseWriter, r *http.Request) {
-	// Require authentication (author/mod/admin)
-	if !isAuthRequest(r) {
+	// Require a signed-in user; players and authors submit their variations for review
+	if getUsernameFromRequest(r) == "" {
 		http.Redirect(w, r, "/auth/login", http.StatusSeeOther)
 		return
 	}
@@ -702,12 +882,13 @@ func (a *App) buildTeamHandler(w http.ResponseWriter, r *http.Request) {
 	// If no boss is selected, render selection form
 	if bossName == "" {
 		// Build list of bosses for current season
-		bossNames := make([]string, 0, len(a.season.RaidBosses))
-		for _, b := range a.season.RaidBosses {
+		season := a.store.Current()
+		bossNames := make([]string, 0, len(season.RaidBosses))
+		for _, b := range season.RaidBosses {
 			bossNames = append(bossNames, b.Name)
 		}
 		ctx := pongo2.Context{
-			"season_name": a.season.SeasonName,
+			"season_name": season.SeasonName,
 			"bosses":      bossNames,
 			"user_role":   getRoleFromRequest(r),
 		}
@@ -715,8 +896,8 @@ func (a *App) buildTeamHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	boss := a.findBoss(bossName)
-	if boss == nil {
+	boss, ok := a.store.FindBoss(bossName)
+	if !ok {
 		http.NotFound(w, r)
 		return
 	}
@@ -786,7 +967,7 @@ func (a *App) pokemonDataHandler(w http.ResponseWriter, r *http.Request) {
 	moveSet := make(map[string]bool)
 	itemSet := make(map[string]bool)
 
-	for _, boss := range a.season.RaidBosses {
+	for _, boss := range a.store.Current().RaidBosses {
 		for _, variation := range boss.Variations {
 			for _, players := range variation.Players {
 				for _, p := range players {
@@ -898,11 +1079,7 @@ func (a *App) pokemonInfoHandler(w http.ResponseWriter, r *http.Request) {
 
 // getSeasonName returns the season name for MongoDB queries
 func (a *App) getSeasonName() string {
-	seasonName := strings.ToLower(strings.ReplaceAll(a.season.SeasonName, " ", "_"))
-	if a.season.Year > 0 {
-		return fmt.Sprintf("%s_%d", seasonName, a.season.Year)
-	}
-	return seasonName
+	return seasonCode(a.store.Current())
 }
 
 // seasonCode returns the canonical code for a given season
@@ -931,26 +1108,6 @@ func slugifyName(name string) string {
 	return name
 }
 
-// findSeasonIndexByCode finds season by code and returns index and true if found
-func (a *App) findSeasonIndexByCode(code string) (int, bool) {
-	for i, s := range a.seasons {
-		if seasonCode(s) == code {
-			return i, true
-		}
-	}
-	return -1, false
-}
-
-// findBoss searches for a boss by name
-func (a *App) findBoss(name string) *RaidBoss {
-	for i := range a.season.RaidBosses {
-		if a.season.RaidBosses[i].Name == name {
-			return &a.season.RaidBosses[i]
-		}
-	}
-	return nil
-}
-
 // bossEditDataHandler returns monster.json and held_items.json for in-place editing
 func (a *App) bossEditDataHandler(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
@@ -989,15 +1146,10 @@ func (a *App) checklistHandler(w http.ResponseWriter, r *http.Request) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	// Get checklist for current season and default user
+	// Get the signed-in user's checklist for the current season
 	season := a.getSeasonName()
-	collection := a.mongoDB.Collection("checklists")
-
-	var doc ChecklistDocument
-	err := collection.FindOne(ctx, bson.M{
-		"season":  season,
-		"user_id": "default",
-	}).Decode(&doc)
+	username := getUsernameFromRequest(r)
+	doc, err := a.userChecklist(ctx, season, username)
 
 	if err == mongo.ErrNoDocuments {
 		// Return empty checklist if not found
@@ -1063,20 +1215,21 @@ func (a *App) checklistHandler(w http.ResponseWriter, r *http.Request) {
 		return types[i].TypeName < types[j].TypeName
 	})
 
-	response := ChecklistResponse{Types: types, Season: season}
+	response := ChecklistResponse{Types: types, Season: season, User: username}
 	json.NewEncoder(w).Encode(response)
 }
 
-// toggleChecklistHandler toggles the completion status of a pokemon
+// toggleChecklistHandler toggles the completion status of a pokemon in the
+// signed-in user's checklist
 func (a *App) toggleChecklistHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
-	// Only authenticated users (mod/author/admin) can persist to server
-	role := getRoleFromRequest(r)
-	if role == "" {
+	// Only signed-in users have a checklist on the server
+	username := getUsernameFromRequest(r)
+	if username == "" {
 		http.Error(w, "unauthorized", http.StatusUnauthorized)
 		return
 	}
@@ -1097,15 +1250,7 @@ func (a *App) toggleChecklistHandler(w http.ResponseWriter, r *http.Request) {
 	defer cancel()
 
 	season := a.getSeasonName()
-	collection := a.mongoDB.Collection("checklists")
-
-	// Find the pokemon and toggle completion
-	var doc ChecklistDocument
-	err := collection.FindOne(ctx, bson.M{
-		"season":  season,
-		"user_id": "default",
-	}).Decode(&doc)
-
+	doc, err := a.userChecklist(ctx, season, username)
 	if err != nil {
 		log.Printf("Error finding checklist: %v", err)
 		http.Error(w, "Checklist not found", http.StatusNotFound)
@@ -1113,43 +1258,30 @@ func (a *App) toggleChecklistHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Find and toggle the pokemon
-	found := false
+	idx := -1
 	for i := range doc.Pokemon {
 		if doc.Pokemon[i].Name == req.Name && doc.Pokemon[i].Usage == req.Usage {
 			doc.Pokemon[i].Completed = !doc.Pokemon[i].Completed
-			found = true
+			idx = i
 			break
 		}
 	}
 
-	if !found {
+	if idx < 0 {
 		http.Error(w, "Pokemon not found", http.StatusNotFound)
 		return
 	}
 
-	// Update the document
+	// Update the document, seeding it on the user's first toggle
 	doc.UpdatedAt = time.Now()
-	_, err = collection.ReplaceOne(ctx,
-		bson.M{
-			"season":  season,
-			"user_id": "default",
-		},
-		doc,
-	)
-
-	if err != nil {
+	if err := a.saveUserChecklist(ctx, doc); err != nil {
 		log.Printf("Error updating checklist: %v", err)
 		http.Error(w, "Failed to update checklist", http.StatusInternalServerError)
 		return
 	}
 
 	// Return the new completion status
-	for i := range doc.Pokemon {
-		if doc.Pokemon[i].Name == req.Name && doc.Pokemon[i].Usage == req.Usage {
-			json.NewEncoder(w).Encode(map[string]bool{"completed": doc.Pokemon[i].Completed})
-			return
-		}
-	}
+	json.NewEncoder(w).Encode(map[string]bool{"completed": doc.Pokemon[idx].Completed})
 }
 
 // saveChecklistHandler saves checklist pokemon edits (admin, mod, or author)
@@ -1200,7 +1332,7 @@ func (a *App) saveChecklistHandler(w http.ResponseWriter, r *http.Request) {
 	var doc ChecklistDocument
 	err := collection.FindOne(ctx, bson.M{
 		"season":  season,
-		"user_id": "default",
+		"user_id": checklistTemplateUser,
 	}).Decode(&doc)
 
 	if err != nil {
@@ -1230,7 +1362,7 @@ func (a *App) saveChecklistHandler(w http.ResponseWriter, r *http.Request) {
 	_, err = collection.ReplaceOne(ctx,
 		bson.M{
 			"season":  season,
-			"user_id": "default",
+			"user_id": checklistTemplateUser,
 		},
 		doc,
 	)
@@ -1350,22 +1482,26 @@ func (a *App) adminPageHandler(w http.ResponseWriter, r *http.Request) {
 	// pass all seasons with code and label for admin sidebar
 	type seasonVM struct{ Code, Label string }
 	var seasons []seasonVM
-	for _, s := range a.seasons {
+	for _, s := range a.store.Seasons() {
 		seasons = append(seasons, seasonVM{Code: seasonCode(s), Label: seasonLabel(s)})
 	}
 	renderTemplate(w, tpl, pongo2.Context{"seasons": seasons, "user_role": role, "commit_hash": a.commitHash})
 }
 
-// adminRaidBossBuildHandler renders the raid boss builder page (similar to build_team.html but admin-only)
+// adminRaidBossBuildHandler renders the raid boss builder page (similar to build_team.html but for admins and mods)
 func (a *App) adminRaidBossBuildHandler(w http.ResponseWriter, r *http.Request) {
 	if !isAuthRequest(r) {
 		http.Redirect(w, r, "/admin/login", http.StatusSeeOther)
 		return
 	}
+	if role := getRoleFromRequest(r); role != "admin" && role != "mod" {
+		http.Error(w, "forbidden", http.StatusForbidden)
+		return
+	}
 
 	action := r.URL.Query().Get("action")
 	season := r.URL.Query().Get("season")
-	idStr := r.URL.Query().Get("id")
+	id := r.URL.Query().Get("id")
 
 	if action == "" || season == "" {
 		http.Error(w, "action and season required", http.StatusBadRequest)
@@ -1387,7 +1523,9 @@ func (a *App) adminRaidBossBuildHandler(w http.ResponseWriter, r *http.Request)
 		"description":        "",
 		"ability":            "",
 		"held_item":          "",
+		"types":              "",
 		"speed_evs":          0,
+		"base_stats_hp":      0,
 		"base_stats_speed":   0,
 		"base_stats_defense": 0,
 		"base_stats_spdef":   0,
@@ -1398,45 +1536,55 @@ func (a *App) adminRaidBossBuildHandler(w http.ResponseWriter, r *http.Request)
 		"raid_boss_data":     "{}",
 	}
 
-	if action == "edit" && idStr != "" {
-		id, _ := strconv.Atoi(idStr)
-		if id >= 0 && id < len(a.season.RaidBosses) {
-			boss := a.season.RaidBosses[id]
-			movesJSON, _ := json.Marshal(boss.Moves)
-			phasesJSON, _ := json.Marshal(boss.PhaseEffects)
-			variationsJSON, _ := json.Marshal(boss.Variations)
-
-			// Marshal the full boss object for the raid-boss-data JSON blob
-			bossDataJSON, _ := json.Marshal(map[string]interface{}{
-				"id":            id,
-				"name":          boss.Name,
-				"stars":         boss.Stars,
-				"description":   boss.Description,
-				"ability":       boss.Ability,
-				"held_item":     boss.HeldItem,
-				"speed_evs":     boss.SpeedEVs,
-				"base_stats":    boss.BaseStats,
-				"moves":         boss.Moves,
-				"phase_effects": boss.PhaseEffects,
-				"variations":    boss.Variations,
-			})
-
-			context["boss_id"] = id
-			context["boss_name"] = boss.Name
-			context["stars"] = boss.Stars
-			context["description"] = boss.Description
-			context["ability"] = boss.Ability
-			context["held_item"] = boss.HeldItem
-			context["speed_evs"] = boss.SpeedEVs
-			context["base_stats_speed"] = boss.BaseStats.Speed
-			context["base_stats_defense"] = boss.BaseStats.Def
-			context["base_stats_spdef"] = boss.BaseStats.SpDef
-			context["moves"] = string(movesJSON)
-			context["phase_effects"] = string(phasesJSON)
-			context["variations"] = string(variationsJSON)
-			context["raid_boss_data"] = string(bossDataJSON)
-			context["mode_label"] = fmt.Sprintf("Editing: %s", boss.Name)
+	if action == "edit" {
+		if !validID(id) {
+			http.Error(w, "valid boss id required", http.StatusBadRequest)
+			return
 		}
+		target, _ := a.store.FindSeason(season)
+		boss := findBossByID(&target, id)
+		if boss == nil {
+			http.Error(w, "boss not found", http.StatusNotFound)
+			return
+		}
+		movesJSON, _ := json.Marshal(boss.Moves)
+		phasesJSON, _ := json.Marshal(boss.PhaseEffects)
+		variationsJSON, _ := json.Marshal(boss.Variations)
+
+		// Marshal the full boss object for the raid-boss-data JSON blob
+		bossDataJSON, _ := json.Marshal(map[string]interface{}{
+			"id":            id,
+			"revision":      boss.Revision,
+			"name":          boss.Name,
+			"stars":         boss.Stars,
+			"description":   boss.Description,
+			"ability":       boss.Ability,
+			"held_item":     boss.HeldItem,
+			"types":         boss.Types,
+			"speed_evs":     boss.SpeedEVs,
+			"base_stats":    boss.BaseStats,
+			"moves":         boss.Moves,
+			"phase_effects": boss.PhaseEffects,
+			"variations":    boss.Variations,
+		})
+
+		context["boss_id"] = id
+		context["boss_name"] = boss.Name
+		context["stars"] = boss.Stars
+		context["description"] = boss.Description
+		context["ability"] = boss.Ability
+		context["held_item"] = boss.HeldItem
+		context["types"] = strings.Join(boss.Types, ", ")
+		context["speed_evs"] = boss.SpeedEVs
+		context["base_stats_hp"] = boss.BaseStats.HP
+		context["base_stats_speed"] = boss.BaseStats.Speed
+		context["base_stats_defense"] = boss.BaseStats.Def
+		context["base_stats_spdef"] = boss.BaseStats.SpDef
+		context["moves"] = string(movesJSON)
+		context["phase_effects"] = string(phasesJSON)
+		context["variations"] = string(variationsJSON)
+		context["raid_boss_data"] = string(bossDataJSON)
+		context["mode_label"] = fmt.Sprintf("Editing: %s", boss.Name)
 	}
 
 	renderTemplate(w, tpl, context)
@@ -1445,7 +1593,7 @@ func (a *App) adminRaidBossBuildHandler(w http.ResponseWriter, r *http.Request)
 // adminUsersHandler provides CRUD API for admin users (requires admin)
 func (a *App) adminUsersHandler(w http.ResponseWriter, r *http.Request) {
 	role := getRoleFromRequest(r)
-	if role == "" {
+	if !isStaffRole(role) {
 		http.Error(w, "unauthorized", http.StatusUnauthorized)
 		return
 	}
@@ -1550,7 +1698,7 @@ func (a *App) adminUsersHandler(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-// authLoginHandler handles login for authors/mods (and admins if needed)
+// authLoginHandler handles login for players, authors and mods (and admins if needed)
 func (a *App) authLoginHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method == http.MethodGet {
 		tpl, err := pongo2.FromFile(templatesPath + "auth_login.html")
@@ -1569,8 +1717,9 @@ func (a *App) authLoginHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	var hash, role string
-	row := a.adminDB.QueryRow("SELECT password_hash, role FROM users WHERE username = ?", username)
-	if err := row.Scan(&hash, &role); err != nil {
+	var verified bool
+	row := a.adminDB.QueryRow("SELECT password_hash, role, email_verified FROM users WHERE username = ?", username)
+	if err := row.Scan(&hash, &role, &verified); err != nil {
 		http.Error(w, "unauthorized", http.StatusUnauthorized)
 		return
 	}
@@ -1578,8 +1727,13 @@ func (a *App) authLoginHandler(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "unauthorized", http.StatusUnauthorized)
 		return
 	}
-	// allow roles author/mod/admin
-	if role != "author" && role != "mod" && role != "admin" {
+	// allow roles author/mod/admin, and players once their email is verified
+	if role == playerRole {
+		if !verified {
+			http.Error(w, "email not verified, use the link we emailed you", http.StatusForbidden)
+			return
+		}
+	} else if !isStaffRole(role) {
 		http.Error(w, "forbidden", http.StatusForbidden)
 		return
 	}
@@ -1623,12 +1777,7 @@ func (a *App) authResetRequestHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	// build reset URL
-	host := r.Host
-	scheme := "https"
-	if strings.HasPrefix(host, "localhost") || strings.HasPrefix(host, "127.0.0.1") {
-		scheme = "http"
-	}
-	resetURL := fmt.Sprintf("%s://%s/auth/reset?token=%s", scheme, host, token)
+	resetURL := fmt.Sprintf("%s/auth/reset?token=%s", siteURL(r), token)
 	// log.Printf("Password reset link for %s → %s (email to: %s)", username, resetURL, email)
 
 	// Send email
@@ -1654,16 +1803,12 @@ func (a *App) authResetRequestHandler(w http.ResponseWriter, r *http.Request) {
 
 // authChangePasswordHandler allows a logged-in user to change password without email
 func (a *App) authChangePasswordHandler(w http.ResponseWriter, r *http.Request) {
-	if !isAuthRequest(r) {
-		http.Redirect(w, r, "/auth/login", http.StatusSeeOther)
-		return
-	}
 	username := getUsernameFromRequest(r)
-	role := getRoleFromRequest(r)
 	if username == "" {
-		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		http.Redirect(w, r, "/auth/login", http.StatusSeeOther)
 		return
 	}
+	role := getRoleFromRequest(r)
 
 	switch r.Method {
 	case http.MethodGet:
@@ -1825,76 +1970,141 @@ func (a *App) saveVariationHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Only authenticated users can save
+	// anonymous visitors may only submit for review, and only when enabled
 	role := getRoleFromRequest(r)
-	if role == "" {
+	if role == "" && !anonSubmissions {
 		http.Error(w, "unauthorized", http.StatusUnauthorized)
 		return
 	}
 
 	var req struct {
-		BossName        string              `json:"boss_name"`
-		VariationIndex  int                 `json:"variation_index"`
+		BossID          string              `json:"boss_id"`
+		VariationID     string              `json:"variation_id"` // empty creates a new variation
+		VariationIndex  *int                `json:"variation_index"`
+		Revision        *int                `json:"revision"`      // required when updating, unless If-Match is sent
+		SubmissionID    string              `json:"submission_id"` // revises an earlier submission of the same author
 		Players         map[string][]Player `json:"players"`
 		HealthRemaining []float64           `json:"health_remaining"`
 		Notes           []string            `json:"notes"`
+		Credits         *string             `json:"credits"` // omitted keeps the current credits
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		http.Error(w, "invalid request body", http.StatusBadRequest)
 		return
 	}
-
-	// Find the boss
-	boss := a.findBoss(req.BossName)
-	if boss == nil {
-		http.Error(w, "boss not found", http.StatusNotFound)
+	if req.VariationIndex != nil {
+		http.Error(w, "variation_index is no longer supported, use variation_id", http.StatusBadRequest)
+		return
+	}
+	if !validID(req.BossID) || (req.VariationID != "" && !validID(req.VariationID)) {
+		http.Error(w, "valid boss_id and variation_id required", http.StatusBadRequest)
 		return
 	}
 
-	// Check if this is an update or a new variation
-	if req.VariationIndex >= 0 && req.VariationIndex < len(boss.Variations) {
-		// Update existing variation at the specified index - replace entire variation
-		updatedVariation := Variation{
-			Index:           boss.Variations[req.VariationIndex].Index,
-			Index0:          req.VariationIndex,
-			Players:         req.Players,
-			HealthRemaining: req.HealthRemaining,
-			Notes:           req.Notes,
+	season := a.getSeasonName()
+	variation := Variation{
+		ID:              req.VariationID,
+		Players:         req.Players,
+		HealthRemaining: req.HealthRemaining,
+		Notes:           req.Notes,
+	}
+	if req.Credits != nil {
+		variation.Credits = strings.TrimSpace(*req.Credits)
+	} else if current, ok := a.store.FindSeason(season); ok && variation.ID != "" {
+		if boss := findBossByID(&current, req.BossID); boss != nil {
+			if i := findVariationIndex(boss, variation.ID); i >= 0 {
+				variation.Credits = boss.Variations[i].Credits
+			}
 		}
-		updatedVariation.TableHTML = a.buildVariationTable(&updatedVariation)
-		boss.Variations[req.VariationIndex] = updatedVariation
+	}
+	if errs := validateVariation(variation, ""); len(errs) > 0 {
+		writeValidationErrors(w, errs)
+		return
+	}
+	a.gameData.linkVariation(&variation)
+	warnings, ok := checkGameData(w, a.gameData.lintVariation(variation, ""))
+	if !ok {
+		return
+	}
+	creating := variation.ID == ""
+	expected := 0
+	if creating {
+		variation.ID = newID()
+		variation.Revision = 1
 	} else {
-		// Create new variation only if index is not provided or invalid
-		newVariation := Variation{
-			Index:           len(boss.Variations) + 1,
-			Index0:          len(boss.Variations),
-			Players:         req.Players,
-			HealthRemaining: req.HealthRemaining,
-			Notes:           req.Notes,
+		var err error
+		if expected, err = expectedRevision(r, req.Revision); err != nil {
+			writeRevisionError(w, err)
+			return
 		}
-
-		// Build the HTML table for this variation
-		newVariation.TableHTML = a.buildVariationTable(&newVariation)
-
-		// Append to boss variations
-		boss.Variations = append(boss.Variations, newVariation)
+		variation.Revision = expected + 1
+	}
+	// authors, players and anonymous visitors propose changes for review instead of editing the live boss
+	if role != "admin" && role != "mod" {
+		a.submitVariation(w, r, season, req.BossID, req.SubmissionID, variation, creating, expected)
+		return
 	}
 
-	// Save to bosses.json
-	if err := a.saveBossesJSON(); err != nil {
-		http.Error(w, "failed to save changes", http.StatusInternalServerError)
+	stampVariation(&variation, getUsernameFromRequest(r), time.Now(), creating)
+	if err := a.applyVariation(r, season, req.BossID, variation, creating, expected); err != nil {
+		writeVariationError(w, err)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]string{"status": "success"})
+	setETag(w, variation.Revision)
+	json.NewEncoder(w).Encode(map[string]interface{}{"status": "success", "id": variation.ID, "revision": variation.Revision, "warnings": warnings})
+}
+
+// applyVariation creates or updates a variation on the live boss and records the revision.
+// For updates variation.Revision must already be expected+1.
+func (a *App) applyVariation(r *http.Request, season, bossID string, variation Variation, creating bool, expected int) error {
+	var snapshot RaidBoss
+	err := a.store.Update(func(tx *SeasonTx) error {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if creating {
+			if err := createVariationIn(tx.Seasons, season, bossID, variation); err != nil {
+				return err
+			}
+			snapshot = bossSnapshot(tx.Seasons, season, bossID)
+			return a.bosses.CreateVariation(ctx, season, bossID, variation)
+		}
+		if err := updateVariationIn(tx.Seasons, season, bossID, &variation, expected); err != nil {
+			return err
+		}
+		snapshot = bossSnapshot(tx.Seasons, season, bossID)
+		return a.bosses.UpdateVariation(ctx, season, bossID, variation, expected)
+	})
+	if err != nil {
+		return err
+	}
+	action := actionUpdateVariation
+	if creating {
+		action = actionCreateVariation
+	}
+	a.recordBossRevision(r, season, action, variation.ID, snapshot)
+	return nil
+}
+
+// writeVariationError maps an applyVariation error to a response
+func writeVariationError(w http.ResponseWriter, err error) {
+	var conflict *revisionConflictError
+	if errors.As(err, &conflict) {
+		writeRevisionConflict(w, conflict)
+	} else if errors.Is(err, errSeasonNotFound) || errors.Is(err, errBossNotFound) || errors.Is(err, errVariationNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+	} else {
+		log.Printf("Error saving variation: %v", err)
+		http.Error(w, "failed to save changes", http.StatusInternalServerError)
+	}
 }
 
 // adminTypesHandler returns all unique types from the checklist Pokemon for a season
 func (a *App) adminTypesHandler(w http.ResponseWriter, r *http.Request) {
 	role := getRoleFromRequest(r)
-	if role == "" {
+	if !isStaffRole(role) {
 		http.Error(w, "unauthorized", http.StatusUnauthorized)
 		return
 	}
@@ -1913,7 +2123,7 @@ func (a *App) adminTypesHandler(w http.ResponseWriter, r *http.Request) {
 	var doc ChecklistDocument
 	err := collection.FindOne(ctx, bson.M{
 		"season":  season,
-		"user_id": "default",
+		"user_id": checklistTemplateUser,
 	}).Decode(&doc)
 
 	if err == mongo.ErrNoDocuments {
@@ -1955,7 +2165,7 @@ func (a *App) adminTypesHandler(w http.ResponseWriter, r *http.Request) {
 // adminPokemonHandler handles CRUD operations for checklist Pokemon
 func (a *App) adminPokemonHandler(w http.ResponseWriter, r *http.Request) {
 	role := getRoleFromRequest(r)
-	if role == "" {
+	if !isStaffRole(role) {
 		http.Error(w, "unauthorized", http.StatusUnauthorized)
 		return
 	}
@@ -1967,18 +2177,14 @@ func (a *App) adminPokemonHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	// find target season by code
-	var target *Season
-	for i := range a.seasons {
-		code := strings.ToLower(strings.ReplaceAll(a.seasons[i].SeasonName, " ", "_"))
-		if a.seasons[i].Year > 0 {
-			code = fmt.Sprintf("%s_%d", code, a.seasons[i].Year)
-		}
-		if strings.EqualFold(season, code) {
-			target = &a.seasons[i]
+	found := false
+	for _, s := range a.store.Seasons() {
+		if strings.EqualFold(season, seasonCode(s)) {
+			found = true
 			break
 		}
 	}
-	if target == nil {
+	if !found {
 		http.Error(w, "season not found", http.StatusNotFound)
 		return
 	}
@@ -1994,7 +2200,7 @@ func (a *App) adminPokemonHandler(w http.ResponseWriter, r *http.Request) {
 		var doc ChecklistDocument
 		err := collection.FindOne(ctx, bson.M{
 			"season":  season,
-			"user_id": "default",
+			"user_id": checklistTemplateUser,
 		}).Decode(&doc)
 
 		if err == mongo.ErrNoDocuments {
@@ -2023,7 +2229,7 @@ func (a *App) adminPokemonHandler(w http.ResponseWriter, r *http.Request) {
 		// Add to Pokemon array
 		_, err := collection.UpdateOne(
 			ctx,
-			bson.M{"season": season, "user_id": "default"},
+			bson.M{"season": season, "user_id": checklistTemplateUser},
 			bson.M{"$push": bson.M{"pokemon": newPokemon}},
 			options.Update().SetUpsert(true),
 		)
@@ -2059,7 +2265,7 @@ func (a *App) adminPokemonHandler(w http.ResponseWriter, r *http.Request) {
 			ctx,
 			bson.M{
 				"season":        season,
-				"user_id":       "default",
+				"user_id":       checklistTemplateUser,
 				"pokemon.name":  updateData.OldName,
 				"pokemon.usage": updateData.OldUsage,
 			},
@@ -2099,7 +2305,7 @@ func (a *App) adminPokemonHandler(w http.ResponseWriter, r *http.Request) {
 		// Remove from Pokemon array
 		_, err := collection.UpdateOne(
 			ctx,
-			bson.M{"season": season, "user_id": "default"},
+			bson.M{"season": season, "user_id": checklistTemplateUser},
 			bson.M{"$pull": bson.M{"pokemon": bson.M{"name": pokemonName, "usage": pokemonUsage}}},
 		)
 
@@ -2119,7 +2325,7 @@ func (a *App) adminPokemonHandler(w http.ResponseWriter, r *http.Request) {
 // adminExtrasHandler returns monster.json and held_items.json for dropdowns
 func (a *App) adminExtrasHandler(w http.ResponseWriter, r *http.Request) {
 	role := getRoleFromRequest(r)
-	if role == "" {
+	if !isStaffRole(role) {
 		http.Error(w, "unauthorized", http.StatusUnauthorized)
 		return
 	}
@@ -2154,7 +2360,7 @@ func (a *App) adminExtrasHandler(w http.ResponseWriter, r *http.Request) {
 // adminRaidBossesHandler handles CRUD for raid bosses, loading from and persisting to bosses.json
 func (a *App) adminRaidBossesHandler(w http.ResponseWriter, r *http.Request) {
 	role := getRoleFromRequest(r)
-	if role == "" {
+	if !isStaffRole(role) {
 		http.Error(w, "unauthorized", http.StatusUnauthorized)
 		return
 	}
@@ -2167,40 +2373,69 @@ func (a *App) adminRaidBossesHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// find target season by code
-	idx, ok := a.findSeasonIndexByCode(season)
+	target, ok := a.store.FindSeason(season)
 	if !ok {
 		http.Error(w, "season not found", http.StatusNotFound)
 		return
 	}
-	target := &a.seasons[idx]
+
+	// updateTarget applies an edit to the in-memory seasons and then persists it
+	updateTarget := func(apply func(seasons []Season) error, persist func(ctx context.Context) error) error {
+		return a.store.Update(func(tx *SeasonTx) error {
+			if err := apply(tx.Seasons); err != nil {
+				return err
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			return persist(ctx)
+		})
+	}
 
 	switch r.Method {
 	case http.MethodGet:
 		// Return raid bosses from in-memory season data
-		bosses := []map[string]interface{}{}
-		for i, boss := range target.RaidBosses {
+		bossVM := func(boss RaidBoss) map[string]interface{} {
 			movesJSON, _ := json.Marshal(boss.Moves)
 			phasesJSON, _ := json.Marshal(boss.PhaseEffects)
 			variationsJSON, _ := json.Marshal(boss.Variations)
-			bosses = append(bosses, map[string]interface{}{
-				"id":            i, // Use index as ID
+			return map[string]interface{}{
+				"id":            boss.ID,
+				"revision":      boss.Revision,
 				"boss_name":     boss.Name,
 				"stars":         boss.Stars,
 				"description":   boss.Description,
 				"ability":       boss.Ability,
 				"held_item":     boss.HeldItem,
+				"types":         boss.Types,
 				"speed_evs":     boss.SpeedEVs,
 				"base_stats":    boss.BaseStats,
 				"moves":         string(movesJSON),
 				"phase_effects": string(phasesJSON),
 				"variations":    string(variationsJSON),
-			})
+			}
+		}
+
+		// a single boss is returned with its revision as the ETag
+		if id := r.URL.Query().Get("id"); id != "" {
+			boss := findBossByID(&target, id)
+			if boss == nil {
+				http.Error(w, "boss not found", http.StatusNotFound)
+				return
+			}
+			setETag(w, boss.Revision)
+			json.NewEncoder(w).Encode(bossVM(*boss))
+			return
+		}
+
+		bosses := []map[string]interface{}{}
+		for _, boss := range target.RaidBosses {
+			bosses = append(bosses, bossVM(boss))
 		}
 		json.NewEncoder(w).Encode(bosses)
 
 	case http.MethodPost:
-		// allow CRU for admin/mod/author on JSONs
-		if role != "admin" && role != "mod" && role != "author" {
+		// authors propose variations through the moderation queue instead
+		if role != "admin" && role != "mod" {
 			http.Error(w, "forbidden", http.StatusForbidden)
 			return
 		}
@@ -2210,6 +2445,7 @@ func (a *App) adminRaidBossesHandler(w http.ResponseWriter, r *http.Request) {
 			Description  string          `json:"description"`
 			Ability      string          `json:"ability"`
 			HeldItem     string          `json:"held_item"`
+			Types        []string        `json:"types"`
 			SpeedEVs     int             `json:"speed_evs"`
 			BaseStats    BaseStats       `json:"base_stats"`
 			Moves        json.RawMessage `json:"moves"`
@@ -2225,54 +2461,79 @@ func (a *App) adminRaidBossesHandler(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
-		// Parse moves
-		var moves []RaidBossMove
-		if err := json.Unmarshal(payload.Moves, &moves); err != nil {
-			moves = []RaidBossMove{}
-		}
-		// Parse phase effects
-		var phases []PhaseEffect
-		if err := json.Unmarshal(payload.PhaseEffects, &phases); err != nil {
-			phases = []PhaseEffect{}
+		// a list that fails to decode is rejected rather than saved empty
+		moves, phases, variations := []RaidBossMove{}, []PhaseEffect{}, []Variation{}
+		errs := decodeField("moves", payload.Moves, &moves)
+		errs = append(errs, decodeField("phase_effects", payload.PhaseEffects, &phases)...)
+		errs = append(errs, decodeField("variations", payload.Variations, &variations)...)
+		if len(errs) > 0 {
+			writeValidationErrors(w, errs)
+			return
 		}
-		// Parse variations
-		var variations []Variation
-		if err := json.Unmarshal(payload.Variations, &variations); err != nil {
-			variations = []Variation{}
+		if errs := append(validatePhaseEffects(phases), validateVariations(variations)...); len(errs) > 0 {
+			writeValidationErrors(w, errs)
+			return
 		}
 
 		newBoss := RaidBoss{
+			ID:           newID(),
+			Revision:     1,
 			Name:         payload.BossName,
 			Stars:        payload.Stars,
 			Description:  payload.Description,
 			Ability:      payload.Ability,
 			HeldItem:     payload.HeldItem,
+			Types:        typechart.NormalizeAll(payload.Types),
 			SpeedEVs:     payload.SpeedEVs,
 			BaseStats:    payload.BaseStats,
 			Moves:        moves,
 			PhaseEffects: phases,
 			Variations:   variations,
 		}
-		target.RaidBosses = append(target.RaidBosses, newBoss)
-		if err := a.saveBossesJSON(); err != nil {
+		newBoss.ensureIDs()
+		for i := range newBoss.Variations {
+			a.gameData.linkVariation(&newBoss.Variations[i])
+		}
+		warnings, ok := checkGameData(w, a.gameData.lintBoss(newBoss))
+		if !ok {
+			return
+		}
+		now := time.Now()
+		for i := range newBoss.Variations {
+			newBoss.Variations[i].Revision = 1
+			stampVariation(&newBoss.Variations[i], getUsernameFromRequest(r), now, true)
+		}
+		err := updateTarget(func(seasons []Season) error {
+			return createBossIn(seasons, season, newBoss)
+		}, func(ctx context.Context) error {
+			return a.bosses.CreateBoss(ctx, season, newBoss)
+		})
+		if errors.Is(err, errSeasonNotFound) {
+			http.Error(w, "season not found", http.StatusNotFound)
+			return
+		} else if err != nil {
 			http.Error(w, "failed to save bosses", http.StatusInternalServerError)
 			return
 		}
-		json.NewEncoder(w).Encode(map[string]string{"status": "created"})
+		a.recordBossRevision(r, season, actionCreateBoss, "", newBoss)
+		setETag(w, newBoss.Revision)
+		json.NewEncoder(w).Encode(map[string]interface{}{"status": "created", "id": newBoss.ID, "revision": newBoss.Revision, "warnings": warnings})
 
 	case http.MethodPut:
-		// allow CRU for admin/mod/author on JSONs
-		if role != "admin" && role != "mod" && role != "author" {
+		// authors propose variations through the moderation queue instead
+		if role != "admin" && role != "mod" {
 			http.Error(w, "forbidden", http.StatusForbidden)
 			return
 		}
 		var payload struct {
-			ID           int             `json:"id"`
+			ID           string          `json:"id"`
+			Revision     *int            `json:"revision"` // required unless If-Match is sent
 			BossName     string          `json:"boss_name"`
 			Stars        int             `json:"stars"`
 			Description  string          `json:"description"`
 			Ability      string          `json:"ability"`
 			HeldItem     string          `json:"held_item"`
+			Types        []string        `json:"types"`
 			SpeedEVs     int             `json:"speed_evs"`
 			BaseStats    BaseStats       `json:"base_stats"`
 			Moves        json.RawMessage `json:"moves"`
@@ -2280,47 +2541,85 @@ func (a *App) adminRaidBossesHandler(w http.ResponseWriter, r *http.Request) {
 			Variations   json.RawMessage `json:"variations"`
 		}
 		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			var typeErr *json.UnmarshalTypeError
+			if errors.As(err, &typeErr) && typeErr.Field == "id" {
+				http.Error(w, "id must be a boss ID string, numeric indexes are no longer supported", http.StatusBadRequest)
+				return
+			}
 			http.Error(w, "invalid body", http.StatusBadRequest)
 			return
 		}
-		if payload.ID < 0 || payload.ID >= len(target.RaidBosses) {
-			http.Error(w, "boss not found", http.StatusNotFound)
+		if !validID(payload.ID) {
+			http.Error(w, "valid boss id required", http.StatusBadRequest)
 			return
 		}
-
-		// Parse moves
-		var moves []RaidBossMove
-		if err := json.Unmarshal(payload.Moves, &moves); err != nil {
-			moves = []RaidBossMove{}
+		expected, err := expectedRevision(r, payload.Revision)
+		if err != nil {
+			writeRevisionError(w, err)
+			return
 		}
-		// Parse phase effects
-		var phases []PhaseEffect
-		if err := json.Unmarshal(payload.PhaseEffects, &phases); err != nil {
-			phases = []PhaseEffect{}
+
+		// a list that fails to decode is rejected rather than saved empty
+		moves, phases, variations := []RaidBossMove{}, []PhaseEffect{}, []Variation{}
+		errs := decodeField("moves", payload.Moves, &moves)
+		errs = append(errs, decodeField("phase_effects", payload.PhaseEffects, &phases)...)
+		errs = append(errs, decodeField("variations", payload.Variations, &variations)...)
+		if len(errs) > 0 {
+			writeValidationErrors(w, errs)
+			return
 		}
-		// Parse variations
-		var variations []Variation
-		if err := json.Unmarshal(payload.Variations, &variations); err != nil {
-			variations = []Variation{}
+		if errs := append(validatePhaseEffects(phases), validateVariations(variations)...); len(errs) > 0 {
+			writeValidationErrors(w, errs)
+			return
 		}
 
-		target.RaidBosses[payload.ID] = RaidBoss{
+		updated := RaidBoss{
+			ID:           payload.ID,
 			Name:         payload.BossName,
 			Stars:        payload.Stars,
 			Description:  payload.Description,
 			Ability:      payload.Ability,
 			HeldItem:     payload.HeldItem,
+			Types:        typechart.NormalizeAll(payload.Types),
 			SpeedEVs:     payload.SpeedEVs,
 			BaseStats:    payload.BaseStats,
 			Moves:        moves,
 			PhaseEffects: phases,
 			Variations:   variations,
 		}
-		if err := a.saveBossesJSON(); err != nil {
+		// variations added in the builder arrive without an ID
+		updated.ensureIDs()
+		for i := range updated.Variations {
+			a.gameData.linkVariation(&updated.Variations[i])
+		}
+		warnings, ok := checkGameData(w, a.gameData.lintBoss(updated))
+		if !ok {
+			return
+		}
+		// updateBossIn keeps the creator of existing variations and the editor of unchanged ones
+		now := time.Now()
+		for i := range updated.Variations {
+			stampVariation(&updated.Variations[i], getUsernameFromRequest(r), now, true)
+		}
+		err = updateTarget(func(seasons []Season) error {
+			return updateBossIn(seasons, season, &updated, expected)
+		}, func(ctx context.Context) error {
+			return a.bosses.UpdateBoss(ctx, season, updated, expected)
+		})
+		var conflict *revisionConflictError
+		if errors.As(err, &conflict) {
+			writeRevisionConflict(w, conflict)
+			return
+		} else if errors.Is(err, errSeasonNotFound) || errors.Is(err, errBossNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		} else if err != nil {
 			http.Error(w, "failed to save bosses", http.StatusInternalServerError)
 			return
 		}
-		json.NewEncoder(w).Encode(map[string]string{"status": "updated"})
+		a.recordBossRevision(r, season, actionUpdateBoss, "", updated)
+		setETag(w, updated.Revision)
+		json.NewEncoder(w).Encode(map[string]interface{}{"status": "updated", "revision": updated.Revision, "warnings": warnings})
 
 	case http.MethodDelete:
 		// only admin may delete JSON bosses
@@ -2328,21 +2627,26 @@ func (a *App) adminRaidBossesHandler(w http.ResponseWriter, r *http.Request) {
 			http.Error(w, "forbidden", http.StatusForbidden)
 			return
 		}
-		idStr := r.URL.Query().Get("id")
-		if idStr == "" {
-			http.Error(w, "id required", http.StatusBadRequest)
+		id := r.URL.Query().Get("id")
+		if !validID(id) {
+			http.Error(w, "valid boss id required", http.StatusBadRequest)
 			return
 		}
-		id, _ := strconv.Atoi(idStr)
-		if id < 0 || id >= len(target.RaidBosses) {
-			http.Error(w, "boss not found", http.StatusNotFound)
+		var deleted RaidBoss
+		err := updateTarget(func(seasons []Season) error {
+			deleted = bossSnapshot(seasons, season, id)
+			return deleteBossIn(seasons, season, id)
+		}, func(ctx context.Context) error {
+			return a.bosses.DeleteBoss(ctx, season, id)
+		})
+		if errors.Is(err, errSeasonNotFound) || errors.Is(err, errBossNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
 			return
-		}
-		target.RaidBosses = append(target.RaidBosses[:id], target.RaidBosses[id+1:]...)
-		if err := a.saveBossesJSON(); err != nil {
+		} else if err != nil {
 			http.Error(w, "failed to save bosses", http.StatusInternalServerError)
 			return
 		}
+		a.recordBossRevision(r, season, actionDeleteBoss, "", deleted)
 		json.NewEncoder(w).Encode(map[string]string{"status": "deleted"})
 
 	default:
@@ -2353,7 +2657,7 @@ func (a *App) adminRaidBossesHandler(w http.ResponseWriter, r *http.Request) {
 // adminSeasonsHandler manages CRUD for seasons (admin only)
 func (a *App) adminSeasonsHandler(w http.ResponseWriter, r *http.Request) {
 	role := getRoleFromRequest(r)
-	if role == "" {
+	if !isStaffRole(role) {
 		http.Error(w, "unauthorized", http.StatusUnauthorized)
 		return
 	}
@@ -2364,8 +2668,9 @@ func (a *App) adminSeasonsHandler(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
 	buildList := func() []map[string]interface{} {
-		out := make([]map[string]interface{}, 0, len(a.seasons))
-		for _, s := range a.seasons {
+		seasons := a.store.Seasons()
+		out := make([]map[string]interface{}, 0, len(seasons))
+		for _, s := range seasons {
 			out = append(out, map[string]interface{}{
 				"code":  seasonCode(s),
 				"label": seasonLabel(s),
@@ -2402,17 +2707,20 @@ func (a *App) adminSeasonsHandler(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 		code = fmt.Sprintf("%s_%d", slug, payload.Year)
-		if _, exists := a.findSeasonIndexByCode(code); exists {
+		newSeason := Season{SeasonName: name, Year: payload.Year, RaidBosses: []RaidBoss{}}
+		err := a.store.Update(func(tx *SeasonTx) error {
+			var err error
+			if tx.Seasons, err = createSeasonIn(tx.Seasons, newSeason); err != nil {
+				return err
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			return a.bosses.CreateSeason(ctx, newSeason)
+		})
+		if errors.Is(err, errSeasonExists) {
 			http.Error(w, "season already exists", http.StatusConflict)
 			return
-		}
-		newSeason := Season{SeasonName: name, Year: payload.Year, RaidBosses: []RaidBoss{}}
-		a.seasons = append(a.seasons, newSeason)
-		if len(a.seasons) == 1 {
-			a.season = newSeason
-			a.preprocessVariations()
-		}
-		if err := a.saveBossesJSON(); err != nil {
+		} else if err != nil {
 			http.Error(w, "failed to save", http.StatusInternalServerError)
 			return
 		}
@@ -2434,46 +2742,47 @@ func (a *App) adminSeasonsHandler(w http.ResponseWriter, r *http.Request) {
 			http.Error(w, "original_code, name and positive year required", http.StatusBadRequest)
 			return
 		}
-		idx, ok := a.findSeasonIndexByCode(payload.OriginalCode)
-		if !ok {
-			http.Error(w, "season not found", http.StatusNotFound)
-			return
-		}
 		slug := slugifyName(payload.Name)
 		if slug == "" {
 			http.Error(w, "invalid name", http.StatusBadRequest)
 			return
 		}
 		newCode := fmt.Sprintf("%s_%d", slug, payload.Year)
-		for i, s := range a.seasons {
-			if i == idx {
-				continue
+		err := a.store.Update(func(tx *SeasonTx) error {
+			if err := updateSeasonIn(tx.Seasons, payload.OriginalCode, payload.Name, payload.Year); err != nil {
+				return err
 			}
-			if seasonCode(s) == newCode {
-				http.Error(w, "season already exists", http.StatusConflict)
-				return
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			if err := a.bosses.UpdateSeason(ctx, payload.OriginalCode, payload.Name, payload.Year); err != nil {
+				return err
 			}
-		}
-		// preserve raid bosses while updating metadata
-		s := a.seasons[idx]
-		s.SeasonName = payload.Name
-		s.Year = payload.Year
-		a.seasons[idx] = s
 
-		// update in-memory current and default season pointers
-		if seasonCode(a.season) == payload.OriginalCode {
-			a.season = s
-			a.preprocessVariations()
-		}
-		if a.defaultSeason == payload.OriginalCode {
-			a.defaultSeason = newCode
-			_, _ = a.adminDB.Exec("INSERT INTO settings(key,value) VALUES('default_season',?) ON CONFLICT(key) DO UPDATE SET value=excluded.value", newCode)
-		}
-
-		if err := a.saveBossesJSON(); err != nil {
+			// keep the default season pointing at the renamed season
+			if tx.Default == payload.OriginalCode {
+				tx.Default = newCode
+				_, _ = a.adminDB.Exec("INSERT INTO settings(key,value) VALUES('default_season',?) ON CONFLICT(key) DO UPDATE SET value=excluded.value", newCode)
+			}
+			return nil
+		})
+		if errors.Is(err, errSeasonNotFound) {
+			http.Error(w, "season not found", http.StatusNotFound)
+			return
+		} else if errors.Is(err, errSeasonExists) {
+			http.Error(w, "season already exists", http.StatusConflict)
+			return
+		} else if err != nil {
 			http.Error(w, "failed to save", http.StatusInternalServerError)
 			return
 		}
+		if a.history != nil {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			renamed := seasonCode(Season{SeasonName: payload.Name, Year: payload.Year})
+			if err := a.history.RenameSeason(ctx, payload.OriginalCode, renamed); err != nil {
+				log.Printf("warning: failed to move revisions of season %s: %v", payload.OriginalCode, err)
+			}
+		}
 		json.NewEncoder(w).Encode(map[string]interface{}{"status": "updated", "code": newCode, "seasons": buildList()})
 		return
 
@@ -2483,32 +2792,32 @@ func (a *App) adminSeasonsHandler(w http.ResponseWriter, r *http.Request) {
 			http.Error(w, "code required", http.StatusBadRequest)
 			return
 		}
-		idx, ok := a.findSeasonIndexByCode(code)
-		if !ok {
-			http.Error(w, "season not found", http.StatusNotFound)
-			return
-		}
-		// remove from slice
-		removed := a.seasons[idx]
-		a.seasons = append(a.seasons[:idx], a.seasons[idx+1:]...)
-
-		// adjust current season if needed
-		if seasonCode(a.season) == code {
-			if len(a.seasons) > 0 {
-				a.season = a.seasons[0]
-				a.preprocessVariations()
-			} else {
-				a.season = Season{}
+		var removed Season
+		err := a.store.Update(func(tx *SeasonTx) error {
+			idx := findSeasonIndex(tx.Seasons, code)
+			if idx < 0 {
+				return errSeasonNotFound
+			}
+			// remove from slice; the current season falls back to the first one
+			removed = tx.Seasons[idx]
+			tx.Seasons, _ = deleteSeasonIn(tx.Seasons, code)
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			if err := a.bosses.DeleteSeason(ctx, code); err != nil {
+				return err
 			}
-		}
-
-		// clear default season if deleted
-		if a.defaultSeason == code {
-			a.defaultSeason = ""
-			_, _ = a.adminDB.Exec("DELETE FROM settings WHERE key='default_season'")
-		}
 
-		if err := a.saveBossesJSON(); err != nil {
+			// clear default season if deleted
+			if tx.Default == code {
+				tx.Default = ""
+				_, _ = a.adminDB.Exec("DELETE FROM settings WHERE key='default_season'")
+			}
+			return nil
+		})
+		if errors.Is(err, errSeasonNotFound) {
+			http.Error(w, "season not found", http.StatusNotFound)
+			return
+		} else if err != nil {
 			http.Error(w, "failed to save", http.StatusInternalServerError)
 			return
 		}
@@ -2521,18 +2830,6 @@ func (a *App) adminSeasonsHandler(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-// saveBossesJSON writes the seasons data back to bosses.json
-func (a *App) saveBossesJSON() error {
-	file, err := os.OpenFile(dataPath, os.O_WRONLY|os.O_TRUNC, 0644)
-	if err != nil {
-		return err
-	}
-	defer file.Close()
-	encoder := json.NewEncoder(file)
-	encoder.SetIndent("", "  ")
-	return encoder.Encode(a.seasons)
-}
-
 // adminDefaultSeasonHandler gets/sets the default season for public view (admin only)
 func (a *App) adminDefaultSeasonHandler(w http.ResponseWriter, r *http.Request) {
 	role := getRoleFromRequest(r)
@@ -2544,7 +2841,7 @@ func (a *App) adminDefaultSeasonHandler(w http.ResponseWriter, r *http.Request)
 
 	switch r.Method {
 	case http.MethodGet:
-		json.NewEncoder(w).Encode(map[string]string{"season": a.defaultSeason})
+		json.NewEncoder(w).Encode(map[string]string{"season": a.store.Default()})
 		return
 	case http.MethodPost:
 		var payload struct {
@@ -2554,7 +2851,7 @@ func (a *App) adminDefaultSeasonHandler(w http.ResponseWriter, r *http.Request)
 			http.Error(w, "invalid body", http.StatusBadRequest)
 			return
 		}
-		if _, ok := a.findSeasonIndexByCode(payload.Season); !ok {
+		if _, ok := a.store.FindSeason(payload.Season); !ok {
 			http.Error(w, "season not found", http.StatusNotFound)
 			return
 		}
@@ -2564,11 +2861,7 @@ func (a *App) adminDefaultSeasonHandler(w http.ResponseWriter, r *http.Request)
 			http.Error(w, "db error", http.StatusInternalServerError)
 			return
 		}
-		a.defaultSeason = payload.Season
-		if idx, ok := a.findSeasonIndexByCode(payload.Season); ok {
-			a.season = a.seasons[idx]
-			a.preprocessVariations()
-		}
+		a.store.SetDefault(payload.Season)
 		json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
 	default:
 		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
@@ -2697,3 +2990,46 @@ func renderError(w http.ResponseWriter, message string, statusCode int) {
 	log.Printf("Error: %s", message)
 	http.Error(w, message, statusCode)
 }
+
+var errRevisionRequired = errors.New("revision required: send an If-Match header or a revision field")
+
+// expectedRevision returns the revision an edit is based on, taken from the
+// If-Match header or, failing that, the payload's revision field
+func expectedRevision(r *http.Request, field *int) (int, error) {
+	if etag := r.Header.Get("If-Match"); etag != "" {
+		rev, err := strconv.Atoi(strings.Trim(strings.TrimPrefix(etag, "W/"), `"`))
+		if err != nil {
+			return 0, fmt.Errorf("invalid If-Match header %q", etag)
+		}
+		return rev, nil
+	}
+	if field == nil {
+		return 0, errRevisionRequired
+	}
+	return *field, nil
+}
+
+// writeRevisionError responds 428 when an edit names no revision and 400 when
+// its If-Match header is malformed
+func writeRevisionError(w http.ResponseWriter, err error) {
+	if errors.Is(err, errRevisionRequired) {
+		http.Error(w, err.Error(), http.StatusPreconditionRequired)
+		return
+	}
+	http.Error(w, err.Error(), http.StatusBadRequest)
+}
+
+// setETag exposes a revision as the response ETag
+func setETag(w http.ResponseWriter, revision int) {
+	w.Header().Set("ETag", fmt.Sprintf(`"%d"`, revision))
+}
+
+// writeRevisionConflict responds 409 with the current server copy so the client can merge
+func writeRevisionConflict(w http.ResponseWriter, conflict *revisionConflictError) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusConflict)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"error":   "this item was changed by someone else",
+		"current": conflict.Current,
+	})
+}
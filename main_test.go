@@ -0,0 +1,223 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// signedInRequest builds a request carrying an auth_token for a user with role
+func signedInRequest(t *testing.T, role, method, target, body string) *http.Request {
+	t.Helper()
+	token, err := generateJWT("ash", role)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := httptest.NewRequest(method, target, strings.NewReader(body))
+	r.AddCookie(&http.Cookie{Name: "auth_token", Value: token})
+	return r
+}
+
+func TestAuthorCannotUpdateBossVariations(t *testing.T) {
+	a := &App{store: newSeasonStore(testSeasons(), nil)}
+	before, _ := a.store.FindBoss("Glaceon")
+
+	body := `{"id": "glaceon", "revision": 0, "boss_name": "Glaceon", "variations": [` +
+		`{"players": {"P1": [{"pokemon": "Golduck", "move": "Rain Dance"}]}, "health_remaining": [10]}]}`
+	r := signedInRequest(t, "author", http.MethodPut, "/api/admin/raid-bosses?season=christmas_2024", body)
+	w := httptest.NewRecorder()
+	a.adminRaidBossesHandler(w, r)
+
+	if w.Code != http.StatusForbidden {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusForbidden)
+	}
+	after, _ := a.store.FindBoss("Glaceon")
+	if !reflect.DeepEqual(before, after) {
+		t.Errorf("author PUT changed the stored boss: %+v", after.Variations)
+	}
+}
+
+func TestSaveVariationRejectsIndexes(t *testing.T) {
+	a := &App{store: newSeasonStore(testSeasons(), nil), gameData: &gameData{}}
+	bossID := newID()
+	tests := []struct {
+		name string
+		body string
+		want string // in the error message
+	}{
+		{"legacy variation_index", `{"boss_id": "` + bossID + `", "variation_index": 0, "health_remaining": [50]}`, "variation_index"},
+		{"numeric boss id", `{"boss_id": "0", "variation_id": "` + newID() + `", "health_remaining": [50]}`, "boss_id"},
+		{"numeric variation id", `{"boss_id": "` + bossID + `", "variation_id": "1", "health_remaining": [50]}`, "variation_id"},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		a.saveVariationHandler(w, signedInRequest(t, "admin", http.MethodPost, "/api/boss/save-variation", tt.body))
+		if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), tt.want) {
+			t.Errorf("%s: %d %q, want %d mentioning %s", tt.name, w.Code, w.Body.String(), http.StatusBadRequest, tt.want)
+		}
+	}
+}
+
+func TestExpectedRevision(t *testing.T) {
+	three := 3
+	tests := []struct {
+		name      string
+		ifMatch   string
+		field     *int
+		want      int
+		wantErr   error
+		malformed bool // any error other than errRevisionRequired
+	}{
+		{name: "quoted If-Match", ifMatch: `"5"`, field: &three, want: 5},
+		{name: "weak If-Match", ifMatch: `W/"5"`, want: 5},
+		{name: "payload revision", field: &three, want: 3},
+		{name: "no revision", wantErr: errRevisionRequired},
+		{name: "malformed If-Match", ifMatch: `"five"`, field: &three, malformed: true},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest(http.MethodPost, "/", nil)
+		if tt.ifMatch != "" {
+			r.Header.Set("If-Match", tt.ifMatch)
+		}
+		got, err := expectedRevision(r, tt.field)
+		switch {
+		case tt.malformed:
+			if err == nil || errors.Is(err, errRevisionRequired) {
+				t.Errorf("%s: err = %v, want a malformed header error", tt.name, err)
+			}
+		case !errors.Is(err, tt.wantErr):
+			t.Errorf("%s: err = %v, want %v", tt.name, err, tt.wantErr)
+		case got != tt.want:
+			t.Errorf("%s: revision = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSaveVariationRevisionErrors(t *testing.T) {
+	bossID, variationID := newID(), newID()
+	seasons := []Season{{SeasonName: "Christmas", Year: 2024, RaidBosses: []RaidBoss{{
+		ID: bossID, Revision: 3, Name: "Glaceon",
+		Variations: []Variation{{ID: variationID, Revision: 2, HealthRemaining: []float64{80}}},
+	}}}}
+	a := &App{store: newSeasonStore(seasons, nil), gameData: &gameData{}}
+	body := func(revision string) string {
+		return `{"boss_id": "` + bossID + `", "variation_id": "` + variationID + `"` + revision +
+			`, "players": {"P1": [{"pokemon": "Golduck", "action": "move", "move": "Surf"}]}, "health_remaining": [50]}`
+	}
+
+	tests := []struct {
+		name    string
+		body    string
+		ifMatch string
+		want    int
+	}{
+		{"stale revision", body(`, "revision": 1`), "", http.StatusConflict},
+		{"stale If-Match", body(""), `"1"`, http.StatusConflict},
+		{"no revision", body(""), "", http.StatusPreconditionRequired},
+		{"malformed If-Match", body(`, "revision": 2`), "two", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		r := signedInRequest(t, "admin", http.MethodPost, "/api/boss/save-variation", tt.body)
+		if tt.ifMatch != "" {
+			r.Header.Set("If-Match", tt.ifMatch)
+		}
+		w := httptest.NewRecorder()
+		a.saveVariationHandler(w, r)
+		if w.Code != tt.want {
+			t.Errorf("%s: status = %d, want %d (%s)", tt.name, w.Code, tt.want, w.Body.String())
+			continue
+		}
+		if tt.want != http.StatusConflict {
+			continue
+		}
+		var conflict struct {
+			Current Variation `json:"current"`
+		}
+		if err := json.NewDecoder(w.Body).Decode(&conflict); err != nil {
+			t.Fatal(err)
+		}
+		if conflict.Current.ID != variationID || conflict.Current.Revision != 2 {
+			t.Errorf("%s: conflict body carries %+v, want the stored variation", tt.name, conflict.Current)
+		}
+	}
+
+	boss, _ := a.store.FindBoss("Glaceon")
+	if boss.Revision != 3 || boss.Variations[0].HealthRemaining[0] != 80 {
+		t.Errorf("rejected edits changed the boss: %+v", boss)
+	}
+}
+
+func TestRaidBossPayloadDecodeErrors(t *testing.T) {
+	bossID := newID()
+	seasons := []Season{{SeasonName: "Christmas", Year: 2024, RaidBosses: []RaidBoss{{
+		ID: bossID, Revision: 1, Name: "Glaceon",
+		Moves:      []RaidBossMove{{Name: "Blizzard"}},
+		Variations: []Variation{{ID: newID(), Revision: 1, HealthRemaining: []float64{80}}},
+	}}}}
+	a := &App{store: newSeasonStore(seasons, nil), gameData: &gameData{}}
+	before, _ := a.store.FindBoss("Glaceon")
+
+	tests := []struct {
+		name   string
+		method string
+		field  string
+		list   string
+	}{
+		{"update with a non-numeric health", http.MethodPut, "variations", `[{"players": {}, "health_remaining": ["x"]}]`},
+		{"update with a bad player", http.MethodPut, "variations", `[{"players": {"P1": "Golduck"}, "health_remaining": [50]}]`},
+		{"update with moves as an object", http.MethodPut, "moves", `{"name": "Blizzard"}`},
+		{"create with a bad phase effect", http.MethodPost, "phase_effects", `[{"health": "half"}]`},
+	}
+	for _, tt := range tests {
+		body := `{"id": "` + bossID + `", "revision": 1, "boss_name": "Glaceon", "` + tt.field + `": ` + tt.list + `}`
+		w := httptest.NewRecorder()
+		a.adminRaidBossesHandler(w, signedInRequest(t, "admin", tt.method, "/api/admin/raid-bosses?season=christmas_2024", body))
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want %d", tt.name, w.Code, http.StatusBadRequest)
+			continue
+		}
+		var resp struct {
+			Fields []FieldError `json:"fields"`
+		}
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatal(err)
+		}
+		if len(resp.Fields) != 1 || resp.Fields[0].Field != tt.field {
+			t.Errorf("%s: field errors = %+v, want one on %s", tt.name, resp.Fields, tt.field)
+		}
+	}
+
+	after, _ := a.store.FindBoss("Glaceon")
+	if !reflect.DeepEqual(before, after) || len(a.store.Current().RaidBosses) != 1 {
+		t.Errorf("rejected payloads changed the season: %+v", a.store.Current().RaidBosses)
+	}
+}
+
+func TestRaidBossPhaseHealthRange(t *testing.T) {
+	a := &App{store: newSeasonStore(testSeasons(), nil), gameData: &gameData{}}
+	for _, health := range []string{"-1", "101", "300"} {
+		body := `{"boss_name": "Gengar", "phase_effects": [{"health": ` + health + `, "kind": "custom", "name": "Curse"}]}`
+		w := httptest.NewRecorder()
+		a.adminRaidBossesHandler(w, signedInRequest(t, "admin", http.MethodPost, "/api/admin/raid-bosses?season=christmas_2024", body))
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("health %s: status = %d, want %d", health, w.Code, http.StatusBadRequest)
+			continue
+		}
+		var resp struct {
+			Fields []FieldError `json:"fields"`
+		}
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatal(err)
+		}
+		if len(resp.Fields) != 1 || resp.Fields[0].Field != "phase_effects[0].health" {
+			t.Errorf("health %s: field errors = %+v, want one on phase_effects[0].health", health, resp.Fields)
+		}
+	}
+	if _, ok := a.store.FindBoss("Gengar"); ok {
+		t.Error("boss with an out-of-range phase was saved")
+	}
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"html"
+	"regexp"
+	"strings"
+)
+
+// renderMarkdown renders the small Markdown subset comments support: paragraphs,
+// "-" and "1." lists, "> " quotes, ``` code blocks, `code`, **bold**, *italic*
+// and [links](https://...). All input is HTML-escaped before formatting is
+// applied, so user-written HTML never reaches the page.
+func renderMarkdown(src string) string {
+	var sb strings.Builder
+	var para, quote []string
+	list := "" // "ul" or "ol" while inside a list
+
+	closeList := func() {
+		if list != "" {
+			sb.WriteString("</" + list + ">")
+			list = ""
+		}
+	}
+	flush := func() {
+		if len(para) > 0 {
+			sb.WriteString("<p>" + strings.Join(para, "<br>") + "</p>")
+			para = nil
+		}
+		if len(quote) > 0 {
+			sb.WriteString("<blockquote>" + strings.Join(quote, "<br>") + "</blockquote>")
+			quote = nil
+		}
+		closeList()
+	}
+	openList := func(kind string) {
+		if list != kind {
+			flush()
+			sb.WriteString("<" + kind + ">")
+			list = kind
+		}
+	}
+
+	lines := strings.Split(strings.ReplaceAll(src, "\r\n", "\n"), "\n")
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimSpace(lines[i])
+		switch {
+		case strings.HasPrefix(line, "```"):
+			flush()
+			var code []string
+			for i++; i < len(lines) && !strings.HasPrefix(strings.TrimSpace(lines[i]), "```"); i++ {
+				code = append(code, lines[i])
+			}
+			sb.WriteString("<pre><code>" + html.EscapeString(strings.Join(code, "\n")) + "</code></pre>")
+		case line == "":
+			flush()
+		case strings.HasPrefix(line, "- ") || strings.HasPrefix(line, "* "):
+			openList("ul")
+			sb.WriteString("<li>" + renderInline(line[2:]) + "</li>")
+		case mdOrderedItem.MatchString(line):
+			openList("ol")
+			sb.WriteString("<li>" + renderInline(mdOrderedItem.ReplaceAllString(line, "")) + "</li>")
+		case strings.HasPrefix(line, ">"):
+			if len(para) > 0 || list != "" {
+				flush()
+			}
+			quote = append(quote, renderInline(strings.TrimSpace(line[1:])))
+		default:
+			if len(quote) > 0 || list != "" {
+				flush()
+			}
+			para = append(para, renderInline(line))
+		}
+	}
+	flush()
+	return sb.String()
+}
+
+var (
+	mdOrderedItem = regexp.MustCompile(`^\d{1,3}[.)]\s+`)
+	mdLink        = regexp.MustCompile(`\[([^\]]+)\]\((https?://[^\s)]+)\)`)
+	mdBold        = regexp.MustCompile(`\*\*([^*]+)\*\*`)
+	mdItalic      = regexp.MustCompile(`\*([^*\s][^*]*)\*`)
+)
+
+// renderInline escapes a line of text and applies the inline formatting;
+// nothing inside `code` spans is formatted
+func renderInline(text string) string {
+	var sb strings.Builder
+	parts := strings.Split(text, "`")
+	if len(parts)%2 == 0 {
+		// an unmatched backtick is plain text
+		last := len(parts) - 1
+		parts = append(parts[:last-1], parts[last-1]+"`"+parts[last])
+	}
+	for i, part := range parts {
+		escaped := html.EscapeString(part)
+		if i%2 == 1 {
+			sb.WriteString("<code>" + escaped + "</code>")
+			continue
+		}
+		escaped = mdLink.ReplaceAllString(escaped, `<a href="$2" rel="nofollow noopener" target="_blank">$1</a>`)
+		escaped = mdBold.ReplaceAllString(escaped, "<strong>$1</strong>")
+		escaped = mdItalic.ReplaceAllString(escaped, "<em>$1</em>")
+		sb.WriteString(escaped)
+	}
+	return sb.String()
+}
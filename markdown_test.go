@@ -0,0 +1,34 @@
+package main
+
+import "testing"
+
+func TestRenderMarkdown(t *testing.T) {
+	for _, tc := range []struct{ in, want string }{
+		{"hello\nworld", "<p>hello<br>world</p>"},
+		{"one\n\ntwo", "<p>one</p><p>two</p>"},
+		{"**Golduck** is *slow*", "<p><strong>Golduck</strong> is <em>slow</em></p>"},
+		{"use `**Surf**`", "<p>use <code>**Surf**</code></p>"},
+		{"a ` b", "<p>a ` b</p>"},
+		{"- P1 Surf\n- P2 Protect\n\n1. first", "<ul><li>P1 Surf</li><li>P2 Protect</li></ul><ol><li>first</li></ol>"},
+		{"> quoted\nreply", "<blockquote>quoted</blockquote><p>reply</p>"},
+		{"```\n<b>x</b>\n```", "<pre><code>&lt;b&gt;x&lt;/b&gt;</code></pre>"},
+		{"[guide](https://example.com/a?b=1&c=2)", `<p><a href="https://example.com/a?b=1&amp;c=2" rel="nofollow noopener" target="_blank">guide</a></p>`},
+	} {
+		if got := renderMarkdown(tc.in); got != tc.want {
+			t.Errorf("renderMarkdown(%q) = %q, want %q", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestRenderMarkdownSanitizes(t *testing.T) {
+	for _, tc := range []struct{ in, want string }{
+		{`<script>alert(1)</script>`, "<p>&lt;script&gt;alert(1)&lt;/script&gt;</p>"},
+		{`<img src=x onerror="alert(1)">`, "<p>&lt;img src=x onerror=&#34;alert(1)&#34;&gt;</p>"},
+		{"[x](javascript:alert(1))", "<p>[x](javascript:alert(1))</p>"},
+		{`[x](https://a.com/"onmouseover="alert(1))`, `<p><a href="https://a.com/&#34;onmouseover=&#34;alert(1" rel="nofollow noopener" target="_blank">x</a>)</p>`},
+	} {
+		if got := renderMarkdown(tc.in); got != tc.want {
+			t.Errorf("renderMarkdown(%q) = %q, want %q", tc.in, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,418 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/flosch/pongo2/v4"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Party statuses; a done party is removed from the parties collection after partyDoneTTL
+const (
+	partyOpen = "open"
+	partyDone = "done"
+)
+
+// partyDoneTTL is how long the share link of a finished raid keeps working
+const partyDoneTTL = 24 * time.Hour
+
+var (
+	errPartyNotFound  = errors.New("party not found")
+	errPartyDone      = errors.New("party's raid is already done")
+	errPartyConflict  = errors.New("party was changed by someone else, try again")
+	errPositionTaken  = errors.New("position already claimed")
+	errNotPartyMember = errors.New("not a member of this party")
+)
+
+// PartyMember is a user who claimed a lane of the party's variation
+type PartyMember struct {
+	Username string    `json:"username" bson:"username"`
+	Position string    `json:"position" bson:"position"`
+	JoinedAt time.Time `json:"joined_at" bson:"joined_at"`
+}
+
+// Party is a group of up to four users planning to run a variation together
+type Party struct {
+	ID          primitive.ObjectID `json:"-" bson:"_id,omitempty"`
+	Code        string             `json:"code" bson:"code"` // used in the share link
+	Revision    int                `json:"revision" bson:"revision"`
+	Season      string             `json:"season" bson:"season"`
+	BossID      string             `json:"boss_id" bson:"boss_id"`
+	BossName    string             `json:"boss_name" bson:"boss_name"`
+	VariationID string             `json:"variation_id" bson:"variation_id"`
+	Leader      string             `json:"leader" bson:"leader"`
+	Members     []PartyMember      `json:"members" bson:"members"`
+	Status      string             `json:"status" bson:"status"`
+	CreatedAt   time.Time          `json:"created_at" bson:"created_at"`
+	DoneAt      *time.Time         `json:"done_at,omitempty" bson:"done_at,omitempty"`
+}
+
+// member returns the member holding position, or nil
+func (p *Party) member(position string) *PartyMember {
+	for i := range p.Members {
+		if p.Members[i].Position == position {
+			return &p.Members[i]
+		}
+	}
+	return nil
+}
+
+// claim gives username the position, moving them from any lane they held
+func (p *Party) claim(username, position string) error {
+	if m := p.member(position); m != nil {
+		if m.Username == username {
+			return nil
+		}
+		return errPositionTaken
+	}
+	p.leave(username)
+	p.Members = append(p.Members, PartyMember{Username: username, Position: position, JoinedAt: time.Now()})
+	return nil
+}
+
+// leave removes username from the party and reports whether they were a member
+func (p *Party) leave(username string) bool {
+	for i, m := range p.Members {
+		if m.Username == username {
+			p.Members = append(p.Members[:i], p.Members[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// partyStore keeps parties in the parties collection
+type partyStore struct {
+	coll *mongo.Collection
+}
+
+func newPartyStore(db *mongo.Database) *partyStore {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	coll := db.Collection("parties")
+	_, err := coll.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "code", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "done_at", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(int32(partyDoneTTL.Seconds()))},
+	})
+	if err != nil {
+		log.Printf("warning: failed to create parties indexes: %v", err)
+	}
+	return &partyStore{coll: coll}
+}
+
+// newPartyCode returns a short random code for a party's share link
+func newPartyCode() string {
+	b := make([]byte, 6)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+func (s *partyStore) Create(ctx context.Context, p *Party) error {
+	p.Code = newPartyCode()
+	p.Revision = 1
+	res, err := s.coll.InsertOne(ctx, p)
+	if err != nil {
+		return err
+	}
+	p.ID = res.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+func (s *partyStore) Get(ctx context.Context, code string) (*Party, error) {
+	var p Party
+	err := s.coll.FindOne(ctx, bson.M{"code": code}).Decode(&p)
+	if err == mongo.ErrNoDocuments {
+		return nil, errPartyNotFound
+	} else if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// Update applies fn to an open party and saves it if no one else changed it meanwhile
+func (s *partyStore) Update(ctx context.Context, code string, fn func(*Party) error) (*Party, error) {
+	p, err := s.Get(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	if p.Status == partyDone {
+		return nil, errPartyDone
+	}
+	if err := fn(p); err != nil {
+		return nil, err
+	}
+	expected := p.Revision
+	p.Revision++
+	res, err := s.coll.ReplaceOne(ctx, bson.M{"_id": p.ID, "revision": expected}, p)
+	if err != nil {
+		return nil, err
+	}
+	if res.MatchedCount == 0 {
+		return nil, errPartyConflict
+	}
+	return p, nil
+}
+
+// PartyLane is one lane of a party's variation: the Pokémon it uses, who claimed
+// it and which of those Pokémon are not completed on the member's checklist
+type PartyLane struct {
+	Position string   `json:"position"`
+	Pokemon  []string `json:"pokemon"`
+	Member   string   `json:"member,omitempty"`
+	Missing  []string `json:"missing"`
+}
+
+// PartyView is a party with the readiness of each lane
+type PartyView struct {
+	Party
+	Variation int         `json:"variation"` // 1-based, 0 when the variation was removed
+	Lanes     []PartyLane `json:"lanes"`
+	Ready     bool        `json:"ready"` // every lane with Pokémon is claimed and has nothing missing
+}
+
+// partyLanes lists the lanes of v with each member's missing Pokémon; owned maps
+// a member to the squashed names of their completed checklist Pokémon
+func partyLanes(v Variation, members []PartyMember, owned map[string]map[string]bool) ([]PartyLane, bool) {
+	byPosition := make(map[string]string, len(members))
+	for _, m := range members {
+		byPosition[m.Position] = m.Username
+	}
+	ready := true
+	lanes := make([]PartyLane, 0, maxPlayers)
+	for _, pos := range playerPositions {
+		lane := PartyLane{Position: pos, Pokemon: positionRoster(v.Players[pos]), Member: byPosition[pos], Missing: []string{}}
+		if lane.Pokemon == nil {
+			lane.Pokemon = []string{}
+		}
+		if lane.Member != "" {
+			for _, name := range lane.Pokemon {
+				if !owned[lane.Member][squashName(name)] {
+					lane.Missing = append(lane.Missing, name)
+				}
+			}
+		}
+		if len(lane.Pokemon) > 0 && (lane.Member == "" || len(lane.Missing) > 0) {
+			ready = false
+		}
+		lanes = append(lanes, lane)
+	}
+	return lanes, ready
+}
+
+// partyView resolves a party's variation and checks each member's checklist
+func (a *App) partyView(ctx context.Context, p *Party) (PartyView, error) {
+	view := PartyView{Party: *p, Lanes: []PartyLane{}}
+	season, ok := a.store.FindSeason(p.Season)
+	if !ok {
+		return view, nil
+	}
+	boss := findBossByID(&season, p.BossID)
+	if boss == nil {
+		return view, nil
+	}
+	i := findVariationIndex(boss, p.VariationID)
+	if i < 0 {
+		return view, nil
+	}
+	view.Variation = i + 1
+
+	owned := make(map[string]map[string]bool, len(p.Members))
+	for _, m := range p.Members {
+		doc, err := a.userChecklist(ctx, p.Season, m.Username)
+		if err != nil && err != mongo.ErrNoDocuments {
+			return view, err
+		}
+		owned[m.Username] = map[string]bool{}
+		for _, name := range completedPokemon(doc.Pokemon) {
+			owned[m.Username][squashName(name)] = true
+		}
+	}
+	view.Lanes, view.Ready = partyLanes(boss.Variations[i], p.Members, owned)
+	return view, nil
+}
+
+// writePartyError maps party store errors to HTTP statuses
+func writePartyError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, errPartyNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, errNotPartyMember):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case errors.Is(err, errPartyDone), errors.Is(err, errPartyConflict), errors.Is(err, errPositionTaken):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		log.Printf("Error updating party: %v", err)
+		http.Error(w, "failed to update party", http.StatusInternalServerError)
+	}
+}
+
+// writePartyView encodes a party with its lanes
+func (a *App) writePartyView(w http.ResponseWriter, ctx context.Context, p *Party, status int) {
+	view, err := a.partyView(ctx, p)
+	if err != nil {
+		log.Printf("Error checking party checklists: %v", err)
+		http.Error(w, "failed to load checklists", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(view)
+}
+
+// partyHandler returns a party by code (GET) or creates one for a variation of
+// the current season (POST), with the creator as leader claiming an optional lane
+func (a *App) partyHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	switch r.Method {
+	case http.MethodGet:
+		p, err := a.parties.Get(ctx, r.URL.Query().Get("code"))
+		if err != nil {
+			writePartyError(w, err)
+			return
+		}
+		a.writePartyView(w, ctx, p, http.StatusOK)
+	case http.MethodPost:
+		username := getUsernameFromRequest(r)
+		if username == "" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		var req struct {
+			BossID      string `json:"boss_id"`
+			VariationID string `json:"variation_id"`
+			Position    string `json:"position"` // optional lane for the leader
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "invalid request body", http.StatusBadRequest)
+			return
+		}
+		if req.Position != "" && !slices.Contains(playerPositions[:], req.Position) {
+			http.Error(w, "position must be P1, P2, P3 or P4", http.StatusBadRequest)
+			return
+		}
+		season := a.getSeasonName()
+		current := a.store.Current()
+		boss := findBossByID(&current, req.BossID)
+		if boss == nil {
+			http.Error(w, errBossNotFound.Error(), http.StatusNotFound)
+			return
+		}
+		if findVariationIndex(boss, req.VariationID) < 0 {
+			http.Error(w, errVariationNotFound.Error(), http.StatusNotFound)
+			return
+		}
+
+		p := &Party{
+			Season:      season,
+			BossID:      boss.ID,
+			BossName:    boss.Name,
+			VariationID: req.VariationID,
+			Leader:      username,
+			Members:     []PartyMember{},
+			Status:      partyOpen,
+			CreatedAt:   time.Now(),
+		}
+		if req.Position != "" {
+			p.claim(username, req.Position)
+		}
+		if err := a.parties.Create(ctx, p); err != nil {
+			log.Printf("Error creating party: %v", err)
+			http.Error(w, "failed to create party", http.StatusInternalServerError)
+			return
+		}
+		a.writePartyView(w, ctx, p, http.StatusCreated)
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// partyActionHandler lets a signed-in user claim a lane ("join"), give it up
+// ("leave") or, as a member, mark the party's raid as done ("done")
+func (a *App) partyActionHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	username := getUsernameFromRequest(r)
+	if username == "" {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	var req struct {
+		Code     string `json:"code"`
+		Action   string `json:"action"`
+		Position string `json:"position"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	var fn func(*Party) error
+	switch req.Action {
+	case "join":
+		if !slices.Contains(playerPositions[:], req.Position) {
+			http.Error(w, "position must be P1, P2, P3 or P4", http.StatusBadRequest)
+			return
+		}
+		fn = func(p *Party) error { return p.claim(username, req.Position) }
+	case "leave":
+		fn = func(p *Party) error {
+			if !p.leave(username) {
+				return errNotPartyMember
+			}
+			return nil
+		}
+	case "done":
+		fn = func(p *Party) error {
+			if p.Leader != username && !slices.ContainsFunc(p.Members, func(m PartyMember) bool { return m.Username == username }) {
+				return errNotPartyMember
+			}
+			now := time.Now()
+			p.Status, p.DoneAt = partyDone, &now
+			return nil
+		}
+	default:
+		http.Error(w, "action must be join, leave or done", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	p, err := a.parties.Update(ctx, strings.TrimSpace(req.Code), fn)
+	if err != nil {
+		writePartyError(w, err)
+		return
+	}
+	a.writePartyView(w, ctx, p, http.StatusOK)
+}
+
+// partyPageHandler renders the shareable party page
+func (a *App) partyPageHandler(w http.ResponseWriter, r *http.Request) {
+	code := r.URL.Query().Get("code")
+	if code == "" {
+		http.NotFound(w, r)
+		return
+	}
+	renderTemplate(w, a.templates["party.html"], pongo2.Context{
+		"code":        code,
+		"username":    getUsernameFromRequest(r),
+		"user_role":   getRoleFromRequest(r),
+		"commit_hash": a.commitHash,
+	})
+}
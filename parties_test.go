@@ -0,0 +1,54 @@
+package main
+
+import "testing"
+
+func TestPartyClaim(t *testing.T) {
+	p := &Party{}
+	if err := p.claim("ash", "P1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.claim("misty", "P1"); err != errPositionTaken {
+		t.Fatalf("got %v, want errPositionTaken", err)
+	}
+	// claiming another lane moves the member
+	if err := p.claim("ash", "P3"); err != nil {
+		t.Fatal(err)
+	}
+	if len(p.Members) != 1 || p.Members[0].Position != "P3" {
+		t.Fatalf("members: %+v", p.Members)
+	}
+	if !p.leave("ash") || p.leave("ash") {
+		t.Fatal("leave should succeed once")
+	}
+}
+
+func TestPartyLanes(t *testing.T) {
+	v := Variation{Players: map[string][]Player{
+		"P1": rosterLane("Jolteon"),
+		"P2": rosterLane("Whimsicott", "Snorlax"),
+		"P3": rosterLane(""),
+		"P4": rosterLane("Ferrothorn"),
+	}}
+	members := []PartyMember{{Username: "ash", Position: "P1"}, {Username: "misty", Position: "P2"}}
+	owned := map[string]map[string]bool{
+		"ash":   {"jolteon": true},
+		"misty": {"whimsicott": true},
+	}
+	lanes, ready := partyLanes(v, members, owned)
+	if ready {
+		t.Fatal("party with gaps reported ready")
+	}
+	if len(lanes[0].Missing) != 0 || len(lanes[1].Missing) != 1 || lanes[1].Missing[0] != "Snorlax" {
+		t.Fatalf("lanes: %+v", lanes)
+	}
+	if lanes[3].Member != "" || len(lanes[2].Pokemon) != 0 {
+		t.Fatalf("lanes: %+v", lanes)
+	}
+
+	owned["misty"]["snorlax"] = true
+	members = append(members, PartyMember{Username: "brock", Position: "P4"})
+	owned["brock"] = map[string]bool{"ferrothorn": true}
+	if _, ready := partyLanes(v, members, owned); !ready {
+		t.Fatal("party should be ready; P3 has no Pokémon")
+	}
+}
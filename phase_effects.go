@@ -0,0 +1,274 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// Phase effect kinds
+const (
+	phaseWeather    = "weather"
+	phaseScreen     = "screen"
+	phaseStatus     = "status"
+	phaseStatChange = "stat_change"
+	phaseAdaptation = "adaptation"
+	phaseHeal       = "heal"
+	phaseCustom     = "custom"
+)
+
+var phaseKinds = []string{phaseWeather, phaseScreen, phaseStatus, phaseStatChange, phaseAdaptation, phaseHeal, phaseCustom}
+
+// PhaseEffect is something the boss does once its health drops to Health percent
+type PhaseEffect struct {
+	Health      int         `json:"health" bson:"health"`
+	Kind        string      `json:"kind" bson:"kind"` // one of phaseKinds
+	Name        string      `json:"name" bson:"name"` // e.g. "Reflect", "Snowscape", "Adaptation"
+	Params      PhaseParams `json:"params" bson:"params"`
+	Description string      `json:"description,omitempty" bson:"description,omitempty"`
+
+	legacy bool // decoded from a free-text effect, see migratePhaseEffects
+}
+
+// PhaseParams are the parameters of a phase effect; which apply depends on its kind
+type PhaseParams struct {
+	Weather string         `json:"weather,omitempty" bson:"weather,omitempty"` // rain, sun, sand or hail
+	Screen  string         `json:"screen,omitempty" bson:"screen,omitempty"`   // reflect or light_screen
+	Status  string         `json:"status,omitempty" bson:"status,omitempty"`   // e.g. sleep, paralysis, confusion
+	Stats   map[string]int `json:"stats,omitempty" bson:"stats,omitempty"`     // stat -> stage change, e.g. {"defense": 2}
+	Heal    int            `json:"heal,omitempty" bson:"heal,omitempty"`       // % of max HP restored
+}
+
+// phaseEffectFields is PhaseEffect without its methods, plus the legacy free-text field
+type phaseEffectFields struct {
+	Health      int         `json:"health" bson:"health"`
+	Kind        string      `json:"kind" bson:"kind"`
+	Name        string      `json:"name" bson:"name"`
+	Params      PhaseParams `json:"params" bson:"params"`
+	Description string      `json:"description,omitempty" bson:"description,omitempty"`
+	Effect      string      `json:"effect,omitempty" bson:"effect,omitempty"`
+}
+
+func (pe *PhaseEffect) fromFields(f phaseEffectFields) {
+	if f.Kind == "" && f.Effect != "" {
+		*pe = parsePhaseEffect(f.Effect)
+		pe.Health = f.Health
+		pe.legacy = true
+		return
+	}
+	*pe = PhaseEffect{Health: f.Health, Kind: f.Kind, Name: f.Name, Params: f.Params, Description: f.Description}
+}
+
+// UnmarshalJSON accepts both the structured form and the old {"health", "effect"} form
+func (pe *PhaseEffect) UnmarshalJSON(data []byte) error {
+	var f phaseEffectFields
+	if err := json.Unmarshal(data, &f); err != nil {
+		return err
+	}
+	pe.fromFields(f)
+	return nil
+}
+
+// UnmarshalBSON accepts both the structured form and the old {"health", "effect"} form
+func (pe *PhaseEffect) UnmarshalBSON(data []byte) error {
+	var f phaseEffectFields
+	if err := bson.Unmarshal(data, &f); err != nil {
+		return err
+	}
+	pe.fromFields(f)
+	return nil
+}
+
+// migratePhaseEffects reports whether any phase effect was decoded from free
+// text, so the caller can write the structured form back
+func migratePhaseEffects(seasons []Season) bool {
+	changed := false
+	for si := range seasons {
+		for bi := range seasons[si].RaidBosses {
+			for pi := range seasons[si].RaidBosses[bi].PhaseEffects {
+				pe := &seasons[si].RaidBosses[bi].PhaseEffects[pi]
+				if pe.legacy {
+					pe.legacy = false
+					changed = true
+				}
+			}
+		}
+	}
+	return changed
+}
+
+// phaseWeathers maps moves and abilities that start a weather to it
+var phaseWeathers = map[string]string{
+	"raindance": "rain", "drizzle": "rain", "sunnyday": "sun", "drought": "sun",
+	"sandstorm": "sand", "sandstream": "sand", "hail": "hail", "snowwarning": "hail", "snowscape": "hail",
+}
+
+// phaseStatuses maps moves that inflict a status on players to it
+var phaseStatuses = map[string]string{
+	"sing": "sleep", "hypnosis": "sleep", "sleeppowder": "sleep", "spore": "sleep", "yawn": "sleep", "grasswhistle": "sleep", "lovelykiss": "sleep", "darkvoid": "sleep",
+	"thunderwave": "paralysis", "stunspore": "paralysis", "glare": "paralysis",
+	"willowisp": "burn", "toxic": "poison", "poisonpowder": "poison",
+	"confuseray": "confusion", "supersonic": "confusion", "swagger": "confusion", "sweetkiss": "confusion",
+	"taunt": "taunt", "tauntaura": "taunt", "torment": "torment", "encore": "encore",
+}
+
+// phaseStatMoves maps moves the boss boosts itself with to their stat changes
+var phaseStatMoves = map[string]map[string]int{
+	"swordsdance": {"attack": 2}, "nastyplot": {"sp_attack": 2}, "bellydrum": {"attack": 6},
+	"agility": {"speed": 2}, "autotomize": {"speed": 2}, "rockpolish": {"speed": 2},
+	"acidarmor": {"defense": 2}, "irondefense": {"defense": 2}, "barrier": {"defense": 2}, "amnesia": {"sp_defense": 2},
+	"cosmicpower": {"defense": 1, "sp_defense": 1}, "stockpile": {"defense": 1, "sp_defense": 1},
+	"calmmind": {"sp_attack": 1, "sp_defense": 1}, "bulkup": {"attack": 1, "defense": 1},
+	"dragondance": {"attack": 1, "speed": 1}, "growth": {"attack": 1, "sp_attack": 1},
+	"quiverdance": {"sp_attack": 1, "sp_defense": 1, "speed": 1}, "shellsmash": {"attack": 2, "sp_attack": 2, "speed": 2},
+	"omniboost": {"attack": 1, "defense": 1, "sp_attack": 1, "sp_defense": 1, "speed": 1},
+}
+
+// phaseHeals maps healing moves to the % of max HP they restore
+var phaseHeals = map[string]int{
+	"wish": 50, "recover": 50, "roost": 50, "softboiled": 50, "slackoff": 50, "milkdrink": 50,
+	"healorder": 50, "moonlight": 50, "morningsun": 50, "synthesis": 50, "rest": 100,
+}
+
+// statNames maps the ways stats are written in effect descriptions to stat keys
+var statNames = map[string]string{
+	"attack": "attack", "atk": "attack", "defense": "defense", "def": "defense",
+	"specialattack": "sp_attack", "spatk": "sp_attack", "spattack": "sp_attack",
+	"specialdefense": "sp_defense", "spdef": "sp_defense", "spdefense": "sp_defense",
+	"speed": "speed", "spe": "speed",
+}
+
+var statChangePattern = regexp.MustCompile(`(?i)([+-]\d)\s*(special attack|special defense|sp\.?\s*atk|sp\.?\s*def|attack|defense|speed|atk|def|spe)\b`)
+
+// parsePhaseEffect maps an old free-text effect such as "Acid Armor [+2 defense]⭐️"
+// to a structured one. Bracketed text becomes the description; effects that
+// are not recognised are kept as custom.
+func parsePhaseEffect(text string) PhaseEffect {
+	name, notes := splitBrackets(text)
+	// an effect written entirely in brackets, e.g. "[Roar⭐️]"
+	if name == "" && len(notes) > 0 {
+		name, notes = notes[0], notes[1:]
+	}
+	pe := PhaseEffect{Kind: phaseCustom, Name: name, Description: strings.Join(notes, "; ")}
+
+	key := squashName(name)
+	switch {
+	case phaseWeathers[key] != "":
+		pe.Kind, pe.Params.Weather = phaseWeather, phaseWeathers[key]
+	case key == "reflect":
+		pe.Kind, pe.Params.Screen = phaseScreen, "reflect"
+	case key == "lightscreen":
+		pe.Kind, pe.Params.Screen = phaseScreen, "light_screen"
+	case phaseStatuses[key] != "":
+		pe.Kind, pe.Params.Status = phaseStatus, phaseStatuses[key]
+	case phaseStatMoves[key] != nil:
+		pe.Kind, pe.Params.Stats = phaseStatChange, maps.Clone(phaseStatMoves[key])
+	case phaseHeals[key] != 0:
+		pe.Kind, pe.Params.Heal = phaseHeal, phaseHeals[key]
+	case strings.HasPrefix(key, "adaptation"):
+		pe.Kind = phaseAdaptation
+	default:
+		// e.g. "[Boss Boost] [Omniboost]"
+		for _, note := range notes {
+			if stats := phaseStatMoves[squashName(note)]; stats != nil {
+				pe.Kind, pe.Params.Stats = phaseStatChange, maps.Clone(stats)
+			}
+		}
+	}
+
+	// explicit changes such as "[+2 defense]" override the defaults of the move
+	if stats := parseStatChanges(text); len(stats) > 0 && (pe.Kind == phaseCustom || pe.Kind == phaseStatChange) {
+		pe.Kind, pe.Params.Stats = phaseStatChange, stats
+	}
+	return pe
+}
+
+// splitBrackets separates the text outside top-level brackets from the bracketed
+// notes; brackets nested in a note are kept in it
+func splitBrackets(text string) (string, []string) {
+	var outside, note strings.Builder
+	var notes []string
+	depth := 0
+	for _, r := range text {
+		switch {
+		case r == '[':
+			if depth > 0 {
+				note.WriteRune(r)
+			}
+			depth++
+		case r == ']' && depth > 0:
+			depth--
+			if depth > 0 {
+				note.WriteRune(r)
+			} else if n := strings.TrimSpace(note.String()); n != "" {
+				notes = append(notes, n)
+				note.Reset()
+			}
+		case depth > 0:
+			note.WriteRune(r)
+		default:
+			outside.WriteRune(r)
+		}
+	}
+	if n := strings.TrimSpace(note.String()); n != "" {
+		notes = append(notes, n) // unclosed bracket
+	}
+	return strings.Join(strings.Fields(outside.String()), " "), notes
+}
+
+// parseStatChanges finds changes written as "+2 defense" or "-1 Sp. Def"
+func parseStatChanges(text string) map[string]int {
+	var stats map[string]int
+	for _, m := range statChangePattern.FindAllStringSubmatch(text, -1) {
+		stat, ok := statNames[squashName(m[2])]
+		if !ok {
+			continue
+		}
+		n, _ := strconv.Atoi(m[1])
+		if stats == nil {
+			stats = map[string]int{}
+		}
+		stats[stat] += n
+	}
+	return stats
+}
+
+func (pe PhaseEffect) clone() PhaseEffect {
+	pe.Params.Stats = maps.Clone(pe.Params.Stats)
+	return pe
+}
+
+// Summary describes a phase effect's parameters for display, e.g. "+2 defense"
+// or "heals 50%"; it is empty for adaptation and custom effects
+func (pe PhaseEffect) Summary() string {
+	p := pe.Params
+	switch pe.Kind {
+	case phaseWeather:
+		return p.Weather
+	case phaseScreen:
+		return strings.ReplaceAll(p.Screen, "_", " ")
+	case phaseStatus:
+		return p.Status
+	case phaseStatChange:
+		var parts []string
+		for _, stat := range phaseStatNames {
+			if n, ok := p.Stats[stat]; ok {
+				parts = append(parts, fmt.Sprintf("%+d %s", n, strings.ReplaceAll(stat, "_", ". ")))
+			}
+		}
+		return strings.Join(parts, ", ")
+	case phaseHeal:
+		return fmt.Sprintf("heals %d%%", p.Heal)
+	}
+	return ""
+}
+
+// KindLabel is the kind for display, e.g. "stat change"
+func (pe PhaseEffect) KindLabel() string {
+	return strings.ReplaceAll(pe.Kind, "_", " ")
+}
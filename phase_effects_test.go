@@ -0,0 +1,68 @@
+package main
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func testPhase(health int, text string) PhaseEffect {
+	pe := parsePhaseEffect(text)
+	pe.Health = health
+	return pe
+}
+
+func TestParsePhaseEffect(t *testing.T) {
+	tests := []struct {
+		text string
+		want PhaseEffect
+	}{
+		{"Snowscape", PhaseEffect{Kind: phaseWeather, Name: "Snowscape", Params: PhaseParams{Weather: "hail"}}},
+		{"Sunny Day⭐️", PhaseEffect{Kind: phaseWeather, Name: "Sunny Day⭐️", Params: PhaseParams{Weather: "sun"}}},
+		{"Light Screen", PhaseEffect{Kind: phaseScreen, Name: "Light Screen", Params: PhaseParams{Screen: "light_screen"}}},
+		{"Sing", PhaseEffect{Kind: phaseStatus, Name: "Sing", Params: PhaseParams{Status: "sleep"}}},
+		{"Acid Armor [+2 defense]⭐️", PhaseEffect{Kind: phaseStatChange, Name: "Acid Armor ⭐️", Params: PhaseParams{Stats: map[string]int{"defense": 2}}, Description: "+2 defense"}},
+		{"[Boss Boost] [Omniboost]", PhaseEffect{Kind: phaseStatChange, Name: "Boss Boost", Params: PhaseParams{Stats: map[string]int{"attack": 1, "defense": 1, "sp_attack": 1, "sp_defense": 1, "speed": 1}}, Description: "Omniboost"}},
+		{"Adaptation [Changes type to resist]", PhaseEffect{Kind: phaseAdaptation, Name: "Adaptation", Description: "Changes type to resist"}},
+		{"Wish", PhaseEffect{Kind: phaseHeal, Name: "Wish", Params: PhaseParams{Heal: 50}}},
+		{"Rainbow [secondary effects [Rainer]]", PhaseEffect{Kind: phaseCustom, Name: "Rainbow", Description: "secondary effects [Rainer]"}},
+		{"[Roar⭐️]", PhaseEffect{Kind: phaseCustom, Name: "Roar⭐️"}},
+		{"Trick Room", PhaseEffect{Kind: phaseCustom, Name: "Trick Room"}},
+	}
+	for _, tt := range tests {
+		if got := parsePhaseEffect(tt.text); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q: got %+v, want %+v", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestLegacyPhaseEffectDecoding(t *testing.T) {
+	want := []PhaseEffect{
+		{Health: 66, Kind: phaseScreen, Name: "Reflect", Params: PhaseParams{Screen: "reflect"}},
+		{Health: 50, Kind: phaseCustom, Name: "Trick Room"},
+	}
+	raw := `[{"health": 66, "effect": "Reflect"}, {"health": 50, "kind": "custom", "name": "Trick Room"}]`
+
+	var fromJSON []PhaseEffect
+	if err := json.Unmarshal([]byte(raw), &fromJSON); err != nil {
+		t.Fatal(err)
+	}
+	seasons := []Season{{RaidBosses: []RaidBoss{{PhaseEffects: fromJSON}}}}
+	if !migratePhaseEffects(seasons) || migratePhaseEffects(seasons) {
+		t.Error("legacy effect should need migrating exactly once")
+	}
+	if !reflect.DeepEqual(seasons[0].RaidBosses[0].PhaseEffects, want) {
+		t.Errorf("json: got %+v", fromJSON)
+	}
+
+	doc, _ := bson.Marshal(bson.M{"phase_effects": bson.A{bson.M{"health": 66, "effect": "Reflect"}}})
+	var fromBSON RaidBoss
+	if err := bson.Unmarshal(doc, &fromBSON); err != nil {
+		t.Fatal(err)
+	}
+	if got := fromBSON.PhaseEffects; len(got) != 1 || got[0].Kind != phaseScreen || !got[0].legacy {
+		t.Errorf("bson: got %+v", got)
+	}
+}
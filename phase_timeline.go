@@ -0,0 +1,91 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// PhaseTrigger is when a boss phase effect fires during a variation
+type PhaseTrigger struct {
+	Health    int    `json:"health"`
+	Effect    string `json:"effect"`
+	Kind      string `json:"kind"`
+	AfterTurn int    `json:"after_turn"` // 0 when active from the start
+	Reached   bool   `json:"reached"`    // false when the variation never brings the boss that low
+	Message   string `json:"message"`
+}
+
+// phaseTimeline works out from a variation's claimed boss health which turn
+// crosses each phase threshold. A phase fires once the boss is at or below its
+// health, so a 100% phase is active from the start.
+func phaseTimeline(effects []PhaseEffect, health []float64) []PhaseTrigger {
+	triggers := make([]PhaseTrigger, 0, len(effects))
+	for _, pe := range effects {
+		name := strings.TrimSpace(pe.Name)
+		t := PhaseTrigger{Health: pe.Health, Effect: name, Kind: pe.Kind}
+		if pe.Health >= 100 {
+			t.Reached = true
+			t.Message = fmt.Sprintf("%s is active from the start", name)
+		} else {
+			for i, hp := range health {
+				if hp <= float64(pe.Health) {
+					t.Reached, t.AfterTurn = true, i+1
+					t.Message = fmt.Sprintf("%s triggers after turn %d (%d%%)", name, t.AfterTurn, pe.Health)
+					break
+				}
+			}
+			if !t.Reached {
+				t.Message = fmt.Sprintf("%s at %d%% is not reached", name, pe.Health)
+			}
+		}
+		triggers = append(triggers, t)
+	}
+	return triggers
+}
+
+// variationView is a variation as served by the boss API, with its phase triggers
+type variationView struct {
+	Variation
+	PhaseTriggers []PhaseTrigger `json:"phase_triggers"`
+}
+
+// bossView is a boss as served by the boss API
+type bossView struct {
+	RaidBoss
+	Variations []variationView `json:"variations"`
+}
+
+// newBossView adds each variation's phase triggers to a boss for the API
+func newBossView(b RaidBoss) bossView {
+	out := bossView{RaidBoss: b, Variations: make([]variationView, 0, len(b.Variations))}
+	for _, v := range b.Variations {
+		triggers := v.PhaseTriggers
+		if triggers == nil {
+			triggers = phaseTimeline(b.PhaseEffects, v.HealthRemaining)
+		}
+		out.Variations = append(out.Variations, variationView{Variation: v, PhaseTriggers: triggers})
+	}
+	return out
+}
+
+// bossAPIHandler returns a boss of the current season by id or name, with the
+// turn each phase effect fires in every variation
+func (a *App) bossAPIHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	current := a.store.Current()
+	boss := findBossByID(&current, r.URL.Query().Get("id"))
+	if boss == nil {
+		boss = findBossIn(&current, r.URL.Query().Get("name"))
+	}
+	if boss == nil {
+		http.Error(w, "boss not found", http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(newBossView(*boss))
+}
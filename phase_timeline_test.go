@@ -0,0 +1,26 @@
+package main
+
+import "testing"
+
+func TestPhaseTimeline(t *testing.T) {
+	effects := []PhaseEffect{
+		testPhase(100, "Snowscape"),
+		testPhase(66, "Reflect"),
+		testPhase(25, "Sing"),
+		testPhase(10, "Agility"),
+	}
+	got := phaseTimeline(effects, []float64{80, 66, 40, 20})
+
+	want := []struct {
+		afterTurn int
+		reached   bool
+	}{{0, true}, {2, true}, {4, true}, {0, false}}
+	for i, w := range want {
+		if got[i].AfterTurn != w.afterTurn || got[i].Reached != w.reached {
+			t.Errorf("%s: got after turn %d reached %v, want %d %v", got[i].Effect, got[i].AfterTurn, got[i].Reached, w.afterTurn, w.reached)
+		}
+	}
+	if got[2].Message != "Sing triggers after turn 4 (25%)" {
+		t.Errorf("message %q", got[2].Message)
+	}
+}
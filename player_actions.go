@@ -0,0 +1,140 @@
+package main
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// Player action kinds
+const (
+	actionMove   = "move"
+	actionSwitch = "switch"
+	actionItem   = "item"
+	actionPass   = "pass"
+)
+
+var playerActions = []string{actionMove, actionSwitch, actionItem, actionPass}
+
+// actionTargets are the targets a player action may have; empty means unspecified
+var actionTargets = []string{"boss", "P1", "P2", "P3", "P4", "add"}
+
+// Player is one player's action in a turn of a variation
+type Player struct {
+	Pokemon string `json:"pokemon" bson:"pokemon"`
+	Action  string `json:"action" bson:"action"` // one of playerActions
+	Move    string `json:"move" bson:"move"`     // move name from moves.json, for move actions
+	Target  string `json:"target,omitempty" bson:"target,omitempty"`
+	Item    string `json:"item" bson:"item"` // held item, or the item used for item actions
+	Note    string `json:"note,omitempty" bson:"note,omitempty"`
+}
+
+// playerFields is Player without its methods
+type playerFields struct {
+	Pokemon string `json:"pokemon" bson:"pokemon"`
+	Action  string `json:"action" bson:"action"`
+	Move    string `json:"move" bson:"move"`
+	Target  string `json:"target,omitempty" bson:"target,omitempty"`
+	Item    string `json:"item" bson:"item"`
+	Note    string `json:"note,omitempty" bson:"note,omitempty"`
+}
+
+func (p *Player) fromFields(f playerFields) {
+	*p = Player(f)
+	if p.Action == "" {
+		p.Action, p.Move, p.Target, p.Note = parseLegacyMove(f.Move, f.Note)
+	}
+}
+
+// UnmarshalJSON accepts both structured actions and the old {"pokemon", "move",
+// "item"} form, where the move is free text such as "skill swap cloud 9 onto Add"
+func (p *Player) UnmarshalJSON(data []byte) error {
+	var f playerFields
+	if err := json.Unmarshal(data, &f); err != nil {
+		return err
+	}
+	p.fromFields(f)
+	return nil
+}
+
+// UnmarshalBSON accepts both structured actions and the old free-text move form
+func (p *Player) UnmarshalBSON(data []byte) error {
+	var f playerFields
+	if err := bson.Unmarshal(data, &f); err != nil {
+		return err
+	}
+	p.fromFields(f)
+	return nil
+}
+
+var (
+	legacyTargetPattern = regexp.MustCompile(`(?i)\s+(?:onto|on|into|at|to)\s+(?:the\s+)?(add|boss|(?:ally\s*)?p?[1-4])\s*$`)
+	legacySwitchPattern = regexp.MustCompile(`(?i)^switch(?:\s+(?:in|out|to|into))?\b\s*`)
+)
+
+// legacyPasses are free-text moves that mean the player does nothing
+var legacyPasses = map[string]bool{"": true, "pass": true, "skip": true, "nothing": true, "none": true, "wait": true}
+
+// parseLegacyMove splits an old free-text move into an action, move, target and
+// note. Moves are kept as written; they are linked to moves.json when saved.
+func parseLegacyMove(text, note string) (action, move, target, rest string) {
+	text = strings.TrimSpace(text)
+	if legacyPasses[squashName(text)] {
+		return actionPass, "", "", note
+	}
+	if loc := legacySwitchPattern.FindStringIndex(text); loc != nil {
+		return actionSwitch, "", "", joinNotes(text[loc[1]:], note)
+	}
+	if m := legacyTargetPattern.FindStringSubmatchIndex(text); m != nil {
+		target = normalizeTarget(text[m[2]:m[3]])
+		text = strings.TrimSpace(text[:m[0]])
+	}
+	return actionMove, text, target, note
+}
+
+// normalizeTarget maps "Add", "ally p2" or "2" to a value of actionTargets
+func normalizeTarget(s string) string {
+	s = strings.ToLower(strings.Join(strings.Fields(s), ""))
+	switch s {
+	case "add", "boss":
+		return s
+	}
+	s = strings.TrimPrefix(s, "ally")
+	return "P" + strings.TrimPrefix(s, "p")
+}
+
+func joinNotes(notes ...string) string {
+	var parts []string
+	for _, n := range notes {
+		if n = strings.TrimSpace(n); n != "" {
+			parts = append(parts, n)
+		}
+	}
+	return strings.Join(parts, "; ")
+}
+
+// linkPlayer links a move written with extra words, e.g. "Prankster Tailwind",
+// to the move it names in moves.json and keeps the other words as a note
+func (g *gameData) linkPlayer(p Player) Player {
+	if p.Action != actionMove || !g.moves.available() {
+		return p
+	}
+	if _, ok := g.moves.resolve(strings.Split(p.Move, "/")[0]); ok {
+		return p
+	}
+	if name, rest, ok := g.moves.within(p.Move); ok {
+		p.Move, p.Note = name, joinNotes(rest, p.Note)
+	}
+	return p
+}
+
+// linkVariation links the moves of every player action in v
+func (g *gameData) linkVariation(v *Variation) {
+	for pos, lane := range v.Players {
+		for t := range lane {
+			v.Players[pos][t] = g.linkPlayer(lane[t])
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestLegacyPlayerDecoding(t *testing.T) {
+	raw := `[
+		{"pokemon": "Whimsicott", "move": "skill swap cloud 9 onto Add", "item": ""},
+		{"pokemon": "Snorlax", "move": "Switch in", "item": "Leftovers"},
+		{"pokemon": "", "move": "", "item": ""},
+		{"pokemon": "Golduck", "action": "move", "move": "Surf", "target": "boss", "note": "after Rain Dance"}
+	]`
+	var got []Player
+	if err := json.Unmarshal([]byte(raw), &got); err != nil {
+		t.Fatal(err)
+	}
+	want := []Player{
+		{Pokemon: "Whimsicott", Action: actionMove, Move: "skill swap cloud 9", Target: "add"},
+		{Pokemon: "Snorlax", Action: actionSwitch, Item: "Leftovers"},
+		{Action: actionPass},
+		{Pokemon: "Golduck", Action: actionMove, Move: "Surf", Target: "boss", Note: "after Rain Dance"},
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("%d: got %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestLinkPlayer(t *testing.T) {
+	g := &gameData{moves: newNameSet([]string{"Skill Swap", "Tailwind", "Overheat", "Fiery Dance"})}
+	tests := []struct {
+		move, wantMove, wantNote string
+	}{
+		{"skill swap cloud 9", "Skill Swap", "cloud 9"},
+		{"Prankster Tailwind", "Tailwind", "Prankster"},
+		{"Overheat/Fiery Dance", "Overheat/Fiery Dance", ""},
+		{"Throwaway", "Throwaway", ""},
+	}
+	for _, tt := range tests {
+		p := g.linkPlayer(Player{Action: actionMove, Move: tt.move})
+		if p.Move != tt.wantMove || p.Note != tt.wantNote {
+			t.Errorf("%q: got move %q note %q", tt.move, p.Move, p.Note)
+		}
+	}
+}
@@ -0,0 +1,408 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// raidSessionTTL is how long a live raid session is kept after its last update
+	raidSessionTTL = func() time.Duration {
+		d, err := time.ParseDuration(getEnvOrDefault("RAID_SESSION_TTL", "2h"))
+		if err != nil || d <= 0 {
+			return 2 * time.Hour
+		}
+		return d
+	}()
+
+	errSessionNotFound = errors.New("session not found or expired")
+)
+
+// raidSessionLimiter limits how many sessions a user, or a visitor's IP, can
+// open per hour
+var raidSessionLimiter = newRateLimiter(10, time.Hour)
+
+// raidSessionHeartbeat keeps idle event streams open through proxies
+const raidSessionHeartbeat = 25 * time.Second
+
+// Raid session event types; "state" carries the whole session
+const (
+	sessionEventState  = "state"
+	sessionEventCheck  = "check"
+	sessionEventNote   = "note"
+	sessionEventHealth = "health"
+)
+
+// RaidSession is the shared progress of a group running a variation together.
+// Checks are keyed by "player-turn" with 0-based indexes, notes and actual boss
+// HP by 0-based turn.
+type RaidSession struct {
+	Code        string          `json:"code"`
+	Season      string          `json:"season"`
+	BossID      string          `json:"boss_id"`
+	BossName    string          `json:"boss_name"`
+	VariationID string          `json:"variation_id"`
+	Turns       int             `json:"turns"`
+	Host        string          `json:"host"`
+	Checks      map[string]bool `json:"checks"`
+	Notes       map[int]string  `json:"notes"`
+	Health      map[int]float64 `json:"health"`
+	Version     int             `json:"version"` // bumped on every update
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+}
+
+// RaidSessionEvent is one change to a session, broadcast to every participant
+type RaidSessionEvent struct {
+	Type    string       `json:"type"`
+	Player  int          `json:"player"`
+	Turn    int          `json:"turn"`
+	Checked bool         `json:"checked,omitempty"`
+	Note    string       `json:"note,omitempty"`
+	Health  *float64     `json:"health,omitempty"` // nil clears the recorded HP
+	By      string       `json:"by,omitempty"`
+	Version int          `json:"version"`
+	Session *RaidSession `json:"session,omitempty"` // for state events
+}
+
+// clone returns a deep copy of s safe to encode outside the hub's lock
+func (s *RaidSession) clone() RaidSession {
+	c := *s
+	c.Checks = make(map[string]bool, len(s.Checks))
+	for k, v := range s.Checks {
+		c.Checks[k] = v
+	}
+	c.Notes = make(map[int]string, len(s.Notes))
+	for k, v := range s.Notes {
+		c.Notes[k] = v
+	}
+	c.Health = make(map[int]float64, len(s.Health))
+	for k, v := range s.Health {
+		c.Health[k] = v
+	}
+	return c
+}
+
+// apply validates an event against the session and records it
+func (s *RaidSession) apply(e *RaidSessionEvent) error {
+	if e.Turn < 0 || e.Turn >= s.Turns {
+		return fmt.Errorf("turn must be between 0 and %d", s.Turns-1)
+	}
+	switch e.Type {
+	case sessionEventCheck:
+		if e.Player < 0 || e.Player >= maxPlayers {
+			return fmt.Errorf("player must be between 0 and %d", maxPlayers-1)
+		}
+		key := fmt.Sprintf("%d-%d", e.Player, e.Turn)
+		if e.Checked {
+			s.Checks[key] = true
+		} else {
+			delete(s.Checks, key)
+		}
+	case sessionEventNote:
+		e.Note = strings.TrimSpace(e.Note)
+		if len(e.Note) > 500 {
+			return errors.New("note is too long")
+		}
+		if e.Note == "" {
+			delete(s.Notes, e.Turn)
+		} else {
+			s.Notes[e.Turn] = e.Note
+		}
+	case sessionEventHealth:
+		if e.Health == nil {
+			delete(s.Health, e.Turn)
+		} else if *e.Health < 0 || *e.Health > 100 {
+			return errors.New("health must be between 0 and 100")
+		} else {
+			s.Health[e.Turn] = *e.Health
+		}
+	default:
+		return errors.New("type must be check, note or health")
+	}
+	s.Version++
+	s.UpdatedAt = time.Now()
+	e.Version = s.Version
+	return nil
+}
+
+// liveSession is a session and the event streams of its participants
+type liveSession struct {
+	state RaidSession
+	subs  map[chan RaidSessionEvent]struct{}
+}
+
+// raidSessionHub keeps live raid sessions in memory; sessions are dropped after
+// raidSessionTTL without updates
+type raidSessionHub struct {
+	mu       sync.Mutex
+	sessions map[string]*liveSession
+}
+
+func newRaidSessionHub() *raidSessionHub {
+	h := &raidSessionHub{sessions: make(map[string]*liveSession)}
+	go h.expireLoop()
+	return h
+}
+
+// sessionCodeAlphabet leaves out characters easily confused when read out on a call
+const sessionCodeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+func newSessionCode() string {
+	b := make([]byte, 6)
+	rand.Read(b)
+	for i := range b {
+		b[i] = sessionCodeAlphabet[int(b[i])%len(sessionCodeAlphabet)]
+	}
+	return string(b)
+}
+
+// Create opens a session and returns a copy of it
+func (h *raidSessionHub) Create(s RaidSession) RaidSession {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for {
+		s.Code = newSessionCode()
+		if _, taken := h.sessions[s.Code]; !taken {
+			break
+		}
+	}
+	s.Checks, s.Notes, s.Health = map[string]bool{}, map[int]string{}, map[int]float64{}
+	s.CreatedAt = time.Now()
+	s.UpdatedAt = s.CreatedAt
+	h.sessions[s.Code] = &liveSession{state: s, subs: make(map[chan RaidSessionEvent]struct{})}
+	return s.clone()
+}
+
+// Get returns a copy of a session
+func (h *raidSessionHub) Get(code string) (RaidSession, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	ls, ok := h.sessions[strings.ToUpper(code)]
+	if !ok {
+		return RaidSession{}, errSessionNotFound
+	}
+	return ls.state.clone(), nil
+}
+
+// Apply records an event and broadcasts it to the session's participants.
+// Participants too slow to keep up are sent the whole state instead.
+func (h *raidSessionHub) Apply(code string, e RaidSessionEvent) (RaidSessionEvent, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	ls, ok := h.sessions[strings.ToUpper(code)]
+	if !ok {
+		return e, errSessionNotFound
+	}
+	if err := ls.state.apply(&e); err != nil {
+		return e, err
+	}
+	for ch := range ls.subs {
+		select {
+		case ch <- e:
+		default:
+			// drop the queued events and resynchronise with the full state
+			drain(ch)
+			state := ls.state.clone()
+			ch <- RaidSessionEvent{Type: sessionEventState, Version: state.Version, Session: &state}
+		}
+	}
+	return e, nil
+}
+
+func drain(ch chan RaidSessionEvent) {
+	for {
+		select {
+		case <-ch:
+		default:
+			return
+		}
+	}
+}
+
+// Subscribe returns a stream of a session's events, starting with its state.
+// The stream is closed when the session expires; call cancel to stop listening.
+func (h *raidSessionHub) Subscribe(code string) (<-chan RaidSessionEvent, func(), error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	ls, ok := h.sessions[strings.ToUpper(code)]
+	if !ok {
+		return nil, nil, errSessionNotFound
+	}
+	ch := make(chan RaidSessionEvent, 32)
+	state := ls.state.clone()
+	ch <- RaidSessionEvent{Type: sessionEventState, Version: state.Version, Session: &state}
+	ls.subs[ch] = struct{}{}
+	cancel := func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		if _, ok := ls.subs[ch]; ok {
+			delete(ls.subs, ch)
+			close(ch)
+		}
+	}
+	return ch, cancel, nil
+}
+
+// expire drops sessions idle since before cutoff and closes their streams
+func (h *raidSessionHub) expire(cutoff time.Time) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for code, ls := range h.sessions {
+		if ls.state.UpdatedAt.Before(cutoff) {
+			for ch := range ls.subs {
+				close(ch)
+			}
+			ls.subs = nil
+			delete(h.sessions, code)
+		}
+	}
+}
+
+func (h *raidSessionHub) expireLoop() {
+	for range time.Tick(time.Minute) {
+		h.expire(time.Now().Add(-raidSessionTTL))
+	}
+}
+
+// raidSessionHandler returns a session by code (GET), used by clients without
+// Server-Sent Events, or opens one for a variation of the current season (POST)
+func (a *App) raidSessionHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		s, err := a.sessions.Get(r.URL.Query().Get("code"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(s)
+	case http.MethodPost:
+		var req struct {
+			BossID      string `json:"boss_id"`
+			VariationID string `json:"variation_id"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "invalid request body", http.StatusBadRequest)
+			return
+		}
+		current := a.store.Current()
+		boss := findBossByID(&current, req.BossID)
+		if boss == nil {
+			http.Error(w, errBossNotFound.Error(), http.StatusNotFound)
+			return
+		}
+		i := findVariationIndex(boss, req.VariationID)
+		if i < 0 {
+			http.Error(w, errVariationNotFound.Error(), http.StatusNotFound)
+			return
+		}
+		host := getUsernameFromRequest(r)
+		key := host
+		if key == "" {
+			key = "ip:" + clientIP(r, trustedProxies)
+		}
+		if !raidSessionLimiter.Allow(key) {
+			http.Error(w, "too many sessions, try again later", http.StatusTooManyRequests)
+			return
+		}
+		s := a.sessions.Create(RaidSession{
+			Season:      a.getSeasonName(),
+			BossID:      boss.ID,
+			BossName:    boss.Name,
+			VariationID: req.VariationID,
+			Turns:       len(boss.Variations[i].HealthRemaining),
+			Host:        host,
+		})
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(s)
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// raidSessionUpdateHandler records a checked turn cell, a note or the actual
+// boss HP of a turn and broadcasts it to the session
+func (a *App) raidSessionUpdateHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var req struct {
+		Code string `json:"code"`
+		RaidSessionEvent
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	e := req.RaidSessionEvent
+	e.By, e.Session = getUsernameFromRequest(r), nil
+	e, err := a.sessions.Apply(req.Code, e)
+	if errors.Is(err, errSessionNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	} else if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(e)
+}
+
+// raidSessionEventsHandler streams a session's events as Server-Sent Events
+func (a *App) raidSessionEventsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+	events, cancel, err := a.sessions.Subscribe(r.URL.Query().Get("code"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	defer cancel()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no") // stop nginx from buffering the stream
+
+	heartbeat := time.NewTicker(raidSessionHeartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": ping\n\n")
+			flusher.Flush()
+		case e, ok := <-events:
+			if !ok {
+				// the session expired
+				fmt.Fprint(w, "event: expired\ndata: {}\n\n")
+				flusher.Flush()
+				return
+			}
+			data, err := json.Marshal(e)
+			if err != nil {
+				log.Printf("Error encoding session event: %v", err)
+				continue
+			}
+			fmt.Fprintf(w, "event: %s\ndata: %s\n\n", e.Type, data)
+			flusher.Flush()
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRaidSessionHub(t *testing.T) {
+	h := &raidSessionHub{sessions: make(map[string]*liveSession)}
+	s := h.Create(RaidSession{BossID: "b1", VariationID: "v1", Turns: 3})
+
+	events, cancel, err := h.Subscribe(s.Code)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cancel()
+	if e := <-events; e.Type != sessionEventState || e.Session.Code != s.Code {
+		t.Fatalf("first event: %+v", e)
+	}
+
+	if _, err := h.Apply(s.Code, RaidSessionEvent{Type: sessionEventCheck, Player: 1, Turn: 2, Checked: true}); err != nil {
+		t.Fatal(err)
+	}
+	hp := 42.5
+	if _, err := h.Apply(s.Code, RaidSessionEvent{Type: sessionEventHealth, Turn: 0, Health: &hp}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := h.Apply(s.Code, RaidSessionEvent{Type: sessionEventNote, Turn: 3, Note: "late"}); err == nil {
+		t.Fatal("turn out of range accepted")
+	}
+	if e := <-events; e.Type != sessionEventCheck || e.Version != 1 {
+		t.Fatalf("check event: %+v", e)
+	}
+	if e := <-events; e.Type != sessionEventHealth || *e.Health != hp || e.Version != 2 {
+		t.Fatalf("health event: %+v", e)
+	}
+
+	got, err := h.Get(s.Code)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.Checks["1-2"] || got.Health[0] != hp || got.Version != 2 {
+		t.Fatalf("state: %+v", got)
+	}
+
+	h.expire(time.Now().Add(time.Minute))
+	if _, ok := <-events; ok {
+		t.Fatal("stream not closed when the session expired")
+	}
+	if _, err := h.Get(s.Code); err != errSessionNotFound {
+		t.Fatalf("got %v, want errSessionNotFound", err)
+	}
+}
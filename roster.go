@@ -0,0 +1,238 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Roster statuses of a variation
+const (
+	rosterReady    = "ready"     // every Pokémon of at least one position is owned
+	rosterOneShort = "one_short" // the best position is missing exactly one Pokémon
+	rosterMissing  = "missing"
+)
+
+// RosterPosition is what a user is missing to play one position of a variation
+type RosterPosition struct {
+	Position string   `json:"position"`
+	Pokemon  []string `json:"pokemon"`
+	Missing  []string `json:"missing"`
+}
+
+// RosterVariation is how close a user is to fielding a variation
+type RosterVariation struct {
+	VariationID string           `json:"variation_id"`
+	Variation   int              `json:"variation"` // 1-based, as shown on the boss page
+	Status      string           `json:"status"`
+	Best        RosterPosition   `json:"best"` // the position with the fewest missing Pokémon
+	Positions   []RosterPosition `json:"positions"`
+}
+
+// RosterBoss lists the variations of a boss a user can field or is one Pokémon short of
+type RosterBoss struct {
+	BossID   string            `json:"boss_id"`
+	BossName string            `json:"boss_name"`
+	Ready    []RosterVariation `json:"ready"`
+	OneShort []RosterVariation `json:"one_short"`
+}
+
+// RosterUnlock is a Pokémon that would make variations fieldable
+type RosterUnlock struct {
+	Pokemon    string               `json:"pokemon"`
+	Count      int                  `json:"count"`
+	Variations []RosterVariationRef `json:"variations"`
+}
+
+// RosterVariationRef points to a variation of a boss
+type RosterVariationRef struct {
+	BossID      string `json:"boss_id"`
+	BossName    string `json:"boss_name"`
+	VariationID string `json:"variation_id"`
+	Variation   int    `json:"variation"`
+}
+
+// RosterReport matches a user's Pokémon against a season's variations
+type RosterReport struct {
+	Season  string         `json:"season"`
+	User    string         `json:"user,omitempty"`
+	Owned   []string       `json:"owned"`
+	Bosses  []RosterBoss   `json:"bosses"`
+	Unlocks []RosterUnlock `json:"unlocks"`
+}
+
+// positionRoster returns the distinct Pokémon a position uses, in order of first use
+func positionRoster(lane []Player) []string {
+	seen := map[string]bool{}
+	var names []string
+	for _, p := range lane {
+		key := squashName(p.Pokemon)
+		if key == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		names = append(names, strings.TrimSpace(p.Pokemon))
+	}
+	return names
+}
+
+// matchVariation reports which Pokémon are missing for each position of v.
+// Each raid player brings their own team, so a user can field a variation when
+// they own every Pokémon of one position. Positions without Pokémon are skipped.
+func matchVariation(v Variation, owned map[string]bool) RosterVariation {
+	rv := RosterVariation{VariationID: v.ID, Status: rosterMissing, Positions: []RosterPosition{}}
+	positions := make([]string, 0, len(v.Players))
+	for pos := range v.Players {
+		positions = append(positions, pos)
+	}
+	sort.Strings(positions)
+	for _, pos := range positions {
+		roster := positionRoster(v.Players[pos])
+		if len(roster) == 0 {
+			continue
+		}
+		rp := RosterPosition{Position: pos, Pokemon: roster, Missing: []string{}}
+		for _, name := range roster {
+			if !owned[squashName(name)] {
+				rp.Missing = append(rp.Missing, name)
+			}
+		}
+		if len(rv.Positions) == 0 || len(rp.Missing) < len(rv.Best.Missing) {
+			rv.Best = rp
+		}
+		rv.Positions = append(rv.Positions, rp)
+	}
+	switch {
+	case len(rv.Positions) == 0:
+	case len(rv.Best.Missing) == 0:
+		rv.Status = rosterReady
+	case len(rv.Best.Missing) == 1:
+		rv.Status = rosterOneShort
+	}
+	return rv
+}
+
+// matchRoster matches owned Pokémon against every variation of a season. Pokémon
+// are ranked by how many variations, not yet fieldable, they would complete a
+// position of.
+func matchRoster(season Season, owned []string) RosterReport {
+	have := make(map[string]bool, len(owned))
+	for _, name := range owned {
+		have[squashName(name)] = true
+	}
+	report := RosterReport{Season: season.SeasonName, Owned: owned, Bosses: []RosterBoss{}, Unlocks: []RosterUnlock{}}
+	unlocks := map[string]*RosterUnlock{}
+	for _, boss := range season.RaidBosses {
+		rb := RosterBoss{BossID: boss.ID, BossName: boss.Name, Ready: []RosterVariation{}, OneShort: []RosterVariation{}}
+		for i, v := range boss.Variations {
+			rv := matchVariation(v, have)
+			rv.Variation = i + 1
+			switch rv.Status {
+			case rosterReady:
+				rb.Ready = append(rb.Ready, rv)
+				continue
+			case rosterOneShort:
+				rb.OneShort = append(rb.OneShort, rv)
+			}
+			// a Pokémon unlocks the variation if it is the only one a position lacks
+			counted := map[string]bool{}
+			for _, rp := range rv.Positions {
+				if len(rp.Missing) != 1 {
+					continue
+				}
+				name := rp.Missing[0]
+				key := squashName(name)
+				if counted[key] {
+					continue
+				}
+				counted[key] = true
+				u := unlocks[key]
+				if u == nil {
+					u = &RosterUnlock{Pokemon: name}
+					unlocks[key] = u
+				}
+				u.Count++
+				u.Variations = append(u.Variations, RosterVariationRef{BossID: boss.ID, BossName: boss.Name, VariationID: v.ID, Variation: i + 1})
+			}
+		}
+		report.Bosses = append(report.Bosses, rb)
+	}
+	for _, u := range unlocks {
+		report.Unlocks = append(report.Unlocks, *u)
+	}
+	sort.Slice(report.Unlocks, func(i, j int) bool {
+		if report.Unlocks[i].Count != report.Unlocks[j].Count {
+			return report.Unlocks[i].Count > report.Unlocks[j].Count
+		}
+		return report.Unlocks[i].Pokemon < report.Unlocks[j].Pokemon
+	})
+	return report
+}
+
+// completedPokemon returns the names of the completed entries of a checklist
+func completedPokemon(entries []PokemonChecklistEntry) []string {
+	seen := map[string]bool{}
+	names := []string{}
+	for _, p := range entries {
+		if p.Completed && !seen[p.Name] {
+			seen[p.Name] = true
+			names = append(names, p.Name)
+		}
+	}
+	return names
+}
+
+// rosterHandler matches the user's completed checklist Pokémon against the
+// current season's variations. Signed-in users are matched with their server
+// checklist; other visitors pass their Pokémon as ?pokemon=A,B. ?boss_id= limits
+// the bosses listed, not the unlock ranking.
+func (a *App) rosterHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	season := a.store.Current()
+	username := getUsernameFromRequest(r)
+
+	owned := []string{}
+	if username != "" {
+		if a.mongoDB == nil {
+			http.Error(w, "checklists unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		doc, err := a.userChecklist(ctx, a.getSeasonName(), username)
+		if err != nil && err != mongo.ErrNoDocuments {
+			http.Error(w, "failed to load checklist", http.StatusInternalServerError)
+			return
+		}
+		owned = completedPokemon(doc.Pokemon)
+	} else if list := r.URL.Query().Get("pokemon"); list != "" {
+		for _, name := range strings.Split(list, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				owned = append(owned, name)
+			}
+		}
+	}
+
+	report := matchRoster(season, owned)
+	report.User = username
+	if bossID := r.URL.Query().Get("boss_id"); bossID != "" {
+		bosses := []RosterBoss{}
+		for _, b := range report.Bosses {
+			if b.BossID == bossID {
+				bosses = append(bosses, b)
+			}
+		}
+		report.Bosses = bosses
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
@@ -0,0 +1,45 @@
+package main
+
+import "testing"
+
+func rosterLane(pokemon ...string) []Player {
+	lane := make([]Player, len(pokemon))
+	for i, p := range pokemon {
+		lane[i] = Player{Pokemon: p, Action: actionMove}
+	}
+	return lane
+}
+
+func TestMatchRoster(t *testing.T) {
+	season := Season{SeasonName: "Winter", RaidBosses: []RaidBoss{{
+		ID:   "b1",
+		Name: "Gyarados",
+		Variations: []Variation{
+			{ID: "v1", Players: map[string][]Player{
+				"P1": rosterLane("Jolteon", "Jolteon"),
+				"P2": rosterLane("Whimsicott", "Snorlax"),
+			}},
+			{ID: "v2", Players: map[string][]Player{
+				"P1": rosterLane("Ferrothorn", "Whimsicott"),
+				"P2": rosterLane("Garchomp", "Rotom-Wash"),
+			}},
+			{ID: "v3", Players: map[string][]Player{
+				"P1": rosterLane("Ferrothorn", "Volcarona"),
+				"P2": rosterLane("Ferrothorn"),
+			}},
+		},
+	}}}
+
+	report := matchRoster(season, []string{"jolteon", "Whimsicott"})
+	boss := report.Bosses[0]
+	if len(boss.Ready) != 1 || boss.Ready[0].VariationID != "v1" || boss.Ready[0].Best.Position != "P1" {
+		t.Fatalf("ready: %+v", boss.Ready)
+	}
+	if len(boss.OneShort) != 2 || boss.OneShort[0].VariationID != "v2" || boss.OneShort[1].Best.Position != "P2" {
+		t.Fatalf("one short: %+v", boss.OneShort)
+	}
+	// Ferrothorn completes a position of v2 and v3, counted once per variation
+	if len(report.Unlocks) == 0 || report.Unlocks[0].Pokemon != "Ferrothorn" || report.Unlocks[0].Count != 2 {
+		t.Fatalf("unlocks: %+v", report.Unlocks)
+	}
+}
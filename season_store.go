@@ -0,0 +1,281 @@
+package main
+
+import (
+	"errors"
+	"maps"
+	"slices"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var (
+	errSeasonNotFound    = errors.New("season not found")
+	errSeasonExists      = errors.New("season already exists")
+	errBossNotFound      = errors.New("boss not found")
+	errVariationNotFound = errors.New("variation not found")
+)
+
+// revisionConflictError is returned when an edit was based on a stale revision.
+// Current holds the server copy (a RaidBoss or Variation) the client should merge with.
+type revisionConflictError struct {
+	Current interface{}
+}
+
+func (e *revisionConflictError) Error() string {
+	return "revision conflict"
+}
+
+// SeasonTx is the mutable view handed to SeasonStore.Update. Seasons is a
+// private deep copy, so edits are invisible to readers until Update publishes them.
+type SeasonTx struct {
+	Seasons []Season
+	Default string // code form e.g. "christmas_2024"
+}
+
+// SeasonStore owns the raid season data. Readers receive immutable snapshots
+// and writers publish copy-on-write replacements under a lock, so a page render
+// never observes a half-applied admin edit.
+type SeasonStore struct {
+	mu          sync.RWMutex
+	seasons     []Season
+	defaultCode string
+	current     Season // preprocessed copy of the public season
+
+	prepare func(*Season) // builds render-only fields for the current season
+}
+
+// newSeasonStore creates a store over seasons; prepare may be nil
+func newSeasonStore(seasons []Season, prepare func(*Season)) *SeasonStore {
+	s := &SeasonStore{seasons: seasons, prepare: prepare}
+	s.refreshCurrent()
+	return s
+}
+
+// refreshCurrent derives the public season: the default if set, otherwise the first one.
+// Callers must hold the write lock.
+func (s *SeasonStore) refreshCurrent() {
+	cur := Season{}
+	if len(s.seasons) > 0 {
+		cur = s.seasons[0]
+	}
+	for _, season := range s.seasons {
+		if s.defaultCode != "" && seasonCode(season) == s.defaultCode {
+			cur = season
+			break
+		}
+	}
+	cur = cur.clone()
+	if s.prepare != nil {
+		s.prepare(&cur)
+	}
+	s.current = cur
+}
+
+// Seasons returns a snapshot of all seasons. The result is shared and must not be modified.
+func (s *SeasonStore) Seasons() []Season {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.seasons
+}
+
+// Current returns the season shown on public pages
+func (s *SeasonStore) Current() Season {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.current
+}
+
+// Default returns the configured default season code, or empty if none is set
+func (s *SeasonStore) Default() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.defaultCode
+}
+
+// FindSeason looks up a season by code
+func (s *SeasonStore) FindSeason(code string) (Season, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if i := findSeasonIndex(s.seasons, code); i >= 0 {
+		return s.seasons[i], true
+	}
+	return Season{}, false
+}
+
+// FindBoss searches the current season for a boss by name
+func (s *SeasonStore) FindBoss(name string) (RaidBoss, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, b := range s.current.RaidBosses {
+		if b.Name == name {
+			return b, true
+		}
+	}
+	return RaidBoss{}, false
+}
+
+// SetDefault switches the public season without touching bosses.json
+func (s *SeasonStore) SetDefault(code string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if code != "" && findSeasonIndex(s.seasons, code) < 0 {
+		return false
+	}
+	s.defaultCode = code
+	s.refreshCurrent()
+	return true
+}
+
+// Update runs fn against a deep copy of the data and publishes the result if fn
+// succeeds. Writers are serialized, so fn may persist its edit to a
+// BossRepository and the repository sees edits in the order they are applied.
+func (s *SeasonStore) Update(fn func(tx *SeasonTx) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tx := &SeasonTx{Seasons: cloneSeasons(s.seasons), Default: s.defaultCode}
+	if err := fn(tx); err != nil {
+		return err
+	}
+	s.seasons = tx.Seasons
+	s.defaultCode = tx.Default
+	if findSeasonIndex(s.seasons, s.defaultCode) < 0 {
+		s.defaultCode = ""
+	}
+	s.refreshCurrent()
+	return nil
+}
+
+// findSeasonIndex returns the index of the season with the given code, or -1
+func findSeasonIndex(seasons []Season, code string) int {
+	for i, s := range seasons {
+		if seasonCode(s) == code {
+			return i
+		}
+	}
+	return -1
+}
+
+func cloneSeasons(seasons []Season) []Season {
+	if seasons == nil {
+		return nil
+	}
+	out := make([]Season, len(seasons))
+	for i, s := range seasons {
+		out[i] = s.clone()
+	}
+	return out
+}
+
+func (s Season) clone() Season {
+	if s.RaidBosses != nil {
+		bosses := make([]RaidBoss, len(s.RaidBosses))
+		for i, b := range s.RaidBosses {
+			bosses[i] = b.clone()
+		}
+		s.RaidBosses = bosses
+	}
+	return s
+}
+
+func (b RaidBoss) clone() RaidBoss {
+	b.Moves = slices.Clone(b.Moves)
+	if b.PhaseEffects != nil {
+		phases := make([]PhaseEffect, len(b.PhaseEffects))
+		for i, pe := range b.PhaseEffects {
+			phases[i] = pe.clone()
+		}
+		b.PhaseEffects = phases
+	}
+	if b.Variations != nil {
+		vars := make([]Variation, len(b.Variations))
+		for i, v := range b.Variations {
+			vars[i] = v.clone()
+		}
+		b.Variations = vars
+	}
+	return b
+}
+
+func (v Variation) clone() Variation {
+	v.Players = maps.Clone(v.Players)
+	for pos, players := range v.Players {
+		v.Players[pos] = slices.Clone(players)
+	}
+	v.HealthRemaining = slices.Clone(v.HealthRemaining)
+	v.Notes = slices.Clone(v.Notes)
+	v.PlayersList = nil
+	return v
+}
+
+// findBossIn returns a pointer to the named boss within season, or nil
+func findBossIn(season *Season, name string) *RaidBoss {
+	for i := range season.RaidBosses {
+		if season.RaidBosses[i].Name == name {
+			return &season.RaidBosses[i]
+		}
+	}
+	return nil
+}
+
+// findBossByID returns a pointer to the boss with the given ID within season, or nil
+func findBossByID(season *Season, id string) *RaidBoss {
+	for i := range season.RaidBosses {
+		if season.RaidBosses[i].ID == id {
+			return &season.RaidBosses[i]
+		}
+	}
+	return nil
+}
+
+// findVariationIndex returns the index of the variation with the given ID, or -1
+func findVariationIndex(boss *RaidBoss, id string) int {
+	for i := range boss.Variations {
+		if boss.Variations[i].ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// newID returns a new unique ID for a boss or variation
+func newID() string {
+	return primitive.NewObjectID().Hex()
+}
+
+// validID reports whether id has the form produced by newID. Numeric slice
+// indexes used by older clients are rejected.
+func validID(id string) bool {
+	return primitive.IsValidObjectID(id)
+}
+
+// ensureIDs assigns IDs to the boss and any of its variations that lack one,
+// and reports whether anything changed
+func (b *RaidBoss) ensureIDs() bool {
+	changed := false
+	if b.ID == "" {
+		b.ID = newID()
+		changed = true
+	}
+	for i := range b.Variations {
+		if b.Variations[i].ID == "" {
+			b.Variations[i].ID = newID()
+			changed = true
+		}
+	}
+	return changed
+}
+
+// assignIDs gives every boss and variation in seasons an ID, reporting whether any were missing
+func assignIDs(seasons []Season) bool {
+	changed := false
+	for si := range seasons {
+		for bi := range seasons[si].RaidBosses {
+			if seasons[si].RaidBosses[bi].ensureIDs() {
+				changed = true
+			}
+		}
+	}
+	return changed
+}
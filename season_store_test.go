@@ -0,0 +1,193 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func testSeasons() []Season {
+	return []Season{
+		{SeasonName: "Christmas", Year: 2024, RaidBosses: []RaidBoss{
+			{ID: "glaceon", Name: "Glaceon", Variations: []Variation{{
+				Players:         map[string][]Player{"P1": {{Pokemon: "Golduck", Move: "Surf"}}},
+				HealthRemaining: []float64{80},
+			}}},
+		}},
+		{SeasonName: "Halloween", Year: 2024, RaidBosses: []RaidBoss{}},
+	}
+}
+
+func TestSeasonStoreCurrentFollowsDefault(t *testing.T) {
+	s := newSeasonStore(testSeasons(), nil)
+	if got := seasonCode(s.Current()); got != "christmas_2024" {
+		t.Fatalf("current = %q, want first season", got)
+	}
+	if !s.SetDefault("halloween_2024") {
+		t.Fatal("SetDefault rejected an existing season")
+	}
+	if got := seasonCode(s.Current()); got != "halloween_2024" {
+		t.Fatalf("current = %q, want default season", got)
+	}
+	if s.SetDefault("missing_2024") {
+		t.Fatal("SetDefault accepted an unknown season")
+	}
+}
+
+func TestSeasonStoreUpdateIsolatesSnapshots(t *testing.T) {
+	s := newSeasonStore(testSeasons(), nil)
+	before, _ := s.FindBoss("Glaceon")
+
+	err := s.Update(func(tx *SeasonTx) error {
+		boss := findBossIn(&tx.Seasons[0], "Glaceon")
+		boss.Variations[0].HealthRemaining[0] = 10
+		boss.Variations = append(boss.Variations, Variation{})
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if before.Variations[0].HealthRemaining[0] != 80 || len(before.Variations) != 1 {
+		t.Fatal("update leaked into a previously returned snapshot")
+	}
+	after, _ := s.FindBoss("Glaceon")
+	if len(after.Variations) != 2 || after.Variations[0].HealthRemaining[0] != 10 {
+		t.Fatalf("update not published: %+v", after.Variations)
+	}
+}
+
+func TestSeasonStoreUpdateErrorDiscardsChanges(t *testing.T) {
+	persistErr := fmt.Errorf("disk full")
+	s := newSeasonStore(testSeasons(), nil)
+
+	err := s.Update(func(tx *SeasonTx) error {
+		tx.Seasons = tx.Seasons[:1]
+		return persistErr
+	})
+	if err != persistErr {
+		t.Fatalf("err = %v, want persist error", err)
+	}
+	if len(s.Seasons()) != 2 {
+		t.Fatal("failed update was published")
+	}
+}
+
+// TestSeasonStoreConcurrentAccess is meant to be run with -race
+func TestSeasonStoreConcurrentAccess(t *testing.T) {
+	var persisted int
+	s := newSeasonStore(testSeasons(), func(season *Season) {
+		for bi := range season.RaidBosses {
+			for vi := range season.RaidBosses[bi].Variations {
+				season.RaidBosses[bi].Variations[vi].Index = vi + 1
+			}
+		}
+	})
+
+	const writers, readers, rounds = 4, 8, 200
+	var wg sync.WaitGroup
+	for w := 0; w < writers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < rounds; i++ {
+				err := s.Update(func(tx *SeasonTx) error {
+					v := Variation{
+						Players:         map[string][]Player{"P1": {{Pokemon: fmt.Sprintf("mon-%d-%d", w, i)}}},
+						HealthRemaining: []float64{50},
+					}
+					if err := createVariationIn(tx.Seasons, "christmas_2024", "glaceon", v); err != nil {
+						return err
+					}
+					persisted++ // serialized by the store's write lock
+					return nil
+				})
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				if i%50 == 0 {
+					s.SetDefault("halloween_2024")
+					s.SetDefault("christmas_2024")
+				}
+			}
+		}(w)
+	}
+	for r := 0; r < readers; r++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < rounds; i++ {
+				for _, b := range s.Current().RaidBosses {
+					for vi, v := range b.Variations {
+						if v.Index != vi+1 {
+							t.Errorf("variation %d has index %d", vi, v.Index)
+							return
+						}
+						_ = len(v.Players["P1"])
+					}
+				}
+				if boss, ok := s.FindBoss("Glaceon"); ok {
+					_ = len(boss.Variations)
+				}
+				_ = len(s.Seasons())
+			}
+		}()
+	}
+	wg.Wait()
+
+	boss, ok := s.FindBoss("Glaceon")
+	if !ok {
+		t.Fatal("boss missing after concurrent updates")
+	}
+	if want := 1 + writers*rounds; len(boss.Variations) != want {
+		t.Fatalf("got %d variations, want %d", len(boss.Variations), want)
+	}
+	if persisted != writers*rounds {
+		t.Fatalf("persisted %d times, want %d", persisted, writers*rounds)
+	}
+}
+
+func TestAssignIDsOnce(t *testing.T) {
+	seasons := testSeasons()
+	if !assignIDs(seasons) {
+		t.Fatal("assignIDs reported no change for a variation without an ID")
+	}
+	v := seasons[0].RaidBosses[0].Variations[0]
+	if !validID(v.ID) || seasons[0].RaidBosses[0].ID != "glaceon" {
+		t.Errorf("ids = %q, %q; existing IDs must be kept", seasons[0].RaidBosses[0].ID, v.ID)
+	}
+	if assignIDs(seasons) || seasons[0].RaidBosses[0].Variations[0].ID != v.ID {
+		t.Error("assignIDs changed IDs that were already set")
+	}
+}
+
+func TestIDsSurviveReload(t *testing.T) {
+	seasons := testSeasons()
+	seasons[0].RaidBosses[0].ID = ""
+	r := newTestJSONRepository(t, seasons)
+
+	first, err := r.Seasons(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := (&jsonBossRepository{path: r.path}).Seasons(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	b1, b2 := first[0].RaidBosses[0], second[0].RaidBosses[0]
+	if !validID(b1.ID) || b1.ID != b2.ID || b1.Variations[0].ID != b2.Variations[0].ID {
+		t.Errorf("IDs changed across reloads: %s/%s then %s/%s", b1.ID, b1.Variations[0].ID, b2.ID, b2.Variations[0].ID)
+	}
+}
+
+func TestValidID(t *testing.T) {
+	for _, id := range []string{"", "0", "12", "glaceon", "65a1f0c2e4b0a1b2c3d4e5f"} {
+		if validID(id) {
+			t.Errorf("validID(%q) = true", id)
+		}
+	}
+	if id := newID(); !validID(id) {
+		t.Errorf("validID(%q) = false for a new ID", id)
+	}
+}
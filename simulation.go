@@ -0,0 +1,342 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"strings"
+)
+
+// simulationTolerance is how far, in percentage points of boss HP, a claimed
+// health value may lie outside the simulated range before it is flagged
+const simulationTolerance = 5.0
+
+// statChange is a change of attack, special attack, defense and special defense stages
+type statChange struct {
+	Atk, SpAtk, Def, SpDef int
+}
+
+// setupMoves raise the stats of the player using them
+var setupMoves = map[string]statChange{
+	"swordsdance": {Atk: 2}, "nastyplot": {SpAtk: 2}, "tailglow": {SpAtk: 3}, "bellydrum": {Atk: 6},
+	"dragondance": {Atk: 1}, "bulkup": {Atk: 1}, "howl": {Atk: 1}, "honeclaws": {Atk: 1}, "coil": {Atk: 1},
+	"calmmind": {SpAtk: 1}, "quiverdance": {SpAtk: 1}, "workup": {Atk: 1, SpAtk: 1}, "growth": {Atk: 1, SpAtk: 1},
+	"shellsmash": {Atk: 2, SpAtk: 2},
+}
+
+// debuffMoves lower the boss's defenses
+var debuffMoves = map[string]statChange{
+	"screech": {Def: -2}, "tickle": {Def: -1}, "leer": {Def: -1}, "tailwhip": {Def: -1},
+	"faketears": {SpDef: -2}, "metalsound": {SpDef: -2},
+}
+
+// weatherMoves start a weather; rocks held by its user make it last 8 turns instead of 5
+var weatherMoves = map[string]struct{ weather, rock string }{
+	"raindance": {"rain", "damprock"}, "sunnyday": {"sun", "heatrock"},
+	"sandstorm": {"sand", "smoothrock"}, "hail": {"hail", "icyrock"}, "snowscape": {"hail", "icyrock"},
+}
+
+// simAction is one player's move in a simulated turn
+type simAction struct {
+	Player  string `json:"player"`
+	Pokemon string `json:"pokemon"`
+	Move    string `json:"move"`
+	Min     int    `json:"min"`
+	Max     int    `json:"max"`
+	Note    string `json:"note,omitempty"`
+}
+
+// simTurn is the state of the boss after one simulated turn
+type simTurn struct {
+	Turn           int           `json:"turn"`
+	Events         []PhaseEffect `json:"events"` // phase effects triggered at the start of the turn
+	Weather        string        `json:"weather,omitempty"`
+	Reflect        bool          `json:"reflect"`
+	LightScreen    bool          `json:"light_screen"`
+	Actions        []simAction   `json:"actions"`
+	Simulated      float64       `json:"simulated_health"` // boss HP % with average rolls
+	SimulatedMin   float64       `json:"simulated_min"`    // with every roll at its highest
+	SimulatedMax   float64       `json:"simulated_max"`    // with every roll at its lowest
+	Claimed        float64       `json:"claimed_health"`
+	Diverges       bool          `json:"diverges"`
+	DivergesBy     float64       `json:"diverges_by,omitempty"`
+	BossDefStage   int           `json:"boss_def_stage"`
+	BossSpDefStage int           `json:"boss_spdef_stage"`
+}
+
+// simulationReport compares a variation's claimed boss health with a simulation
+type simulationReport struct {
+	BossID         string    `json:"boss_id"`
+	VariationID    string    `json:"variation_id"`
+	BossHP         int       `json:"boss_hp"`
+	Tolerance      float64   `json:"tolerance"`
+	Assumptions    []string  `json:"assumptions"`
+	Turns          []simTurn `json:"turns"`
+	DivergingTurns []int     `json:"diverging_turns"`
+}
+
+// simField is the battle state carried between simulated turns
+type simField struct {
+	weather       string
+	weatherTurns  int
+	reflectTurns  int
+	lightTurns    int
+	boss          statChange
+	players       map[string]statChange
+	screenTurns   int // 5, or 8 when the boss holds Light Clay
+	bossItem      string
+	triggered     []bool
+	pendingEvents []PhaseEffect
+}
+
+func clampStage(n int) int {
+	return max(-6, min(6, n))
+}
+
+func (f *simField) setWeather(move, item string) bool {
+	w, ok := weatherMoves[move]
+	if !ok {
+		return false
+	}
+	f.weather, f.weatherTurns = w.weather, 5
+	if item == w.rock {
+		f.weatherTurns = 8
+	}
+	return true
+}
+
+// applyPhase applies the mechanical part of a boss phase effect; effects the
+// simulation does not model are only reported
+func (f *simField) applyPhase(pe PhaseEffect) {
+	switch pe.Kind {
+	case phaseWeather:
+		for _, w := range weatherMoves {
+			if w.weather == pe.Params.Weather {
+				f.weather, f.weatherTurns = w.weather, 5
+				if f.bossItem == w.rock {
+					f.weatherTurns = 8
+				}
+				break
+			}
+		}
+	case phaseScreen:
+		switch pe.Params.Screen {
+		case "reflect":
+			f.reflectTurns = f.screenTurns
+		case "light_screen":
+			f.lightTurns = f.screenTurns
+		}
+	case phaseStatChange:
+		f.boss.Def = clampStage(f.boss.Def + pe.Params.Stats["defense"])
+		f.boss.SpDef = clampStage(f.boss.SpDef + pe.Params.Stats["sp_defense"])
+	}
+}
+
+// triggerPhases fires phase effects whose health threshold the boss has reached
+func (f *simField) triggerPhases(effects []PhaseEffect, healthPct float64) {
+	for i, pe := range effects {
+		if !f.triggered[i] && float64(pe.Health) >= healthPct {
+			f.triggered[i] = true
+			f.applyPhase(pe)
+			f.pendingEvents = append(f.pendingEvents, pe)
+		}
+	}
+}
+
+// endTurn counts down timed field effects
+func (f *simField) endTurn() {
+	countdown := func(n *int) {
+		if *n > 0 {
+			*n--
+		}
+	}
+	countdown(&f.reflectTurns)
+	countdown(&f.lightTurns)
+	if f.weatherTurns > 0 {
+		f.weatherTurns--
+		if f.weatherTurns == 0 {
+			f.weather = ""
+		}
+	}
+}
+
+// simulateVariation replays a variation turn by turn with calculated damage and
+// the boss's phase effects, and compares the result with its claimed health.
+// It is deterministic: every attack uses its average roll without critical hits.
+func (a *App) simulateVariation(boss RaidBoss, v Variation) (simulationReport, error) {
+	bossHP := a.bossMaxHP(boss)
+	if bossHP == 0 {
+		return simulationReport{}, damageError("boss HP is unknown; set its HP base stat")
+	}
+
+	report := simulationReport{
+		BossID:      boss.ID,
+		VariationID: v.ID,
+		BossHP:      bossHP,
+		Tolerance:   simulationTolerance,
+		Assumptions: []string{
+			fmt.Sprintf("players are level %d with 31 IVs, 252 EVs and a boosting nature in the move's attacking stat", raidLevel),
+			"every attack uses its average damage roll and no attack is a critical hit",
+			"players act in order P1–P4; abilities and the boss's own attacks, status moves and KOs are not simulated",
+			"Reflect and Light Screen last 5 turns (8 with Light Clay); weather lasts 5 turns (8 with its rock)",
+		},
+		Turns:          []simTurn{},
+		DivergingTurns: []int{},
+	}
+
+	field := &simField{
+		players:     map[string]statChange{},
+		screenTurns: 5,
+		bossItem:    squashName(boss.HeldItem),
+		triggered:   make([]bool, len(boss.PhaseEffects)),
+	}
+	if field.bossItem == "lightclay" {
+		field.screenTurns = 8
+	}
+
+	hp, hpLow, hpHigh := float64(bossHP), float64(bossHP), float64(bossHP)
+	pct := func(x float64) float64 { return math.Round(max(x, 0)*1000/float64(bossHP)) / 10 }
+
+	for t, claimed := range v.HealthRemaining {
+		field.triggerPhases(boss.PhaseEffects, pct(hp))
+		turn := simTurn{
+			Turn:        t + 1,
+			Events:      field.pendingEvents,
+			Weather:     field.weather,
+			Reflect:     field.reflectTurns > 0,
+			LightScreen: field.lightTurns > 0,
+			Actions:     []simAction{},
+			Claimed:     claimed,
+		}
+		if turn.Events == nil {
+			turn.Events = []PhaseEffect{}
+		}
+		field.pendingEvents = nil
+
+		for _, pos := range playerPositions {
+			lane := v.Players[pos]
+			if t >= len(lane) || lane[t].Action != actionMove || strings.TrimSpace(lane[t].Move) == "" {
+				continue
+			}
+			p := lane[t]
+			action := simAction{Player: pos, Pokemon: p.Pokemon, Move: p.Move}
+			action.Note = a.simulateAction(boss, field, pos, p, pct(hp), &action)
+			hp -= float64(action.Min+action.Max) / 2
+			hpLow -= float64(action.Max)
+			hpHigh -= float64(action.Min)
+			turn.Actions = append(turn.Actions, action)
+		}
+		field.endTurn()
+
+		turn.Simulated, turn.SimulatedMin, turn.SimulatedMax = pct(hp), pct(hpLow), pct(hpHigh)
+		turn.BossDefStage, turn.BossSpDefStage = field.boss.Def, field.boss.SpDef
+		switch {
+		case claimed < turn.SimulatedMin-simulationTolerance:
+			turn.DivergesBy = math.Round((claimed-turn.SimulatedMin)*10) / 10
+		case claimed > turn.SimulatedMax+simulationTolerance:
+			turn.DivergesBy = math.Round((claimed-turn.SimulatedMax)*10) / 10
+		}
+		if turn.DivergesBy != 0 {
+			turn.Diverges = true
+			report.DivergingTurns = append(report.DivergingTurns, turn.Turn)
+		}
+		report.Turns = append(report.Turns, turn)
+	}
+	return report, nil
+}
+
+// simulateAction applies one player's move to the field and fills in its
+// damage range. It returns a note when the move deals no calculated damage.
+func (a *App) simulateAction(boss RaidBoss, field *simField, pos string, p Player, healthPct float64, action *simAction) string {
+	move, ok := a.gameData.findMove(p.Move)
+	if !ok {
+		return "unknown move, counted as no damage"
+	}
+	key := squashName(move.Name)
+
+	if move.Category == "STATUS" {
+		switch {
+		case field.setWeather(key, squashName(p.Item)):
+			return "sets " + field.weather
+		case key == "defog":
+			field.reflectTurns, field.lightTurns = 0, 0
+			return "clears screens"
+		}
+		if c, ok := setupMoves[key]; ok {
+			s := field.players[pos]
+			s.Atk, s.SpAtk = clampStage(s.Atk+c.Atk), clampStage(s.SpAtk+c.SpAtk)
+			field.players[pos] = s
+			return "raises the user's stats"
+		}
+		if c, ok := debuffMoves[key]; ok {
+			field.boss.Def = clampStage(field.boss.Def + c.Def)
+			field.boss.SpDef = clampStage(field.boss.SpDef + c.SpDef)
+			return "lowers the boss's defenses"
+		}
+		return "status move"
+	}
+
+	mon, ok := a.gameData.findMonster(p.Pokemon)
+	if !ok {
+		return "unknown Pokémon, counted as no damage"
+	}
+	// Brick Break shatters screens before it hits
+	if key == "brickbreak" {
+		field.reflectTurns, field.lightTurns = 0, 0
+	}
+
+	stats := field.players[pos]
+	in := attackInput{
+		Pokemon:     mon,
+		Move:        move,
+		Nature:      "plus",
+		EVs:         252,
+		IVs:         31,
+		Level:       raidLevel,
+		Stage:       stats.SpAtk,
+		BossStage:   field.boss.SpDef,
+		Item:        p.Item,
+		Weather:     field.weather,
+		BossHealth:  healthPct,
+		Reflect:     field.reflectTurns > 0,
+		LightScreen: field.lightTurns > 0,
+	}
+	if move.Category == "PHYSICAL" {
+		in.Stage, in.BossStage = stats.Atk, field.boss.Def
+	}
+	est, err := a.estimateDamage(boss, in)
+	if err != nil {
+		return err.Error()
+	}
+	action.Min, action.Max = est.Rolls[0], est.Rolls[len(est.Rolls)-1]
+	return ""
+}
+
+// simulationHandler returns the simulation report for one variation of a boss.
+// Query: boss_id, variation_id.
+func (a *App) simulationHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	current := a.store.Current()
+	boss := findBossByID(&current, r.URL.Query().Get("boss_id"))
+	if boss == nil {
+		http.Error(w, "boss not found", http.StatusNotFound)
+		return
+	}
+	vi := findVariationIndex(boss, r.URL.Query().Get("variation_id"))
+	if vi < 0 {
+		http.Error(w, "variation not found", http.StatusNotFound)
+		return
+	}
+
+	report, err := a.simulateVariation(*boss, boss.Variations[vi])
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
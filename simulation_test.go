@@ -0,0 +1,60 @@
+package main
+
+import "testing"
+
+func TestSimulateVariation(t *testing.T) {
+	a := &App{gameData: &gameData{
+		pokemon:  newNameSet([]string{"Machamp"}),
+		moves:    newNameSet([]string{"Cross Chop", "Screech"}),
+		monsters: map[string]monsterInfo{"machamp": {Name: "Machamp", Types: []string{"Fighting"}, Stats: statBlock{Attack: 130}}},
+		moveInfo: map[string]moveInfo{
+			"cross chop": {Name: "Cross Chop", Category: "PHYSICAL", Power: 100, Type: "FIGHTING"},
+			"screech":    {Name: "Screech", Category: "STATUS", Type: "NORMAL"},
+		},
+	}}
+	boss := RaidBoss{
+		ID:           "b1",
+		BaseStats:    BaseStats{HP: 250, Def: 100},
+		PhaseEffects: []PhaseEffect{testPhase(100, "Sing"), testPhase(60, "Reflect")},
+	}
+	chop := Player{Pokemon: "Machamp", Action: actionMove, Move: "Cross Chop⭐️"}
+	v := Variation{
+		ID: "v1",
+		Players: map[string][]Player{
+			"P1": {chop, chop, chop},
+			"P2": {{Pokemon: "Machamp", Action: actionMove, Move: "Screech"}, chop, chop},
+		},
+		HealthRemaining: []float64{90, 80, 0},
+	}
+
+	report, err := a.simulateVariation(boss, v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Turns) != 3 {
+		t.Fatalf("got %d turns", len(report.Turns))
+	}
+	first, second, third := report.Turns[0], report.Turns[1], report.Turns[2]
+	if len(first.Events) != 1 || first.Events[0].Name != "Sing" {
+		t.Errorf("turn 1 events %+v, want Sing", first.Events)
+	}
+	if second.BossDefStage != -2 || second.Reflect {
+		t.Errorf("turn 2: def stage %d, reflect %v", second.BossDefStage, second.Reflect)
+	}
+	if !third.Reflect || len(third.Events) != 1 {
+		t.Errorf("turn 3: reflect %v, events %+v", third.Reflect, third.Events)
+	}
+	if first.Actions[0].Max == 0 || first.Actions[1].Note == "" {
+		t.Errorf("turn 1 actions %+v", first.Actions)
+	}
+	if !first.Diverges || third.Diverges {
+		t.Errorf("diverging turns %v, want [1 ...] without 3", report.DivergingTurns)
+	}
+}
+
+func TestSimulateVariationNeedsBossHP(t *testing.T) {
+	a := &App{gameData: &gameData{}}
+	if _, err := a.simulateVariation(RaidBoss{}, Variation{HealthRemaining: []float64{50}}); err == nil {
+		t.Fatal("expected an error without boss HP")
+	}
+}
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// raidLevel is the level raid bosses fight at and the default level for player Pokémon
+var raidLevel = func() int {
+	n, err := strconv.Atoi(getEnvOrDefault("RAID_LEVEL", "100"))
+	if err != nil || n < 1 || n > 100 {
+		return 100
+	}
+	return n
+}()
+
+// natureEffects maps each stat-changing nature to the stats it raises and lowers
+var natureEffects = map[string][2]string{
+	"lonely": {"attack", "defense"}, "brave": {"attack", "speed"}, "adamant": {"attack", "sp_attack"}, "naughty": {"attack", "sp_defense"},
+	"bold": {"defense", "attack"}, "relaxed": {"defense", "speed"}, "impish": {"defense", "sp_attack"}, "lax": {"defense", "sp_defense"},
+	"timid": {"speed", "attack"}, "hasty": {"speed", "defense"}, "jolly": {"speed", "sp_attack"}, "naive": {"speed", "sp_defense"},
+	"modest": {"sp_attack", "attack"}, "mild": {"sp_attack", "defense"}, "quiet": {"sp_attack", "speed"}, "rash": {"sp_attack", "sp_defense"},
+	"calm": {"sp_defense", "attack"}, "gentle": {"sp_defense", "defense"}, "sassy": {"sp_defense", "speed"}, "careful": {"sp_defense", "sp_attack"},
+}
+
+// natureMultiplier returns the nature modifier in tenths (9, 10 or 11) for a stat.
+// Besides nature names, "plus", "neutral" and "minus" are accepted.
+func natureMultiplier(nature, stat string) (int, error) {
+	switch n := strings.ToLower(strings.TrimSpace(nature)); n {
+	case "", "neutral", "hardy", "docile", "serious", "bashful", "quirky":
+		return 10, nil
+	case "plus":
+		return 11, nil
+	case "minus":
+		return 9, nil
+	default:
+		effect, ok := natureEffects[n]
+		if !ok {
+			return 0, fmt.Errorf("unknown nature %q", nature)
+		}
+		switch stat {
+		case effect[0]:
+			return 11, nil
+		case effect[1]:
+			return 9, nil
+		}
+		return 10, nil
+	}
+}
+
+// calcStat applies the Gen 3+ stat formula for a non-HP stat
+func calcStat(base, iv, ev, level, nature10 int) int {
+	return ((2*base+iv+ev/4)*level/100 + 5) * nature10 / 10
+}
+
+// stageMultiply applies a stat stage from -6 to +6
+func stageMultiply(stat, stage int) int {
+	if stage >= 0 {
+		return stat * (2 + stage) / 2
+	}
+	return stat * 2 / (2 - stage)
+}
+
+// weatherSpeedAbilities double speed in their weather
+var weatherSpeedAbilities = map[string]string{
+	"swift swim":  "rain",
+	"chlorophyll": "sun",
+	"sand rush":   "sand",
+}
+
+// speedModifiers are the in-battle effects on a Pokémon's speed
+type speedModifiers struct {
+	Stage     int    // -6..+6
+	Tailwind  bool   // x2
+	Paralyzed bool   // x0.25
+	Scarf     bool   // Choice Scarf x1.5
+	Ability   string // e.g. "Swift Swim"
+	Weather   string // "rain", "sun", "sand", "hail" or ""
+}
+
+// effectiveSpeed applies modifiers in battle order, rounding down after each step
+func effectiveSpeed(stat int, m speedModifiers) int {
+	speed := stageMultiply(stat, m.Stage)
+	if m.Scarf {
+		speed = speed * 3 / 2
+	}
+	if w, ok := weatherSpeedAbilities[strings.ToLower(strings.TrimSpace(m.Ability))]; ok && w == strings.ToLower(m.Weather) {
+		speed *= 2
+	}
+	if m.Tailwind {
+		speed *= 2
+	}
+	if m.Paralyzed {
+		speed /= 4
+	}
+	return speed
+}
+
+// bossSpeed returns the boss's speed stat at raid level: perfect IVs, its SpeedEVs and a neutral nature
+func bossSpeed(b RaidBoss) int {
+	return calcStat(b.BaseStats.Speed, 31, b.SpeedEVs, raidLevel, 10)
+}
+
+// speedCalcHandler reports whether a player's Pokémon outspeeds a boss.
+// Query: boss_id, pokemon, nature, evs, ivs, level, stage, tailwind, paralyzed,
+// scarf, ability, weather, boss_stage, boss_paralyzed, trick_room.
+func (a *App) speedCalcHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	q := r.URL.Query()
+
+	current := a.store.Current()
+	boss := findBossByID(&current, q.Get("boss_id"))
+	if boss == nil {
+		http.Error(w, "boss not found", http.StatusNotFound)
+		return
+	}
+	if boss.BaseStats.Speed == 0 {
+		http.Error(w, "boss has no base speed recorded", http.StatusUnprocessableEntity)
+		return
+	}
+	mon, ok := a.gameData.monster(q.Get("pokemon"))
+	if !ok || mon.Stats.Speed == 0 {
+		http.Error(w, "unknown pokemon or no base stats available", http.StatusNotFound)
+		return
+	}
+
+	var errs []FieldError
+	intParam := func(name string, def, lo, hi int) int {
+		s := q.Get(name)
+		if s == "" {
+			return def
+		}
+		n, err := strconv.Atoi(s)
+		if err != nil || n < lo || n > hi {
+			errs = append(errs, FieldError{Field: name, Message: fmt.Sprintf("must be a number from %d to %d", lo, hi)})
+			return def
+		}
+		return n
+	}
+	boolParam := func(name string) bool {
+		b, _ := strconv.ParseBool(q.Get(name))
+		return b
+	}
+
+	evs := intParam("evs", 0, 0, 252)
+	ivs := intParam("ivs", 31, 0, 31)
+	level := intParam("level", raidLevel, 1, 100)
+	mods := speedModifiers{
+		Stage:     intParam("stage", 0, -6, 6),
+		Tailwind:  boolParam("tailwind"),
+		Paralyzed: boolParam("paralyzed"),
+		Scarf:     boolParam("scarf"),
+		Ability:   q.Get("ability"),
+		Weather:   q.Get("weather"),
+	}
+	bossMods := speedModifiers{
+		Stage:     intParam("boss_stage", 0, -6, 6),
+		Paralyzed: boolParam("boss_paralyzed"),
+	}
+	nature, err := natureMultiplier(q.Get("nature"), "speed")
+	if err != nil {
+		errs = append(errs, FieldError{Field: "nature", Message: err.Error()})
+	}
+	if len(errs) > 0 {
+		writeValidationErrors(w, errs)
+		return
+	}
+
+	stat := calcStat(mon.Stats.Speed, ivs, evs, level, nature)
+	speed := effectiveSpeed(stat, mods)
+	bossStat := bossSpeed(*boss)
+	bossEffective := effectiveSpeed(bossStat, bossMods)
+
+	// under Trick Room the slower Pokémon moves first
+	trickRoom := boolParam("trick_room")
+	first := "tie"
+	if speed != bossEffective {
+		first = "boss"
+		if (speed > bossEffective) != trickRoom {
+			first = "pokemon"
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"boss": map[string]interface{}{
+			"name":            boss.Name,
+			"level":           raidLevel,
+			"base_speed":      boss.BaseStats.Speed,
+			"speed_evs":       boss.SpeedEVs,
+			"speed":           bossStat,
+			"effective_speed": bossEffective,
+		},
+		"pokemon": map[string]interface{}{
+			"name":            mon.Name,
+			"level":           level,
+			"base_speed":      mon.Stats.Speed,
+			"speed":           stat,
+			"effective_speed": speed,
+		},
+		"trick_room":  trickRoom,
+		"outspeeds":   speed > bossEffective,
+		"moves_first": first,
+	})
+}
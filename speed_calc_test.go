@@ -0,0 +1,48 @@
+package main
+
+import "testing"
+
+func TestCalcStatSpeed(t *testing.T) {
+	// base 130 speed, 31 IVs, 252 EVs at level 100
+	tests := []struct {
+		nature string
+		want   int
+	}{
+		{"timid", 394},
+		{"neutral", 359},
+		{"brave", 323},
+	}
+	for _, tt := range tests {
+		n, err := natureMultiplier(tt.nature, "speed")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := calcStat(130, 31, 252, 100, n); got != tt.want {
+			t.Errorf("%s: got %d, want %d", tt.nature, got, tt.want)
+		}
+	}
+	if _, err := natureMultiplier("speedy", "speed"); err == nil {
+		t.Error("expected error for unknown nature")
+	}
+}
+
+func TestEffectiveSpeed(t *testing.T) {
+	tests := []struct {
+		name string
+		m    speedModifiers
+		want int
+	}{
+		{"none", speedModifiers{}, 200},
+		{"+1", speedModifiers{Stage: 1}, 300},
+		{"-1", speedModifiers{Stage: -1}, 133},
+		{"scarf and tailwind", speedModifiers{Scarf: true, Tailwind: true}, 600},
+		{"swift swim in rain", speedModifiers{Ability: "Swift Swim", Weather: "rain"}, 400},
+		{"swift swim in sun", speedModifiers{Ability: "Swift Swim", Weather: "sun"}, 200},
+		{"paralyzed", speedModifiers{Paralyzed: true}, 50},
+	}
+	for _, tt := range tests {
+		if got := effectiveSpeed(200, tt.m); got != tt.want {
+			t.Errorf("%s: got %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
@@ -28,6 +28,8 @@ async function initAdmin() {
         // Tab switching
         document.getElementById('tab-checklist').addEventListener('click', () => switchTab('checklist'));
         document.getElementById('tab-raid-bosses').addEventListener('click', () => switchTab('raid-bosses'));
+        document.getElementById('tab-submissions').addEventListener('click', () => switchTab('submissions'));
+        document.getElementById('tab-variations').addEventListener('click', () => switchTab('variations'));
         const usersTabBtn = document.getElementById('tab-users');
         usersTabBtn.addEventListener('click', () => switchTab('users'));
         // Hide Users tab for non-admins
@@ -146,12 +148,18 @@ function switchTab(tab) {
     // Update button styles
     document.getElementById('tab-checklist').classList.toggle('active', tab === 'checklist');
     document.getElementById('tab-raid-bosses').classList.toggle('active', tab === 'raid-bosses');
+    document.getElementById('tab-submissions').classList.toggle('active', tab === 'submissions');
+    document.getElementById('tab-variations').classList.toggle('active', tab === 'variations');
     document.getElementById('tab-users').classList.toggle('active', tab === 'users');
 
     if (tab === 'checklist') {
         loadTypes();
     } else if (tab === 'raid-bosses') {
         loadRaidBosses();
+    } else if (tab === 'submissions') {
+        loadSubmissions();
+    } else if (tab === 'variations') {
+        loadVariations();
     } else if (tab === 'users') {
         if (userRole === 'admin') {
             loadUsers();
@@ -562,8 +570,17 @@ function renderRaidBosses(bosses) {
     const title = document.createElement('h2');
     title.textContent = `Raid Bosses (${bosses.length})`;
     header.appendChild(title);
+    const lintBtn = document.createElement('button');
+    lintBtn.textContent = 'Check Names';
+    lintBtn.className = 'button btn-secondary';
+    lintBtn.addEventListener('click', loadSeasonLint);
+    header.appendChild(lintBtn);
     container.appendChild(header);
 
+    const lintReport = document.createElement('div');
+    lintReport.id = 'season-lint-report';
+    container.appendChild(lintReport);
+
     const grid = document.createElement('div');
     grid.className = 'raid-boss-grid';
 
@@ -594,11 +611,14 @@ function renderRaidBosses(bosses) {
 
             const actions = document.createElement('div');
             actions.className = 'raid-boss-actions';
-            const edit = document.createElement('a');
-            edit.href = `/admin/raid-boss-builder?action=edit&season=${encodeURIComponent(currentSeason)}&id=${encodeURIComponent(b.id)}`;
-            edit.textContent = 'Edit';
-            edit.className = 'raid-boss-link';
-            actions.appendChild(edit);
+            // authors suggest variation changes from the boss page instead
+            if (userRole === 'admin' || userRole === 'mod') {
+                const edit = document.createElement('a');
+                edit.href = `/admin/raid-boss-builder?action=edit&season=${encodeURIComponent(currentSeason)}&id=${encodeURIComponent(b.id)}`;
+                edit.textContent = 'Edit';
+                edit.className = 'raid-boss-link';
+                actions.appendChild(edit);
+            }
             if (userRole === 'admin') {
                 const delBtn = document.createElement('button');
                 delBtn.textContent = 'Delete';
@@ -618,6 +638,45 @@ function renderRaidBosses(bosses) {
     container.appendChild(grid);
 }
 
+// loadSeasonLint lists moves, items and Pokémon that are not in the game data files
+async function loadSeasonLint() {
+    const report = document.getElementById('season-lint-report');
+    report.innerHTML = '<p class="admin-loading">Checking names…</p>';
+    try {
+        const res = await fetch(`/api/admin/lint?season=${encodeURIComponent(currentSeason)}`);
+        if (!res.ok) {
+            report.innerHTML = `<p class="error">Failed to check names (${res.status})</p>`;
+            return;
+        }
+        const lint = await res.json();
+        report.innerHTML = '';
+        if (!lint.total) {
+            report.innerHTML = '<p class="admin-empty">All names match the game data.</p>';
+            return;
+        }
+        lint.bosses.forEach(b => {
+            const card = document.createElement('div');
+            card.className = 'raid-boss-card';
+            const name = document.createElement('strong');
+            name.className = 'raid-boss-name';
+            name.textContent = `${b.boss_name} (${b.issues.length})`;
+            card.appendChild(name);
+            const list = document.createElement('ul');
+            b.issues.forEach(issue => {
+                const li = document.createElement('li');
+                li.className = 'raid-boss-meta';
+                li.textContent = `${issue.field}: ${issue.message}`;
+                list.appendChild(li);
+            });
+            card.appendChild(list);
+            report.appendChild(card);
+        });
+    } catch (e) {
+        console.error('Season lint failed', e);
+        report.innerHTML = '<p class="error">Failed to check names</p>';
+    }
+}
+
 async function deleteRaidBoss(id) {
     try {
         const res = await fetch(`/api/admin/raid-bosses?season=${encodeURIComponent(currentSeason)}&id=${encodeURIComponent(id)}`, { method: 'DELETE' });
@@ -635,6 +694,254 @@ async function deleteRaidBoss(id) {
 // Backwards-compat alias for potential case-typo
 const loadRaidbosses = loadRaidBosses;
 
+// ============= SUBMISSIONS TAB =============
+
+// Mods and admins review the pending queue; authors see their own submissions and feedback
+function canReviewSubmissions() {
+    return userRole === 'admin' || userRole === 'mod';
+}
+
+async function loadSubmissions() {
+    const container = document.getElementById('admin-app');
+    container.innerHTML = '<p class="admin-loading">Loading submissions…</p>';
+    try {
+        const res = await fetch(canReviewSubmissions() ? '/api/admin/submissions' : '/api/submissions');
+        if (!res.ok) {
+            const txt = await res.text();
+            container.innerHTML = `<p class="error">Failed to load submissions (${res.status}). ${txt}</p>`;
+            return;
+        }
+        const subs = await res.json();
+        renderSubmissions(subs);
+    } catch (err) {
+        console.error('Error loading submissions:', err);
+        container.innerHTML = '<p class="error">Failed to load submissions</p>';
+    }
+}
+
+function renderSubmissions(subs) {
+    const container = document.getElementById('admin-app');
+    container.innerHTML = '';
+
+    const header = document.createElement('div');
+    header.className = 'admin-section-header';
+    const title = document.createElement('h2');
+    title.textContent = canReviewSubmissions() ? `Pending Submissions (${subs.length})` : `My Submissions (${subs.length})`;
+    header.appendChild(title);
+    container.appendChild(header);
+
+    const grid = document.createElement('div');
+    grid.className = 'raid-boss-grid';
+
+    if (!subs.length) {
+        grid.innerHTML = '<p class="admin-empty">No submissions.</p>';
+    }
+    subs.forEach(s => {
+        const card = document.createElement('div');
+        card.className = 'raid-boss-card';
+
+        const nameRow = document.createElement('div');
+        nameRow.className = 'raid-boss-name-row';
+        const nameEl = document.createElement('strong');
+        nameEl.className = 'raid-boss-name';
+        nameEl.textContent = `${s.boss_name} — ${s.new_variation ? 'new variation' : 'variation edit'}`;
+        nameRow.appendChild(nameEl);
+        card.appendChild(nameRow);
+
+        const meta = document.createElement('div');
+        meta.className = 'raid-boss-meta';
+        meta.textContent = `${s.submitted_by || 'anonymous'} • ${new Date(s.submitted_at).toLocaleString()} • ${s.status.replace('_', ' ')}`;
+        card.appendChild(meta);
+
+        if (s.variation.credits) {
+            const credits = document.createElement('div');
+            credits.className = 'raid-boss-desc';
+            credits.textContent = `Credits: ${s.variation.credits}`;
+            card.appendChild(credits);
+        }
+
+        if (s.reason) {
+            const reason = document.createElement('div');
+            reason.className = 'raid-boss-desc';
+            reason.textContent = `Reviewer: ${s.reason}`;
+            card.appendChild(reason);
+        }
+
+        card.appendChild(renderSubmissionTable(s.variation));
+
+        if (canReviewSubmissions()) {
+            const actions = document.createElement('div');
+            actions.className = 'raid-boss-actions';
+            [['approve', 'Approve'], ['request_changes', 'Request changes'], ['reject', 'Reject']].forEach(([action, label]) => {
+                const btn = document.createElement('button');
+                btn.textContent = label;
+                btn.className = action === 'reject' ? 'raid-boss-delete' : 'raid-boss-link';
+                btn.addEventListener('click', () => reviewSubmission(s.id, action));
+                actions.appendChild(btn);
+            });
+            card.appendChild(actions);
+        }
+
+        grid.appendChild(card);
+    });
+
+    container.appendChild(grid);
+}
+
+// renderSubmissionTable shows the proposed turns as a compact read-only table
+function renderSubmissionTable(variation) {
+    const table = document.createElement('table');
+    table.className = 'plan-table';
+    const headRow = table.createTHead().insertRow();
+    ['Turn', 'P1', 'P2', 'P3', 'P4', 'HP', 'Notes'].forEach(h => {
+        const th = document.createElement('th');
+        th.textContent = h;
+        headRow.appendChild(th);
+    });
+    const body = table.createTBody();
+    const health = variation.health_remaining || [];
+    health.forEach((hp, turn) => {
+        const row = body.insertRow();
+        row.insertCell().textContent = turn + 1;
+        ['P1', 'P2', 'P3', 'P4'].forEach(pos => {
+            const p = ((variation.players || {})[pos] || [])[turn];
+            row.insertCell().textContent = p ? [p.pokemon, p.move, p.item].filter(Boolean).join(' / ') : '—';
+        });
+        row.insertCell().textContent = hp;
+        row.insertCell().textContent = (variation.notes || [])[turn] || '';
+    });
+    return table;
+}
+
+async function reviewSubmission(id, action) {
+    let reason = '';
+    if (action !== 'approve') {
+        reason = prompt(action === 'reject' ? 'Reason for rejecting:' : 'What should the author change?');
+        if (!reason) return;
+    }
+    try {
+        const res = await fetch('/api/admin/submissions/review', {
+            method: 'POST',
+            headers: { 'Content-Type': 'application/json' },
+            body: JSON.stringify({ id, action, reason })
+        });
+        if (res.status === 409) {
+            alert('The variation changed since this was submitted. Request changes so the author can rebase it.');
+        } else if (!res.ok) {
+            alert('Failed to review submission: ' + await res.text());
+        }
+        await loadSubmissions();
+    } catch (e) {
+        console.error('Review submission failed', e);
+        alert('Error reviewing submission');
+    }
+}
+
+// ============= VARIATIONS TAB =============
+
+// Filters of the variations list: a user who created or last edited a variation
+// or is named in its credits, a boss, and a date of the last update
+let variationFilters = { author: '', boss_id: '', since: '' };
+
+async function loadVariations() {
+    const container = document.getElementById('admin-app');
+    container.innerHTML = '<p class="admin-loading">Loading variations…</p>';
+    const params = new URLSearchParams({ season: currentSeason });
+    Object.entries(variationFilters).forEach(([k, v]) => { if (v) params.set(k, v); });
+    try {
+        const [res, bossRes] = await Promise.all([
+            fetch(`/api/admin/variations?${params.toString()}`),
+            fetch(`/api/admin/raid-bosses?season=${encodeURIComponent(currentSeason)}`),
+        ]);
+        if (!res.ok) {
+            const txt = await res.text();
+            container.innerHTML = `<p class="error">Failed to load variations (${res.status}). ${txt}</p>`;
+            return;
+        }
+        const rows = await res.json();
+        const bosses = bossRes.ok ? await bossRes.json() : [];
+        renderVariations(rows, bosses);
+    } catch (err) {
+        console.error('Error loading variations:', err);
+        container.innerHTML = '<p class="error">Failed to load variations</p>';
+    }
+}
+
+function renderVariations(rows, bosses) {
+    const container = document.getElementById('admin-app');
+    container.innerHTML = '';
+
+    const header = document.createElement('div');
+    header.className = 'admin-section-header';
+    const title = document.createElement('h2');
+    title.textContent = `Variations (${rows.length})`;
+    header.appendChild(title);
+    container.appendChild(header);
+
+    const form = document.createElement('form');
+    form.className = 'admin-filter-row';
+    form.innerHTML = `
+        <input type="text" name="author" placeholder="Author, editor or credits" />
+        <select name="boss_id"><option value="">All bosses</option></select>
+        <label>Updated since <input type="date" name="since" /></label>
+        <button type="submit" class="button btn-secondary">Filter</button>
+        <button type="button" class="button btn-secondary" id="clear-variation-filters">Clear</button>
+    `;
+    bosses.forEach(b => {
+        const opt = document.createElement('option');
+        opt.value = b.id;
+        opt.textContent = b.boss_name;
+        form.boss_id.appendChild(opt);
+    });
+    form.author.value = variationFilters.author;
+    form.boss_id.value = variationFilters.boss_id;
+    form.since.value = variationFilters.since;
+    form.addEventListener('submit', (e) => {
+        e.preventDefault();
+        variationFilters = { author: form.author.value.trim(), boss_id: form.boss_id.value, since: form.since.value };
+        loadVariations();
+    });
+    form.querySelector('#clear-variation-filters').addEventListener('click', () => {
+        variationFilters = { author: '', boss_id: '', since: '' };
+        loadVariations();
+    });
+    container.appendChild(form);
+
+    if (!rows.length) {
+        const empty = document.createElement('p');
+        empty.className = 'admin-empty';
+        empty.textContent = 'No variations match.';
+        container.appendChild(empty);
+        return;
+    }
+
+    const table = document.createElement('table');
+    table.className = 'plan-table';
+    const headRow = table.createTHead().insertRow();
+    ['Boss', 'Variation', 'Created by', 'Created', 'Last edited by', 'Updated', 'Credits'].forEach(h => {
+        const th = document.createElement('th');
+        th.textContent = h;
+        headRow.appendChild(th);
+    });
+    const body = table.createTBody();
+    const date = (t) => t ? new Date(t).toLocaleDateString() : '—';
+    rows.forEach(v => {
+        const row = body.insertRow();
+        const bossCell = row.insertCell();
+        const link = document.createElement('a');
+        link.href = `/boss?name=${encodeURIComponent(v.boss_name)}`;
+        link.textContent = v.boss_name;
+        bossCell.appendChild(link);
+        row.insertCell().textContent = `${v.variation} (rev ${v.revision})`;
+        row.insertCell().textContent = v.created_at ? (v.created_by || 'anonymous') : '—';
+        row.insertCell().textContent = date(v.created_at);
+        row.insertCell().textContent = v.updated_at ? (v.updated_by || 'anonymous') : '—';
+        row.insertCell().textContent = date(v.updated_at);
+        row.insertCell().textContent = v.credits || '—';
+    });
+    container.appendChild(table);
+}
+
 // ============= USERS TAB =============
 
 async function loadUsers() {
@@ -55,27 +55,91 @@ function renderMovesList() {
     });
 }
 
+function escapeAttr(text) {
+    return String(text).replace(/&/g, '&amp;').replace(/"/g, '&quot;').replace(/</g, '&lt;');
+}
+
+const PHASE_KINDS = ['weather', 'screen', 'status', 'stat_change', 'adaptation', 'heal', 'custom'];
+
+// phaseParamsText formats a phase's parameters for its single parameter input
+function phaseParamsText(phase) {
+    const p = phase.params || {};
+    switch (phase.kind) {
+        case 'weather': return p.weather || '';
+        case 'screen': return p.screen || '';
+        case 'status': return p.status || '';
+        case 'stat_change':
+            return Object.entries(p.stats || {}).map(([stat, n]) => `${n > 0 ? '+' : ''}${n} ${stat}`).join(', ');
+        case 'heal': return p.heal ? String(p.heal) : '';
+        default: return '';
+    }
+}
+
+// parsePhaseParams reads the parameter input back, e.g. "+2 defense, +1 speed" for a stat change
+function parsePhaseParams(kind, text) {
+    text = text.trim();
+    switch (kind) {
+        case 'weather': return { weather: text };
+        case 'screen': return { screen: text };
+        case 'status': return { status: text };
+        case 'stat_change': {
+            const stats = {};
+            text.split(',').forEach(part => {
+                const m = part.trim().match(/^([+-]?\d+)\s+(\w+)$/);
+                if (m) stats[m[2]] = parseInt(m[1]);
+            });
+            return { stats };
+        }
+        case 'heal': return { heal: parseInt(text) || 0 };
+        default: return {};
+    }
+}
+
+const PHASE_PARAM_HINTS = {
+    weather: 'rain, sun, sand or hail',
+    screen: 'reflect or light_screen',
+    status: 'e.g. sleep, paralysis',
+    stat_change: 'e.g. +2 defense, +1 sp_defense',
+    heal: '% of max HP',
+};
+
 function renderPhasesList() {
     const tbody = document.getElementById('phaseList');
     tbody.innerHTML = '';
     phasesData.forEach((phase, idx) => {
         const row = document.createElement('tr');
+        const kindOptions = PHASE_KINDS.map(k =>
+            `<option value="${k}" ${phase.kind === k ? 'selected' : ''}>${k.replace('_', ' ')}</option>`).join('');
+        const hint = PHASE_PARAM_HINTS[phase.kind];
         row.innerHTML = `
             <td><input type="number" min="0" max="100" class="phase-health" value="${phase.health}" data-idx="${idx}" /></td>
-            <td><input type="text" class="phase-effect" value="${phase.effect}" data-idx="${idx}" /></td>
+            <td><select class="phase-kind" data-idx="${idx}">${kindOptions}</select></td>
+            <td><input type="text" class="phase-name" value="${escapeAttr(phase.name || '')}" data-idx="${idx}" /></td>
+            <td><input type="text" class="phase-params" value="${escapeAttr(phaseParamsText(phase))}" data-idx="${idx}"
+                ${hint ? `placeholder="${hint}"` : 'disabled'} /></td>
+            <td><input type="text" class="phase-description" value="${escapeAttr(phase.description || '')}" data-idx="${idx}" /></td>
             <td><button type="button" class="delete-phase btn-small" data-idx="${idx}">Delete</button></td>
         `;
         tbody.appendChild(row);
     });
 
     // Attach input listeners
-    document.querySelectorAll('.phase-health, .phase-effect').forEach(input => {
+    document.querySelectorAll('.phase-health, .phase-kind, .phase-name, .phase-params, .phase-description').forEach(input => {
         input.addEventListener('change', (e) => {
             const idx = parseInt(e.target.dataset.idx);
+            const phase = phasesData[idx];
             if (e.target.classList.contains('phase-health')) {
-                phasesData[idx].health = parseInt(e.target.value);
+                phase.health = parseInt(e.target.value);
+            } else if (e.target.classList.contains('phase-kind')) {
+                phase.kind = e.target.value;
+                phase.params = {};
+                renderPhasesList();
+            } else if (e.target.classList.contains('phase-name')) {
+                phase.name = e.target.value;
+            } else if (e.target.classList.contains('phase-params')) {
+                phase.params = parsePhaseParams(phase.kind, e.target.value);
             } else {
-                phasesData[idx].effect = e.target.value;
+                phase.description = e.target.value;
             }
             updatePhasesJSON();
         });
@@ -161,6 +225,76 @@ function renderVariationCarousel() {
         });
     });
 
+    // Boss health and notes per turn; the server requires one health value per player action
+    if (!variation.health_remaining) variation.health_remaining = [];
+    if (!variation.notes) variation.notes = [];
+    const turns = Math.max(variation.health_remaining.length,
+        ...['P1', 'P2', 'P3', 'P4'].map(p => variation.players[p].length));
+    const turnsTable = document.createElement('div');
+    turnsTable.className = 'player-table-container';
+    turnsTable.innerHTML = `
+        <h5 class="player-table-title">Turns</h5>
+        <table class="builder-table">
+            <thead><tr><th>Turn</th><th>Boss HP %</th><th>Notes</th></tr></thead>
+            <tbody class="turns-list"></tbody>
+        </table>
+    `;
+    const turnsBody = turnsTable.querySelector('.turns-list');
+    for (let t = 0; t < turns; t++) {
+        const row = document.createElement('tr');
+        const hp = variation.health_remaining[t];
+        row.innerHTML = `
+            <td>${t + 1}</td>
+            <td><input type="number" class="turn-health" min="0" max="100" step="any" data-idx="${t}" value="${hp == null ? '' : hp}" /></td>
+            <td><input type="text" class="turn-note" data-idx="${t}" placeholder="notes" /></td>
+        `;
+        row.querySelector('.turn-note').value = variation.notes[t] || '';
+        turnsBody.appendChild(row);
+    }
+    container.appendChild(turnsTable);
+
+    // who wrote the original guide; the server records the creator and last editor itself
+    const credits = document.createElement('div');
+    credits.className = 'player-table-container';
+    credits.innerHTML = `
+        <h5 class="player-table-title">Credits</h5>
+        <input type="text" class="variation-credits" maxlength="200" placeholder="e.g. original guide by …" />
+    `;
+    const creditsInput = credits.querySelector('.variation-credits');
+    creditsInput.value = variation.credits || '';
+    creditsInput.addEventListener('change', (e) => {
+        variation.credits = e.target.value.trim();
+        updateVariationsJSON();
+    });
+    if (variation.created_by || variation.updated_by) {
+        const byline = document.createElement('p');
+        byline.className = 'admin-empty';
+        const parts = [];
+        if (variation.created_by) parts.push(`Added by ${variation.created_by} on ${new Date(variation.created_at).toLocaleDateString()}`);
+        if (variation.updated_by) parts.push(`last edited by ${variation.updated_by} on ${new Date(variation.updated_at).toLocaleDateString()}`);
+        byline.textContent = parts.join(', ');
+        credits.appendChild(byline);
+    }
+    container.appendChild(credits);
+
+    turnsBody.querySelectorAll('.turn-health').forEach(input => {
+        input.addEventListener('change', (e) => {
+            const idx = parseInt(e.target.dataset.idx);
+            // fill skipped turns so the list stays aligned with the players
+            while (variation.health_remaining.length <= idx) variation.health_remaining.push(null);
+            variation.health_remaining[idx] = e.target.value === '' ? null : parseFloat(e.target.value);
+            updateVariationsJSON();
+        });
+    });
+    turnsBody.querySelectorAll('.turn-note').forEach(input => {
+        input.addEventListener('change', (e) => {
+            const idx = parseInt(e.target.dataset.idx);
+            while (variation.notes.length <= idx) variation.notes.push('');
+            variation.notes[idx] = e.target.value;
+            updateVariationsJSON();
+        });
+    });
+
     // Attach event listeners
     document.querySelectorAll('.poke-name, .poke-move, .poke-item').forEach(input => {
         input.addEventListener('change', (e) => {
@@ -209,6 +343,7 @@ document.addEventListener('DOMContentLoaded', function () {
     const season = document.getElementById('raidBossSeason').value;
     const action = document.getElementById('raidBossAction').value;
     const bossId = document.getElementById('raidBossId').value;
+    let bossRevision = 0;
 
     // Wait for MovesAutocomplete to initialize before rendering
     const initializeAdmin = async () => {
@@ -237,6 +372,7 @@ document.addEventListener('DOMContentLoaded', function () {
             const bossDataElem = document.getElementById('raid-boss-data');
             if (bossDataElem && bossDataElem.textContent && bossDataElem.textContent.trim().length > 0) {
                 const bossObj = JSON.parse(bossDataElem.textContent);
+                bossRevision = bossObj.revision || 0;
                 variationsData = bossObj.variations || JSON.parse(document.getElementById('bossVariations').value || '[]');
             } else {
                 variationsData = JSON.parse(document.getElementById('bossVariations').value || '[]');
@@ -309,7 +445,7 @@ document.addEventListener('DOMContentLoaded', function () {
         // Add phase button
         document.getElementById('addPhaseBtn').addEventListener('click', (e) => {
             e.preventDefault();
-            phasesData.push({ health: 100, effect: '' });
+            phasesData.push({ health: 100, kind: 'custom', name: '', params: {}, description: '' });
             renderPhasesList();
             updatePhasesJSON();
         });
@@ -322,19 +458,25 @@ document.addEventListener('DOMContentLoaded', function () {
                 description: formData.get('description'),
                 ability: formData.get('ability'),
                 held_item: formData.get('held_item'),
+                types: (formData.get('types') || '').split(',').map(t => t.trim()).filter(Boolean),
                 speed_evs: parseInt(formData.get('speed_evs')),
                 base_stats: {
+                    hp: parseInt(formData.get('base_stats_hp')),
                     speed: parseInt(formData.get('base_stats_speed')),
                     defense: parseInt(formData.get('base_stats_defense')),
                     special_defense: parseInt(formData.get('base_stats_spdef'))
                 },
                 moves: JSON.parse(formData.get('moves') || '[]'),
                 phase_effects: JSON.parse(formData.get('phase_effects') || '[]'),
-                variations: JSON.parse(formData.get('variations') || '[]')
+                // untouched placeholder variations are not saved
+                variations: JSON.parse(formData.get('variations') || '[]').filter(v =>
+                    (v.health_remaining || []).length > 0 ||
+                    Object.values(v.players || {}).some(list => list.length > 0))
             };
 
             if (action === 'edit') {
-                payload.id = parseInt(bossId);
+                payload.id = bossId;
+                payload.revision = bossRevision;
             }
 
             try {
@@ -345,7 +487,16 @@ document.addEventListener('DOMContentLoaded', function () {
                     body: JSON.stringify(payload)
                 });
                 if (response.ok) {
+                    const body = await response.json();
+                    if (body.warnings && body.warnings.length) {
+                        alert('Saved, but some names are not in the game data:\n' + body.warnings.map(f => `${f.field}: ${f.message}`).join('\n'));
+                    }
                     window.location.href = '/admin?tab=raid-bosses';
+                } else if (response.status === 409) {
+                    alert('This boss was changed by someone else while you were editing. Reload the page to see their changes, then re-apply yours.');
+                } else if (response.status === 400 && response.headers.get('Content-Type')?.includes('application/json')) {
+                    const body = await response.json();
+                    alert('Please fix the following:\n' + (body.fields || []).map(f => `${f.field}: ${f.message}`).join('\n'));
                 } else {
                     alert('Failed to save boss');
                 }
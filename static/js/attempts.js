@@ -0,0 +1,138 @@
+// attempts.js - Log raid attempts, show each variation's success rate and sort variations by it
+
+// the ranked order the server rendered the variations in
+let rankedVariationIds = [];
+
+document.addEventListener('DOMContentLoaded', () => {
+    if (!document.querySelector('.variation-block')) return;
+    rankedVariationIds = Array.from(document.querySelectorAll('.variation-block')).map(b => b.dataset.variationId);
+    loadAttemptStats('');
+
+    const sort = document.getElementById('variationSort');
+    if (sort) sort.addEventListener('change', () => loadAttemptStats(sort.value));
+
+    document.querySelectorAll('.log-attempt-btn').forEach(btn => {
+        btn.addEventListener('click', () => toggleAttemptForm(btn.closest('.variation-block')));
+    });
+});
+
+async function loadAttemptStats(sortBy) {
+    const bossData = JSON.parse(document.getElementById('boss-data').textContent);
+    const params = new URLSearchParams({ boss_id: bossData.id });
+    if (sortBy) params.set('sort', sortBy);
+    try {
+        const response = await fetch('/api/attempts/stats?' + params.toString());
+        if (!response.ok) return;
+        const data = await response.json();
+        data.variations.forEach(renderVariationStats);
+        reorderVariations(sortBy ? data.variations.map(s => s.variation_id) : rankedVariationIds);
+    } catch (err) {
+        console.error('Failed to load attempt stats:', err);
+    }
+}
+
+function renderVariationStats(stats) {
+    const line = document.querySelector(`.variation-block[data-variation-id="${stats.variation_id}"] .variation-stats`);
+    if (!line) return;
+    if (!stats.attempts) {
+        line.textContent = 'No attempts logged yet';
+    } else {
+        const parts = [
+            `${stats.attempts} attempt${stats.attempts === 1 ? '' : 's'}`,
+            `${Math.round(stats.clear_rate * 100)}% cleared`,
+        ];
+        if (stats.clears) parts.push(`${stats.avg_turns.toFixed(1)} turns to clear on average`);
+        line.textContent = parts.join(' · ');
+    }
+    line.hidden = false;
+}
+
+// reorderVariations moves the variation blocks into the given order
+function reorderVariations(ids) {
+    const area = document.querySelector('.tables-area');
+    if (!area) return;
+    ids.forEach(id => {
+        const block = area.querySelector(`.variation-block[data-variation-id="${id}"]`);
+        if (block) area.appendChild(block);
+    });
+}
+
+function toggleAttemptForm(block) {
+    const existing = block.querySelector('.attempt-form');
+    if (existing) {
+        existing.remove();
+        return;
+    }
+    const table = block.querySelector('.variation-table');
+    const turns = table.querySelectorAll('tbody tr').length;
+
+    // prefill the boss HP recorded in a live session
+    const recorded = Array.from(table.querySelectorAll('.actual-health')).map(input => input.value);
+    while (recorded.length && recorded[recorded.length - 1] === '') recorded.pop();
+
+    const form = document.createElement('form');
+    form.className = 'calc-form attempt-form';
+    form.innerHTML = `
+        <label>Result
+            <select name="cleared">
+                <option value="true">Cleared</option>
+                <option value="false">Failed</option>
+            </select>
+        </label>
+        <label>Turn reached <input type="number" name="turn_reached" min="1" max="50" required></label>
+        <label>Boss HP % per turn <input type="text" name="health" placeholder="e.g. 80, 62, 40"></label>
+        <label class="attempt-deviations">Deviations, one per line
+            <textarea name="deviations" rows="3" placeholder="3, P2: used Protect instead of Tailwind"></textarea>
+        </label>
+        <button type="submit" class="auth-btn">Save attempt</button>
+        <p class="calc-result attempt-result"></p>`;
+    form.elements.turn_reached.value = recorded.length || turns;
+    form.elements.health.value = recorded.join(', ');
+    form.addEventListener('submit', (e) => {
+        e.preventDefault();
+        saveAttempt(block, form);
+    });
+    block.querySelector('.variation-header').after(form);
+}
+
+// parseDeviations reads lines like "3, P2: used Protect" or "5: boss crit"
+function parseDeviations(text) {
+    return text.split('\n').map(line => line.trim()).filter(Boolean).map(line => {
+        const m = line.match(/^(\d+)\s*(?:,?\s*(P[1-4]))?\s*[:\-]\s*(.+)$/i);
+        if (!m) return { turn: 0, note: line };
+        return { turn: Number(m[1]), player: m[2] ? m[2].toUpperCase() : '', note: m[3] };
+    });
+}
+
+async function saveAttempt(block, form) {
+    const bossData = JSON.parse(document.getElementById('boss-data').textContent);
+    const result = form.querySelector('.attempt-result');
+    const health = form.elements.health.value.split(',').map(v => v.trim()).filter(Boolean).map(Number);
+    const payload = {
+        boss_id: bossData.id,
+        variation_id: block.dataset.variationId,
+        cleared: form.elements.cleared.value === 'true',
+        turn_reached: Number(form.elements.turn_reached.value),
+        health,
+        deviations: parseDeviations(form.elements.deviations.value),
+    };
+    const response = await fetch('/api/attempts', {
+        method: 'POST',
+        headers: { 'Content-Type': 'application/json' },
+        body: JSON.stringify(payload),
+    });
+    if (response.status === 400) {
+        const data = await response.json();
+        result.textContent = data.fields.map(f => `${f.field} ${f.message}`).join('; ');
+        result.className = 'calc-result attempt-result error';
+        return;
+    }
+    if (!response.ok) {
+        result.textContent = (await response.text()).trim();
+        result.className = 'calc-result attempt-result error';
+        return;
+    }
+    form.remove();
+    const sort = document.getElementById('variationSort');
+    loadAttemptStats(sort ? sort.value : '');
+}
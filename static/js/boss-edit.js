@@ -115,36 +115,48 @@ function enterEditMode(varIndex) {
     editMode[varIndex] = true;
 }
 
+const PLAYER_ACTIONS = ['move', 'switch', 'item', 'pass'];
+const ACTION_TARGETS = ['', 'boss', 'P1', 'P2', 'P3', 'P4', 'add'];
+
+function selectOptions(values, selected, emptyLabel) {
+    return values.map(v =>
+        `<option value="${v}" ${v === selected ? 'selected' : ''}>${v || emptyLabel}</option>`).join('');
+}
+
 // Convert player cell to editable with autocomplete
 function convertPlayerCellToEditable(cell, turnIdx, playerIdx) {
     const label = cell.querySelector('.player-action');
-    if (!label) {
-        // Empty cell with just "—"
-        cell.innerHTML = `
-            <div class="editable-player-cell" data-turn="${turnIdx}" data-player="${playerIdx}">
-                <input type="text" class="pokemon-input" placeholder="Pokemon" data-field="pokemon">
-                <input type="text" class="move-input" placeholder="Move" data-field="move">
-                <input type="text" class="item-input" placeholder="Item (optional)" data-field="item">
-            </div>
-        `;
-    } else {
-        // Extract existing data
-        const nameDiv = label.querySelector('.player-name');
-        const moveDiv = label.querySelector('.player-move');
-        const itemDiv = label.querySelector('.player-item');
-
-        const pokemon = nameDiv ? nameDiv.textContent : '';
-        const move = moveDiv ? moveDiv.textContent : '';
-        const item = itemDiv ? itemDiv.textContent : '';
-
-        cell.innerHTML = `
-            <div class="editable-player-cell" data-turn="${turnIdx}" data-player="${playerIdx}">
-                <input type="text" class="pokemon-input" placeholder="Pokemon" value="${escapeHtml(pokemon)}" data-field="pokemon">
-                <input type="text" class="move-input" placeholder="Move" value="${escapeHtml(move)}" data-field="move">
-                <input type="text" class="item-input" placeholder="Item (optional)" value="${escapeHtml(item)}" data-field="item">
-            </div>
-        `;
-    }
+    // Extract existing data; an empty cell with just "—" starts as a blank move
+    const meta = label ? label.querySelector('.player-meta').dataset : {};
+    const nameDiv = label ? label.querySelector('.player-name') : null;
+    const itemDiv = label ? label.querySelector('.player-item') : null;
+
+    const pokemon = nameDiv ? nameDiv.textContent : '';
+    const action = meta.action || 'move';
+    const move = meta.move || '';
+    const target = meta.target || '';
+    const item = itemDiv ? itemDiv.textContent : '';
+    const note = meta.note || '';
+
+    cell.innerHTML = `
+        <div class="editable-player-cell" data-turn="${turnIdx}" data-player="${playerIdx}">
+            <input type="text" class="pokemon-input" placeholder="Pokemon" value="${escapeHtml(pokemon)}" data-field="pokemon">
+            <select class="action-input" data-field="action">${selectOptions(PLAYER_ACTIONS, action)}</select>
+            <input type="text" class="move-input" placeholder="Move" value="${escapeHtml(move)}" data-field="move">
+            <select class="target-input" data-field="target">${selectOptions(ACTION_TARGETS, target, 'target')}</select>
+            <input type="text" class="item-input" placeholder="Item (optional)" value="${escapeHtml(item)}" data-field="item">
+            <input type="text" class="player-note-input" placeholder="Note (optional)" value="${escapeHtml(note)}" data-field="note">
+        </div>
+    `;
+
+    // only move actions have a move
+    const actionInput = cell.querySelector('.action-input');
+    const syncMoveInput = () => {
+        const moveInput = cell.querySelector('.move-input');
+        moveInput.hidden = actionInput.value !== 'move';
+    };
+    actionInput.addEventListener('change', syncMoveInput);
+    syncMoveInput();
 
     // Attach autocomplete to inputs
     const editableCell = cell.querySelector('.editable-player-cell');
@@ -561,14 +573,20 @@ async function saveVariation(varIndex) {
             const editableCell = cell.querySelector('.editable-player-cell');
             if (editableCell) {
                 const pokemon = editableCell.querySelector('.pokemon-input').value.trim();
-                const move = editableCell.querySelector('.move-input').value.trim();
+                const action = editableCell.querySelector('.action-input').value;
+                const move = action === 'move' ? editableCell.querySelector('.move-input').value.trim() : '';
+                const target = editableCell.querySelector('.target-input').value;
                 const item = editableCell.querySelector('.item-input').value.trim();
+                const note = editableCell.querySelector('.player-note-input').value.trim();
 
                 const playerKey = `P${i}`;
                 players[playerKey].push({
                     pokemon: pokemon,
+                    action: action,
                     move: move,
-                    item: item
+                    target: target,
+                    item: item,
+                    note: note
                 });
             }
         }
@@ -584,29 +602,29 @@ async function saveVariation(varIndex) {
         notes.push(note);
     });
 
-    // Get boss name from page data
+    // Get boss ID from page data
     const bossData = JSON.parse(document.getElementById('boss-data').textContent);
-
-    // Boss name property is lowercase in JSON (from Go serialization)
-    const bossName = bossData.name || bossData.Name;
-    if (!bossName) {
-        console.error('❌ Boss name not found in boss data:', bossData);
-        alert('Error: Could not determine boss name. Please refresh and try again.');
+    if (!bossData.id) {
+        console.error('❌ Boss ID not found in boss data:', bossData);
+        alert('Error: Could not determine boss. Please refresh and try again.');
         return;
     }
 
-    // Remove the star symbol from boss name if present (e.g., "Raichu 3★" -> "Raichu 3")
-    const cleanBossName = bossName.replace(/\s*★\s*$/, '').trim();
-
-    // Send to server
+    // Send to server; a table without a variation ID (team builder) creates a new variation
     try {
         const payload = {
-            boss_name: cleanBossName,
-            variation_index: parseInt(varIndex),
+            boss_id: bossData.id,
+            variation_id: varTable.dataset.variationId || '',
             players: players,
             health_remaining: healthRemaining,
             notes: notes
         };
+        const creditsInput = document.querySelector(`.credits-edit[data-variation-index="${varIndex}"] .credits-input`);
+        if (creditsInput) payload.credits = creditsInput.value.trim();
+        if (payload.variation_id) {
+            // the revision this edit is based on; the server rejects stale saves with 409
+            payload.revision = parseInt(varTable.dataset.variationRevision || '0');
+        }
 
         const response = await fetch('/api/boss/save-variation', {
             method: 'POST',
@@ -616,9 +634,28 @@ async function saveVariation(varIndex) {
 
         const responseText = await response.text();
 
+        if (response.status === 400 && response.headers.get('Content-Type')?.includes('application/json')) {
+            const body = JSON.parse(responseText);
+            alert('Please fix the following:\n' + (body.fields || []).map(f => `${f.field}: ${f.message}`).join('\n'));
+            return;
+        }
+        if (response.status === 409) {
+            alert('This variation was changed by someone else while you were editing. Reload the page to see their changes, then re-apply yours.');
+            return;
+        }
         if (!response.ok) {
             throw new Error(`Server error: ${response.status} - ${responseText}`);
         }
+        if (response.status === 202) {
+            // authors and visitors submit for review; the live variation is unchanged until approved
+            alert('Thanks! Your variation was submitted and will appear once a moderator approves it.');
+            cancelEditMode(varIndex);
+            return;
+        }
+        const body = JSON.parse(responseText);
+        if (body.warnings && body.warnings.length) {
+            alert('Saved, but some names are not in the game data:\n' + body.warnings.map(f => `${f.field}: ${f.message}`).join('\n'));
+        }
 
         // Reload page to show updated data
         window.location.reload();
@@ -635,6 +672,8 @@ function cancelEditMode(varIndex) {
 
     // Restore original HTML
     tbody.innerHTML = originalTableData[varIndex];
+    const creditsInput = document.querySelector(`.credits-edit[data-variation-index="${varIndex}"] .credits-input`);
+    if (creditsInput) creditsInput.value = creditsInput.defaultValue;
 
     // Toggle buttons
     toggleEditButtons(varIndex, false);
@@ -648,8 +687,9 @@ function toggleEditButtons(varIndex, isEditing) {
     const varTable = document.querySelector(`.variation-table[data-variation-index="${varIndex}"]`);
     if (!varTable) return;
 
-    // Find the header that's a sibling or parent of this variation table
-    const header = varTable.previousElementSibling;
+    // Boss pages wrap each variation in a block; the team builder puts the header right before the table
+    const block = varTable.closest('.variation-block');
+    const header = block ? block.querySelector('.variation-header') : varTable.previousElementSibling;
     if (!header || !header.classList.contains('variation-header')) return;
 
     const editBtn = header.querySelector(`.edit-variation-btn[data-variation-index="${varIndex}"]`);
@@ -658,6 +698,9 @@ function toggleEditButtons(varIndex, isEditing) {
 
     if (!editBtn || !saveBtn || !cancelBtn) return;
 
+    const credits = block && block.querySelector('.credits-edit');
+    if (credits) credits.hidden = !isEditing;
+
     if (isEditing) {
         editBtn.style.display = 'none';
         saveBtn.style.display = 'inline-block';
@@ -0,0 +1,92 @@
+// calc.js - Battle calculators on the boss page
+
+document.addEventListener('DOMContentLoaded', () => {
+    const speedForm = document.getElementById('speedCalcForm');
+    if (speedForm) {
+        speedForm.addEventListener('submit', (e) => {
+            e.preventDefault();
+            runSpeedCalc(speedForm);
+        });
+    }
+    const damageForm = document.getElementById('damageCalcForm');
+    if (damageForm) {
+        damageForm.addEventListener('submit', (e) => {
+            e.preventDefault();
+            runDamageCalc(damageForm);
+        });
+    }
+});
+
+// Call a calculator endpoint with the form's values and this boss's id.
+// Returns the parsed response, or null after showing the error in result.
+async function fetchCalc(url, form, result) {
+    const bossData = JSON.parse(document.getElementById('boss-data').textContent);
+    const params = new URLSearchParams({ boss_id: bossData.id });
+    new FormData(form).forEach((value, key) => {
+        if (value !== '') params.set(key, value === 'on' ? 'true' : value);
+    });
+
+    try {
+        const response = await fetch(url + '?' + params.toString());
+        if (!response.ok) {
+            const text = await response.text();
+            let message = text;
+            try {
+                message = (JSON.parse(text).fields || []).map(f => `${f.field}: ${f.message}`).join(', ');
+            } catch (err) { /* plain text error */ }
+            showCalcResult(result, message, 'error');
+            return null;
+        }
+        return await response.json();
+    } catch (err) {
+        console.error('Calculation failed:', err);
+        showCalcResult(result, 'Failed to calculate', 'error');
+        return null;
+    }
+}
+
+function showCalcResult(result, text, kind) {
+    result.textContent = text;
+    result.className = 'calc-result ' + kind;
+}
+
+// Ask the server whether the entered Pokémon outspeeds this boss
+async function runSpeedCalc(form) {
+    const result = document.getElementById('speedCalcResult');
+    const data = await fetchCalc('/api/calc/speed', form, result);
+    if (!data) return;
+
+    const verdict = {
+        pokemon: `${data.pokemon.name} moves first`,
+        boss: `${data.boss.name} moves first`,
+        tie: 'Speed tie, turn order is random'
+    }[data.moves_first];
+    showCalcResult(result,
+        `${verdict}: ${data.pokemon.effective_speed} vs ${data.boss.effective_speed} ` +
+        `(boss Lv. ${data.boss.level}, base ${data.boss.base_speed}, ${data.boss.speed_evs} EVs)` +
+        (data.trick_room ? ' under Trick Room' : ''),
+        data.moves_first === 'pokemon' ? 'ok' : 'warn');
+}
+
+// Estimate how much of the boss's HP one hit takes
+async function runDamageCalc(form) {
+    const result = document.getElementById('damageCalcResult');
+    const data = await fetchCalc('/api/calc/damage', form, result);
+    if (!data) return;
+
+    let text = `${data.attacker.name}'s ${data.move.name}: ${data.min}–${data.max} damage`;
+    if (data.max_percent !== undefined) {
+        text += ` (${data.min_percent}–${data.max_percent}% of ${data.boss.hp} HP`;
+        if (data.hits_to_ko) {
+            const [best, worst] = data.hits_to_ko;
+            text += best === worst ? `, ${best} hits to KO` : `, ${best}–${worst} hits to KO`;
+        }
+        text += ')';
+    }
+    const screens = [data.boss.reflect && 'Reflect', data.boss.light_screen && 'Light Screen'].filter(Boolean);
+    if (screens.length) text += ` — boss has ${screens.join(' and ')} up`;
+    if (data.modifiers.length) {
+        text += ` [${data.modifiers.map(m => `${m.name} x${m.value}`).join(', ')}]`;
+    }
+    showCalcResult(result, text, 'ok');
+}
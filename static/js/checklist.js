@@ -1,18 +1,56 @@
 /**
  * Checklist functionality for Pokemon raid team building
- * Checkbox states stored in localStorage only (client-side) for ALL users
- * This is for personal tracking and does not persist to server
+ * Checkbox states are stored on the server for signed-in users, so progress
+ * follows them across devices, and in localStorage for everyone else
  */
 
 let checklistData = {};
 let currentSeason = '';
+let resistantPokemon = new Set();
 const STORAGE_KEY = 'pokemmoraids_checklist_state';
 
 // Initialize checklist on page load
 document.addEventListener('DOMContentLoaded', function () {
     loadChecklist();
+
+    const resistSelect = document.getElementById('resistBossSelect');
+    if (resistSelect) {
+        resistSelect.addEventListener('change', () => loadResistHighlight(resistSelect.value));
+    }
 });
 
+/**
+ * Fetch which checklist Pokemon resist every move of a boss and highlight them
+ */
+async function loadResistHighlight(bossId) {
+    resistantPokemon = new Set();
+    if (bossId) {
+        try {
+            const response = await fetch(`/api/types/boss?boss_id=${encodeURIComponent(bossId)}`);
+            if (!response.ok) {
+                throw new Error('Failed to load boss matchups');
+            }
+            const data = await response.json();
+            resistantPokemon = new Set(data.resistant_pokemon || []);
+        } catch (error) {
+            console.error('Error loading boss matchups:', error);
+        }
+    }
+    applyResistHighlight();
+}
+
+/**
+ * Mark rows of Pokemon that resist the selected boss's full moveset
+ */
+function applyResistHighlight() {
+    document.querySelectorAll('.pokemon-row').forEach(row => {
+        const checkbox = row.querySelector('.pokemon-checkbox');
+        const resists = checkbox && resistantPokemon.has(checkbox.dataset.pokemonName);
+        row.classList.toggle('resists-boss', resists);
+        row.title = resists ? 'Resists every move of the selected boss' : '';
+    });
+}
+
 /**
  * Get the current season
  */
@@ -22,7 +60,6 @@ function getCurrentSeason() {
 
 /**
  * Fetch checklist data from the API and render it
- * Checkbox states stored in localStorage for ALL users (client-side only)
  */
 async function loadChecklist() {
     try {
@@ -34,8 +71,10 @@ async function loadChecklist() {
         currentSeason = checklistData.season || '';
         renderChecklist();
 
-        // Restore checkbox states from localStorage for all users
-        restoreChecklistState();
+        // signed-in users get their completion state from the server
+        if (!checklistData.user) {
+            restoreChecklistState();
+        }
     } catch (error) {
         console.error('Error loading checklist:', error);
         const container = document.getElementById('checklist-container');
@@ -92,6 +131,7 @@ function renderChecklist() {
         const typeSection = createTypeSection(typeData, typeIndex);
         container.appendChild(typeSection);
     });
+    applyResistHighlight();
 
     // Add event listener for pin star checkboxes
     const pinStars = container.querySelectorAll('.pin-star');
@@ -377,12 +417,34 @@ function toggleTypeContent(typeName) {
 
 /**
  * Handle pokemon checkbox toggle
- * Client-side only (localStorage) for ALL users
+ * Saved to the server for signed-in users, otherwise to localStorage
  */
-function handlePokemonToggle(pokemonName, pokemonUsage, isChecked) {
-    const pokemonKey = `${pokemonName}-${pokemonUsage}`;
+async function handlePokemonToggle(pokemonName, pokemonUsage, isChecked) {
+    if (checklistData.user) {
+        try {
+            const response = await fetch('/api/checklist/toggle', {
+                method: 'POST',
+                headers: { 'Content-Type': 'application/json' },
+                body: JSON.stringify({ name: pokemonName, usage: pokemonUsage })
+            });
+            if (!response.ok) throw new Error(await response.text());
+            isChecked = (await response.json()).completed;
+        } catch (err) {
+            console.error('Error saving checklist state:', err);
+            isChecked = !isChecked;
+        }
+    }
+    setPokemonChecked(pokemonName, pokemonUsage, isChecked);
+    if (!checklistData.user) {
+        saveChecklistState();
+    }
+}
 
-    // Update UI immediately for ALL occurrences of this Pokemon
+/**
+ * Check or uncheck every occurrence of a Pokemon and update the counts
+ */
+function setPokemonChecked(pokemonName, pokemonUsage, isChecked) {
+    // Update UI for ALL occurrences of this Pokemon
     const checkboxes = document.querySelectorAll(`input[data-pokemon-name="${pokemonName}"][data-pokemon-usage="${pokemonUsage}"]`);
     checkboxes.forEach(checkbox => {
         checkbox.checked = isChecked;
@@ -398,9 +460,6 @@ function handlePokemonToggle(pokemonName, pokemonUsage, isChecked) {
 
     // Update completion counts for all affected type sections
     updateCompletionCounts();
-
-    // Save state to localStorage
-    saveChecklistState();
 }
 
 /**
@@ -0,0 +1,207 @@
+// comments.js - Discussion threads on the boss and each variation, with replies and moderation
+
+document.addEventListener('DOMContentLoaded', () => {
+    document.querySelectorAll('.comments-btn').forEach(btn => {
+        btn.addEventListener('click', () => {
+            const thread = btn.closest('.variation-block').querySelector('.comment-thread');
+            thread.hidden = !thread.hidden;
+            if (!thread.hidden && !thread.dataset.loaded) loadComments(thread);
+        });
+    });
+
+    const discussion = document.getElementById('bossDiscussion');
+    if (discussion) {
+        discussion.addEventListener('toggle', () => {
+            const thread = discussion.querySelector('.comment-thread');
+            if (discussion.open && !thread.dataset.loaded) loadComments(thread);
+        });
+    }
+});
+
+async function loadComments(thread) {
+    const bossData = JSON.parse(document.getElementById('boss-data').textContent);
+    const params = new URLSearchParams({ boss_id: bossData.id, variation_id: thread.dataset.variationId });
+    try {
+        const response = await fetch('/api/comments?' + params.toString());
+        if (!response.ok) {
+            thread.textContent = 'Could not load comments.';
+            return;
+        }
+        const comments = await response.json();
+        thread.dataset.loaded = 'true';
+        renderThread(thread, comments);
+        updateCommentCount(thread, countVisible(comments));
+    } catch (err) {
+        console.error('Failed to load comments:', err);
+    }
+}
+
+function renderThread(thread, comments) {
+    thread.innerHTML = '';
+    if (!comments.length) {
+        const empty = document.createElement('p');
+        empty.className = 'comment-empty';
+        empty.textContent = 'No comments yet.';
+        thread.appendChild(empty);
+    } else {
+        thread.appendChild(renderCommentList(thread, comments));
+    }
+    if ('canPost' in thread.dataset) {
+        thread.appendChild(commentForm(thread, null));
+    } else {
+        const hint = document.createElement('p');
+        hint.className = 'comment-empty';
+        hint.innerHTML = '<a href="/auth/login">Sign in</a> to join the discussion.';
+        thread.appendChild(hint);
+    }
+}
+
+function renderCommentList(thread, comments) {
+    const list = document.createElement('ul');
+    list.className = 'comment-list';
+    comments.forEach(c => list.appendChild(renderComment(thread, c)));
+    return list;
+}
+
+function renderComment(thread, c) {
+    const item = document.createElement('li');
+    item.className = 'comment' + (c.hidden ? ' comment-hidden' : '');
+
+    const meta = document.createElement('div');
+    meta.className = 'comment-meta';
+    if (c.deleted) {
+        meta.textContent = '[deleted]';
+    } else if (!c.html) {
+        meta.textContent = '[hidden by a moderator]';
+    } else {
+        const author = document.createElement('strong');
+        author.textContent = c.author;
+        meta.appendChild(author);
+        const parts = [];
+        if (c.turn && !c.parent_id) parts.push(`Turn ${c.turn}`);
+        parts.push(new Date(c.created_at).toLocaleString());
+        if (c.hidden) parts.push('hidden');
+        meta.append(' · ' + parts.join(' · '));
+    }
+    item.appendChild(meta);
+
+    if (c.html) {
+        const body = document.createElement('div');
+        body.className = 'comment-body';
+        body.innerHTML = c.html; // sanitized by the server
+        item.appendChild(body);
+    }
+
+    const actions = document.createElement('div');
+    actions.className = 'comment-actions';
+    if ('canPost' in thread.dataset && !c.deleted) {
+        actions.appendChild(commentButton('Reply', () => {
+            const existing = item.querySelector(':scope > .comment-form');
+            if (existing) existing.remove();
+            else item.insertBefore(commentForm(thread, c.id), item.querySelector(':scope > .comment-list'));
+        }));
+    }
+    if ('canModerate' in thread.dataset && !c.deleted) {
+        actions.appendChild(commentButton(c.hidden ? 'Unhide' : 'Hide', () => moderateComment(thread, c.id, c.hidden ? 'unhide' : 'hide')));
+        actions.appendChild(commentButton('Delete', () => {
+            if (confirm('Delete this comment? Replies are kept.')) moderateComment(thread, c.id, 'delete');
+        }));
+    }
+    if (actions.children.length) item.appendChild(actions);
+
+    if (c.replies && c.replies.length) item.appendChild(renderCommentList(thread, c.replies));
+    return item;
+}
+
+function commentButton(label, onClick) {
+    const btn = document.createElement('button');
+    btn.type = 'button';
+    btn.className = 'comment-action';
+    btn.textContent = label;
+    btn.addEventListener('click', onClick);
+    return btn;
+}
+
+// commentForm posts a new comment, or a reply when parentId is set
+function commentForm(thread, parentId) {
+    const form = document.createElement('form');
+    form.className = 'calc-form comment-form';
+    const turns = Number(thread.dataset.turns);
+    let turnSelect = '';
+    if (!parentId && turns > 0) {
+        const options = ['<option value="0">Whole variation</option>'];
+        for (let t = 1; t <= turns; t++) options.push(`<option value="${t}">Turn ${t}</option>`);
+        turnSelect = `<label>About <select name="turn">${options.join('')}</select></label>`;
+    }
+    form.innerHTML = `
+        <textarea name="body" rows="3" maxlength="2000" required
+            placeholder="${parentId ? 'Write a reply' : 'Ask a question or share a tip'} (Markdown: **bold**, *italic*, \`code\`, - lists, [links](https://…))"></textarea>
+        ${turnSelect}
+        <button type="submit" class="auth-btn">${parentId ? 'Reply' : 'Post'}</button>
+        <span class="calc-result comment-status"></span>`;
+    form.addEventListener('submit', async e => {
+        e.preventDefault();
+        const bossData = JSON.parse(document.getElementById('boss-data').textContent);
+        const payload = {
+            boss_id: bossData.id,
+            variation_id: thread.dataset.variationId,
+            body: form.body.value,
+        };
+        if (parentId) payload.parent_id = parentId;
+        if (form.turn) payload.turn = Number(form.turn.value);
+        const status = form.querySelector('.comment-status');
+        const submit = form.querySelector('button[type="submit"]');
+        submit.disabled = true;
+        try {
+            const response = await fetch('/api/comments', {
+                method: 'POST',
+                headers: { 'Content-Type': 'application/json' },
+                body: JSON.stringify(payload),
+            });
+            if (!response.ok) {
+                status.textContent = await commentError(response);
+                return;
+            }
+            await loadComments(thread);
+        } finally {
+            submit.disabled = false;
+        }
+    });
+    return form;
+}
+
+async function moderateComment(thread, id, action) {
+    const response = await fetch('/api/admin/comments/moderate', {
+        method: 'POST',
+        headers: { 'Content-Type': 'application/json' },
+        body: JSON.stringify({ id, action }),
+    });
+    if (!response.ok) {
+        alert('Could not moderate comment: ' + (await response.text()).trim());
+        return;
+    }
+    await loadComments(thread);
+}
+
+async function commentError(response) {
+    const text = await response.text();
+    try {
+        const data = JSON.parse(text);
+        if (data.fields) return data.fields.map(f => `${f.field} ${f.message}`).join('; ');
+    } catch (err) {
+        // not a validation error
+    }
+    return text.trim();
+}
+
+function countVisible(comments) {
+    return comments.reduce((n, c) => n + (c.html && !c.hidden ? 1 : 0) + countVisible(c.replies || []), 0);
+}
+
+function updateCommentCount(thread, count) {
+    const id = thread.dataset.variationId;
+    const target = id
+        ? document.querySelector(`.comments-btn[data-variation-id="${id}"] .comment-count`)
+        : document.querySelector('#bossDiscussion .comment-count');
+    if (target) target.textContent = count;
+}
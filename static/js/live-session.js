@@ -0,0 +1,282 @@
+// live-session.js - Share turn checks, notes and actual boss HP with the rest of the raid
+// over Server-Sent Events, polling the session when EventSource is unavailable
+
+const SESSION_POLL_MS = 3000;
+
+let liveSession = null; // { code, table, version, source, pollTimer }
+
+document.addEventListener('DOMContentLoaded', () => {
+    document.querySelectorAll('.live-session-btn').forEach(btn => {
+        btn.addEventListener('click', () => startLiveSession(btn.dataset.variationId, btn));
+    });
+    const joinForm = document.getElementById('joinSessionForm');
+    if (joinForm) {
+        joinForm.addEventListener('submit', (e) => {
+            e.preventDefault();
+            joinLiveSession(joinForm.elements.code.value.trim().toUpperCase());
+        });
+    }
+    const code = new URLSearchParams(window.location.search).get('session');
+    if (code) joinLiveSession(code.toUpperCase());
+});
+
+function sessionStatus(text, isError) {
+    const status = document.getElementById('liveSessionStatus');
+    if (!status) return;
+    status.textContent = text;
+    status.className = 'calc-result' + (isError ? ' error' : '');
+}
+
+async function startLiveSession(variationId, btn) {
+    const bossData = JSON.parse(document.getElementById('boss-data').textContent);
+    btn.disabled = true;
+    try {
+        const response = await fetch('/api/raid-session', {
+            method: 'POST',
+            headers: { 'Content-Type': 'application/json' },
+            body: JSON.stringify({ boss_id: bossData.id, variation_id: variationId }),
+        });
+        if (!response.ok) {
+            alert('Could not start session: ' + (await response.text()).trim());
+            return;
+        }
+        const session = await response.json();
+        connectLiveSession(session);
+    } finally {
+        btn.disabled = false;
+    }
+}
+
+async function joinLiveSession(code) {
+    if (!code) return;
+    const response = await fetch('/api/raid-session?code=' + encodeURIComponent(code));
+    if (!response.ok) {
+        sessionStatus((await response.text()).trim(), true);
+        return;
+    }
+    const session = await response.json();
+    const bossData = JSON.parse(document.getElementById('boss-data').textContent);
+    if (session.boss_id !== bossData.id) {
+        window.location.href = `/boss?name=${encodeURIComponent(session.boss_name)}&session=${encodeURIComponent(session.code)}`;
+        return;
+    }
+    connectLiveSession(session);
+}
+
+function connectLiveSession(session) {
+    leaveLiveSession();
+    const table = document.querySelector(`.variation-table[data-variation-id="${session.variation_id}"]`);
+    if (!table) {
+        sessionStatus('This session\'s variation is no longer on this page.', true);
+        return;
+    }
+
+    const url = new URL(window.location.href);
+    url.searchParams.set('session', session.code);
+    history.replaceState(null, '', url);
+
+    liveSession = { code: session.code, table, version: -1, source: null, pollTimer: null };
+    enableLiveTable(table, session.code);
+    applySessionState(session);
+    table.scrollIntoView({ behavior: 'smooth', block: 'start' });
+
+    if (window.EventSource) {
+        const source = new EventSource('/api/raid-session/events?code=' + encodeURIComponent(session.code));
+        liveSession.source = source;
+        source.addEventListener('state', (e) => applySessionState(JSON.parse(e.data).session));
+        ['check', 'note', 'health'].forEach(type => {
+            source.addEventListener(type, (e) => applySessionEvent(JSON.parse(e.data)));
+        });
+        source.addEventListener('expired', () => endLiveSession('This session expired.'));
+        source.onerror = () => {
+            // EventSource retries on its own unless the stream was refused; fall back to polling then
+            if (source.readyState === EventSource.CLOSED && liveSession && liveSession.source === source) {
+                liveSession.source = null;
+                startSessionPolling();
+            }
+        };
+    } else {
+        startSessionPolling();
+    }
+}
+
+function startSessionPolling() {
+    if (!liveSession || liveSession.pollTimer) return;
+    const code = liveSession.code;
+    liveSession.pollTimer = setInterval(async () => {
+        try {
+            const response = await fetch('/api/raid-session?code=' + encodeURIComponent(code));
+            if (response.status === 404) {
+                endLiveSession('This session expired.');
+                return;
+            }
+            if (response.ok) applySessionState(await response.json());
+        } catch (err) {
+            console.error('Session poll failed:', err);
+        }
+    }, SESSION_POLL_MS);
+}
+
+function leaveLiveSession() {
+    if (!liveSession) return;
+    if (liveSession.source) liveSession.source.close();
+    if (liveSession.pollTimer) clearInterval(liveSession.pollTimer);
+    disableLiveTable(liveSession.table);
+    liveSession = null;
+    const url = new URL(window.location.href);
+    url.searchParams.delete('session');
+    history.replaceState(null, '', url);
+}
+
+function endLiveSession(message) {
+    leaveLiveSession();
+    sessionStatus(message, true);
+}
+
+// enableLiveTable adds the session banner and actual HP inputs and sends local edits
+function enableLiveTable(table, code) {
+    table.classList.add('live');
+    const banner = document.createElement('div');
+    banner.className = 'live-banner';
+    banner.innerHTML = '<span class="live-dot"></span> Live session <strong></strong> — share this code with your group. ';
+    banner.querySelector('strong').textContent = code;
+    const leave = document.createElement('button');
+    leave.type = 'button';
+    leave.className = 'auth-btn ghost';
+    leave.textContent = 'Leave';
+    leave.addEventListener('click', () => {
+        leaveLiveSession();
+        sessionStatus('You left the session.');
+    });
+    banner.appendChild(leave);
+    table.parentElement.insertBefore(banner, table);
+
+    table.querySelectorAll('tbody tr').forEach((row, turn) => {
+        const health = row.querySelector('.boss-health');
+        if (health) {
+            const input = document.createElement('input');
+            input.type = 'number';
+            input.min = 0;
+            input.max = 100;
+            input.step = 'any';
+            input.placeholder = 'actual %';
+            input.className = 'actual-health';
+            input.dataset.turnIndex = turn;
+            health.appendChild(input);
+        }
+        const note = row.querySelector('.note-input');
+        if (note) {
+            note.dataset.turnIndex = turn;
+            note.dataset.original = note.value;
+        }
+    });
+
+    table.addEventListener('change', onLiveTableChange);
+}
+
+function disableLiveTable(table) {
+    table.classList.remove('live');
+    table.removeEventListener('change', onLiveTableChange);
+    const banner = table.parentElement.querySelector('.live-banner');
+    if (banner) banner.remove();
+    table.querySelectorAll('.actual-health').forEach(el => el.remove());
+    table.querySelectorAll('.note-input').forEach(note => {
+        if (note.dataset.original !== undefined) note.value = note.dataset.original;
+    });
+}
+
+function onLiveTableChange(e) {
+    const el = e.target;
+    if (el.classList.contains('player-check')) {
+        sendSessionEvent({ type: 'check', player: Number(el.dataset.playerIndex), turn: Number(el.dataset.turnIndex), checked: el.checked });
+    } else if (el.classList.contains('note-input')) {
+        sendSessionEvent({ type: 'note', turn: Number(el.dataset.turnIndex), note: el.value });
+    } else if (el.classList.contains('actual-health')) {
+        const health = el.value === '' ? null : Number(el.value);
+        sendSessionEvent({ type: 'health', turn: Number(el.dataset.turnIndex), health });
+    }
+}
+
+async function sendSessionEvent(event) {
+    if (!liveSession) return;
+    const response = await fetch('/api/raid-session/update', {
+        method: 'POST',
+        headers: { 'Content-Type': 'application/json' },
+        body: JSON.stringify({ code: liveSession.code, ...event }),
+    });
+    if (response.status === 404) {
+        endLiveSession('This session expired.');
+    } else if (!response.ok) {
+        sessionStatus((await response.text()).trim(), true);
+    } else {
+        applySessionEvent(await response.json());
+    }
+}
+
+async function resyncLiveSession() {
+    const code = liveSession.code;
+    const response = await fetch('/api/raid-session?code=' + encodeURIComponent(code));
+    if (response.ok && liveSession && liveSession.code === code) {
+        const session = await response.json();
+        liveSession.version = Math.min(liveSession.version, session.version - 1);
+        applySessionState(session);
+    }
+}
+
+function setCheck(table, player, turn, checked) {
+    const chk = table.querySelector(`.player-check[data-player-index="${player}"][data-turn-index="${turn}"]`);
+    if (!chk) return;
+    chk.checked = checked;
+    const cell = chk.closest('.player-cell');
+    if (cell) cell.classList.toggle('completed', checked);
+}
+
+function setNote(table, turn, note) {
+    const input = table.querySelector(`.note-input[data-turn-index="${turn}"]`);
+    if (input && document.activeElement !== input) input.value = note;
+}
+
+function setHealth(table, turn, health) {
+    const input = table.querySelector(`.actual-health[data-turn-index="${turn}"]`);
+    if (input && document.activeElement !== input) input.value = health === null || health === undefined ? '' : health;
+}
+
+// applySessionState replaces the table's live state with the session's
+function applySessionState(session) {
+    if (!liveSession || session.version <= liveSession.version) return;
+    const table = liveSession.table;
+    liveSession.version = session.version;
+    table.querySelectorAll('.player-check').forEach(chk => {
+        setCheck(table, chk.dataset.playerIndex, chk.dataset.turnIndex, !!session.checks[`${chk.dataset.playerIndex}-${chk.dataset.turnIndex}`]);
+    });
+    table.querySelectorAll('.note-input').forEach(note => {
+        const turn = note.dataset.turnIndex;
+        setNote(table, turn, turn in session.notes ? session.notes[turn] : note.dataset.original);
+    });
+    table.querySelectorAll('.actual-health').forEach(input => {
+        setHealth(table, input.dataset.turnIndex, session.health[input.dataset.turnIndex]);
+    });
+}
+
+function applySessionEvent(event) {
+    if (!liveSession || event.version <= liveSession.version) return;
+    if (event.version > liveSession.version + 1) {
+        // an event was missed, e.g. our own update's reply overtook the stream
+        resyncLiveSession();
+    }
+    const table = liveSession.table;
+    liveSession.version = event.version;
+    switch (event.type) {
+    case 'check':
+        setCheck(table, event.player, event.turn, !!event.checked);
+        break;
+    case 'note': {
+        const input = table.querySelector(`.note-input[data-turn-index="${event.turn}"]`);
+        setNote(table, event.turn, event.note || (input ? input.dataset.original : ''));
+        break;
+    }
+    case 'health':
+        setHealth(table, event.turn, event.health);
+        break;
+    }
+}
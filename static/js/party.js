@@ -0,0 +1,148 @@
+// party.js - Claim lanes of a variation with a group and check everyone's Pokémon
+
+const PARTY_REFRESH_MS = 15000;
+
+document.addEventListener('DOMContentLoaded', () => {
+    // boss page: start a party for a variation
+    document.querySelectorAll('.plan-party-btn').forEach(btn => {
+        btn.addEventListener('click', () => createParty(btn.dataset.variationId, btn));
+    });
+
+    const page = document.getElementById('partyPage');
+    if (!page) return;
+    const code = page.dataset.code;
+
+    const link = document.getElementById('partyLink');
+    link.value = `${window.location.origin}/party?code=${encodeURIComponent(code)}`;
+    document.getElementById('copyPartyLink').addEventListener('click', async () => {
+        try {
+            await navigator.clipboard.writeText(link.value);
+        } catch (err) {
+            link.select();
+        }
+    });
+    document.getElementById('leaveParty').addEventListener('click', () => partyAction(code, 'leave'));
+    document.getElementById('finishParty').addEventListener('click', () => {
+        if (confirm('Mark this raid as done? The party will be closed.')) partyAction(code, 'done');
+    });
+
+    loadParty(code);
+    setInterval(() => loadParty(code), PARTY_REFRESH_MS);
+});
+
+async function createParty(variationId, btn) {
+    const bossData = JSON.parse(document.getElementById('boss-data').textContent);
+    btn.disabled = true;
+    try {
+        const response = await fetch('/api/party', {
+            method: 'POST',
+            headers: { 'Content-Type': 'application/json' },
+            body: JSON.stringify({ boss_id: bossData.id, variation_id: variationId }),
+        });
+        if (!response.ok) {
+            alert('Could not create party: ' + (await response.text()).trim());
+            return;
+        }
+        const party = await response.json();
+        window.location.href = '/party?code=' + encodeURIComponent(party.code);
+    } finally {
+        btn.disabled = false;
+    }
+}
+
+async function loadParty(code) {
+    const response = await fetch('/api/party?code=' + encodeURIComponent(code));
+    if (!response.ok) {
+        showPartyError((await response.text()).trim());
+        return;
+    }
+    renderParty(await response.json());
+}
+
+async function partyAction(code, action, position) {
+    const response = await fetch('/api/party/action', {
+        method: 'POST',
+        headers: { 'Content-Type': 'application/json' },
+        body: JSON.stringify({ code, action, position: position || '' }),
+    });
+    if (!response.ok) {
+        alert((await response.text()).trim());
+        loadParty(code);
+        return;
+    }
+    renderParty(await response.json());
+}
+
+function showPartyError(message) {
+    document.getElementById('partyStatus').textContent = message;
+    document.getElementById('partyStatus').className = 'party-status error';
+    document.getElementById('partyLanes').innerHTML = '';
+    document.getElementById('partyActions').hidden = true;
+}
+
+function renderParty(party) {
+    const page = document.getElementById('partyPage');
+    const username = page.dataset.username;
+    const open = party.status === 'open';
+    const isMember = party.members.some(m => m.username === username);
+
+    const title = party.variation ? `${party.boss_name} — Variation ${party.variation}` : party.boss_name;
+    document.getElementById('partyTitle').textContent = title;
+    document.getElementById('partyBossName').textContent = party.boss_name;
+
+    const status = document.getElementById('partyStatus');
+    if (!open) {
+        status.textContent = 'This raid is done.';
+        status.className = 'party-status';
+    } else if (!party.variation) {
+        status.textContent = 'This variation no longer exists.';
+        status.className = 'party-status error';
+    } else if (party.ready) {
+        status.textContent = 'Everyone has their Pokémon — ready to raid!';
+        status.className = 'party-status ok';
+    } else {
+        status.textContent = `Led by ${party.leader}. Claim a lane; Pokémon not checked off on a member's checklist are listed as missing.`;
+        status.className = 'party-status';
+    }
+
+    const lanes = document.getElementById('partyLanes');
+    lanes.innerHTML = '';
+    party.lanes.forEach(lane => {
+        const card = document.createElement('div');
+        card.className = 'party-lane' + (lane.member && !lane.missing.length ? ' ready' : '');
+
+        const heading = document.createElement('h3');
+        heading.textContent = lane.position;
+        card.appendChild(heading);
+
+        const pokemon = document.createElement('p');
+        pokemon.className = 'party-lane-pokemon';
+        pokemon.textContent = lane.pokemon.length ? lane.pokemon.join(', ') : 'No Pokémon in this lane';
+        card.appendChild(pokemon);
+
+        const member = document.createElement('p');
+        member.className = 'party-lane-member';
+        member.textContent = lane.member ? `Claimed by ${lane.member}` : 'Open';
+        card.appendChild(member);
+
+        if (lane.missing.length) {
+            const missing = document.createElement('p');
+            missing.className = 'party-lane-missing';
+            missing.textContent = 'Missing: ' + lane.missing.join(', ');
+            card.appendChild(missing);
+        }
+
+        if (open && username && !lane.member) {
+            const join = document.createElement('button');
+            join.type = 'button';
+            join.className = 'auth-btn';
+            join.textContent = isMember ? 'Switch here' : 'Claim';
+            join.addEventListener('click', () => partyAction(party.code, 'join', lane.position));
+            card.appendChild(join);
+        }
+        lanes.appendChild(card);
+    });
+
+    document.getElementById('partyActions').hidden = !open || !(isMember || party.leader === username);
+    document.getElementById('leaveParty').hidden = !isMember;
+}
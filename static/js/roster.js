@@ -0,0 +1,89 @@
+// roster.js - Show which variations the user's checklist Pokémon can field
+
+const CHECKLIST_STORAGE_KEY = 'pokemmoraids_checklist_state';
+
+document.addEventListener('DOMContentLoaded', loadRoster);
+
+// localChecklistPokemon returns the Pokémon checked off in this browser; keys are "Name-Usage"
+function localChecklistPokemon() {
+    try {
+        const keys = JSON.parse(localStorage.getItem(CHECKLIST_STORAGE_KEY) || '[]');
+        return [...new Set(keys.map(key => key.includes('-') ? key.slice(0, key.lastIndexOf('-')) : key))];
+    } catch (err) {
+        return [];
+    }
+}
+
+async function loadRoster() {
+    const panel = document.getElementById('rosterPanel');
+    if (!panel) return;
+    const bossData = JSON.parse(document.getElementById('boss-data').textContent);
+    // signed-in users are matched with their server checklist and the list is ignored
+    const params = new URLSearchParams({ boss_id: bossData.id, pokemon: localChecklistPokemon().join(',') });
+    try {
+        const response = await fetch('/api/roster?' + params.toString());
+        if (!response.ok) {
+            panel.hidden = true;
+            return;
+        }
+        renderRoster(panel, await response.json());
+    } catch (err) {
+        console.error('Failed to load roster:', err);
+        panel.hidden = true;
+    }
+}
+
+function renderRoster(panel, report) {
+    const result = panel.querySelector('.roster-result');
+    result.innerHTML = '';
+    if (!report.owned.length) {
+        result.textContent = 'Check off the Pokémon you have built on the home page checklist to see which variations you can field.';
+        return;
+    }
+
+    const boss = report.bosses[0] || { ready: [], one_short: [] };
+    const summary = panel.querySelector('summary');
+    summary.textContent = `Your roster: ${boss.ready.length} ready, ${boss.one_short.length} one Pokémon short`;
+
+    boss.ready.forEach(v => markVariation(v, 'ready', `✔ You can play ${v.best.position}`));
+    boss.one_short.forEach(v => markVariation(v, 'short', `Needs ${v.best.missing[0]} for ${v.best.position}`));
+
+    result.appendChild(rosterList('Ready', boss.ready, v => `Variation ${v.variation} as ${v.best.position} (${v.best.pokemon.join(', ')})`));
+    result.appendChild(rosterList('One Pokémon short', boss.one_short, v => `Variation ${v.variation}: build ${v.best.missing[0]} to play ${v.best.position}`));
+    result.appendChild(rosterList('Build next (this season)', report.unlocks.slice(0, 5), u => {
+        const bosses = [...new Set(u.variations.map(v => v.boss_name))].join(', ');
+        return `${u.pokemon} unlocks ${u.count} variation${u.count === 1 ? '' : 's'} (${bosses})`;
+    }));
+}
+
+function rosterList(title, items, format) {
+    const section = document.createElement('div');
+    const heading = document.createElement('h4');
+    heading.textContent = title;
+    section.appendChild(heading);
+    if (!items.length) {
+        const empty = document.createElement('p');
+        empty.className = 'roster-empty';
+        empty.textContent = 'None yet';
+        section.appendChild(empty);
+        return section;
+    }
+    const list = document.createElement('ul');
+    items.forEach(item => {
+        const li = document.createElement('li');
+        li.textContent = format(item);
+        list.appendChild(li);
+    });
+    section.appendChild(list);
+    return section;
+}
+
+// markVariation adds a badge to the header of a variation on the page
+function markVariation(v, status, text) {
+    const target = document.querySelector(`.variation-block[data-variation-id="${v.variation_id}"] .variation-title`);
+    if (!target) return;
+    const badge = document.createElement('span');
+    badge.className = `roster-badge ${status}`;
+    badge.textContent = text;
+    target.appendChild(badge);
+}
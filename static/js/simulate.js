@@ -0,0 +1,113 @@
+// simulate.js - Annotate variation tables with a simulated playthrough
+
+document.addEventListener('DOMContentLoaded', () => {
+    document.querySelectorAll('.simulate-variation-btn').forEach(btn => {
+        btn.addEventListener('click', () => simulateVariation(btn.dataset.variationIndex, btn));
+    });
+
+    // remove annotations before the editor reads the table cells
+    document.addEventListener('click', (e) => {
+        const editBtn = e.target.closest('.edit-variation-btn');
+        if (!editBtn) return;
+        const index = editBtn.dataset.variationIndex;
+        const container = document.querySelector(`.variation-table[data-variation-index="${index}"]`);
+        const summary = document.querySelector(`.simulation-summary[data-variation-index="${index}"]`);
+        const simBtn = document.querySelector(`.simulate-variation-btn[data-variation-index="${index}"]`);
+        if (container && summary && container.classList.contains('simulated')) {
+            clearSimulation(container, summary);
+            if (simBtn) simBtn.textContent = '▶ Simulate';
+        }
+    }, true);
+});
+
+async function simulateVariation(index, btn) {
+    const bossData = JSON.parse(document.getElementById('boss-data').textContent);
+    const container = document.querySelector(`.variation-table[data-variation-index="${index}"]`);
+    const summary = document.querySelector(`.simulation-summary[data-variation-index="${index}"]`);
+    if (!container || !summary) return;
+
+    // a second click removes the annotations
+    if (container.classList.contains('simulated')) {
+        clearSimulation(container, summary);
+        btn.textContent = '▶ Simulate';
+        return;
+    }
+
+    const params = new URLSearchParams({ boss_id: bossData.id, variation_id: container.dataset.variationId });
+    btn.disabled = true;
+    try {
+        const response = await fetch('/api/simulate?' + params.toString());
+        if (!response.ok) {
+            summary.textContent = 'Simulation unavailable: ' + (await response.text()).trim();
+            summary.className = 'simulation-summary error';
+            summary.hidden = false;
+            return;
+        }
+        const report = await response.json();
+        annotateVariation(container, summary, report);
+        btn.textContent = '✖ Hide simulation';
+    } catch (err) {
+        console.error('Simulation failed:', err);
+    } finally {
+        btn.disabled = false;
+    }
+}
+
+function clearSimulation(container, summary) {
+    container.classList.remove('simulated');
+    container.querySelectorAll('.sim-note').forEach(el => el.remove());
+    container.querySelectorAll('.sim-diverges').forEach(el => el.classList.remove('sim-diverges'));
+    summary.hidden = true;
+}
+
+function annotateVariation(container, summary, report) {
+    clearSimulation(container, summary);
+    container.classList.add('simulated');
+
+    const diverging = report.diverging_turns.length;
+    summary.textContent = diverging === 0
+        ? `Simulation matches the claimed boss health within ${report.tolerance}% on every turn (boss HP ${report.boss_hp}).`
+        : `Simulation differs from the claimed boss health by more than ${report.tolerance}% on turn${diverging > 1 ? 's' : ''} ${report.diverging_turns.join(', ')} (boss HP ${report.boss_hp}).`;
+    summary.title = 'Assumes ' + report.assumptions.join('; ');
+    summary.className = 'simulation-summary ' + (diverging === 0 ? 'ok' : 'warn');
+    summary.hidden = false;
+
+    const rows = container.querySelectorAll('tbody tr');
+    report.turns.forEach((turn, i) => {
+        const row = rows[i];
+        if (!row) return;
+
+        // phase effects and field state go under the turn number
+        const field = turn.events.map(e => `⚡ ${e.health}%: ${e.name}`);
+        if (turn.reflect) field.push('Reflect up');
+        if (turn.light_screen) field.push('Light Screen up');
+        if (turn.weather) field.push(`Weather: ${turn.weather}`);
+        if (field.length) {
+            row.cells[0].appendChild(simNote(field.join('\n')));
+        }
+
+        turn.actions.forEach(action => {
+            const cell = row.cells[1 + ['P1', 'P2', 'P3', 'P4'].indexOf(action.player)];
+            if (!cell) return;
+            const text = action.note || `${action.min}–${action.max} dmg`;
+            cell.appendChild(simNote(text));
+        });
+
+        const healthCell = row.querySelector('.boss-health');
+        if (healthCell) {
+            healthCell.appendChild(simNote(
+                `sim ${turn.simulated_health}% (${turn.simulated_min}–${turn.simulated_max})`));
+            if (turn.diverges) {
+                row.classList.add('sim-diverges');
+                healthCell.title = `Claimed ${turn.claimed_health}% is ${Math.abs(turn.diverges_by)}% outside the simulated range`;
+            }
+        }
+    });
+}
+
+function simNote(text) {
+    const el = document.createElement('div');
+    el.className = 'sim-note';
+    el.textContent = text;
+    return el;
+}
@@ -0,0 +1,65 @@
+// votes.js - Vote on variations and let admins and mods pin one to the top
+
+document.addEventListener('DOMContentLoaded', () => {
+    document.querySelectorAll('.vote-box').forEach(box => {
+        box.querySelectorAll('.vote-btn').forEach(btn => {
+            btn.addEventListener('click', () => {
+                // pressing your current vote again removes it
+                const vote = Number(btn.dataset.vote);
+                castVote(box, Number(box.dataset.userVote) === vote ? 0 : vote);
+            });
+        });
+    });
+
+    document.querySelectorAll('.pin-variation-btn').forEach(btn => {
+        btn.addEventListener('click', () => {
+            const pinned = btn.dataset.pinned === 'True' || btn.dataset.pinned === 'true';
+            pinVariation(pinned ? '' : btn.dataset.variationId, btn);
+        });
+    });
+});
+
+async function castVote(box, vote) {
+    const bossData = JSON.parse(document.getElementById('boss-data').textContent);
+    const buttons = box.querySelectorAll('.vote-btn');
+    buttons.forEach(b => b.disabled = true);
+    try {
+        const response = await fetch('/api/votes', {
+            method: 'POST',
+            headers: { 'Content-Type': 'application/json' },
+            body: JSON.stringify({ boss_id: bossData.id, variation_id: box.dataset.variationId, vote }),
+        });
+        if (!response.ok) {
+            alert('Could not save vote: ' + (await response.text()).trim());
+            return;
+        }
+        const tally = await response.json();
+        box.dataset.userVote = tally.user_vote;
+        const score = box.querySelector('.vote-score');
+        score.textContent = tally.score;
+        score.title = `${tally.up} up, ${tally.down} down`;
+        buttons.forEach(b => b.classList.toggle('active', Number(b.dataset.vote) === tally.user_vote));
+    } finally {
+        buttons.forEach(b => b.disabled = false);
+    }
+}
+
+async function pinVariation(variationId, btn) {
+    const bossData = JSON.parse(document.getElementById('boss-data').textContent);
+    btn.disabled = true;
+    try {
+        const response = await fetch('/api/admin/variation-pin', {
+            method: 'POST',
+            headers: { 'Content-Type': 'application/json' },
+            body: JSON.stringify({ boss_id: bossData.id, variation_id: variationId }),
+        });
+        if (!response.ok) {
+            alert('Could not pin variation: ' + (await response.text()).trim());
+            return;
+        }
+        // the ranking is rendered by the server
+        window.location.reload();
+    } finally {
+        btn.disabled = false;
+    }
+}
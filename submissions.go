@@ -0,0 +1,465 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Submission statuses; only approved submissions are applied to the live boss
+const (
+	submissionPending          = "pending"
+	submissionApproved         = "approved"
+	submissionRejected         = "rejected"
+	submissionChangesRequested = "changes_requested"
+)
+
+var (
+	// anonSubmissions lets visitors without an account submit variations for review
+	anonSubmissions = getEnvOrDefault("ANON_SUBMISSIONS", "false") == "true"
+
+	// anonSubmissionsPerHour limits anonymous submissions per client IP
+	anonSubmissionsPerHour = func() int {
+		n, err := strconv.Atoi(getEnvOrDefault("ANON_SUBMISSIONS_PER_HOUR", "3"))
+		if err != nil || n < 1 {
+			return 3
+		}
+		return n
+	}()
+
+	errSubmissionNotFound = errors.New("submission not found")
+	errSubmissionClosed   = errors.New("submission was already reviewed")
+)
+
+// VariationSubmission is a proposed new or edited variation awaiting moderation
+type VariationSubmission struct {
+	ID           primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	Season       string             `json:"season" bson:"season"`
+	BossID       string             `json:"boss_id" bson:"boss_id"`
+	BossName     string             `json:"boss_name" bson:"boss_name"`
+	NewVariation bool               `json:"new_variation" bson:"new_variation"`
+	BaseRevision int                `json:"base_revision" bson:"base_revision"` // revision of the edited variation the proposal is based on
+	Variation    Variation          `json:"variation" bson:"variation"`
+	Status       string             `json:"status" bson:"status"`
+	Reason       string             `json:"reason,omitempty" bson:"reason,omitempty"`
+	SubmittedBy  string             `json:"submitted_by" bson:"submitted_by"` // username, empty for anonymous
+	SubmittedAt  time.Time          `json:"submitted_at" bson:"submitted_at"`
+	ReviewedBy   string             `json:"reviewed_by,omitempty" bson:"reviewed_by,omitempty"`
+	ReviewedAt   *time.Time         `json:"reviewed_at,omitempty" bson:"reviewed_at,omitempty"`
+}
+
+// submissionStore keeps variation submissions in the variation_submissions collection
+type submissionStore struct {
+	coll *mongo.Collection
+}
+
+func newSubmissionStore(db *mongo.Database) *submissionStore {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	coll := db.Collection("variation_submissions")
+	_, err := coll.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "status", Value: 1}, {Key: "submitted_at", Value: 1}},
+	})
+	if err != nil {
+		log.Printf("warning: failed to create variation_submissions index: %v", err)
+	}
+	return &submissionStore{coll: coll}
+}
+
+func (s *submissionStore) Create(ctx context.Context, sub *VariationSubmission) error {
+	res, err := s.coll.InsertOne(ctx, sub)
+	if err != nil {
+		return err
+	}
+	sub.ID = res.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+func (s *submissionStore) Get(ctx context.Context, id primitive.ObjectID) (*VariationSubmission, error) {
+	var sub VariationSubmission
+	err := s.coll.FindOne(ctx, bson.M{"_id": id}).Decode(&sub)
+	if err == mongo.ErrNoDocuments {
+		return nil, errSubmissionNotFound
+	} else if err != nil {
+		return nil, err
+	}
+	return &sub, nil
+}
+
+// List returns submissions matching filter, oldest first
+func (s *submissionStore) List(ctx context.Context, filter bson.M) ([]VariationSubmission, error) {
+	cursor, err := s.coll.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "submitted_at", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	subs := []VariationSubmission{}
+	if err := cursor.All(ctx, &subs); err != nil {
+		return nil, err
+	}
+	return subs, nil
+}
+
+// Revise replaces the proposal of an open submission and puts it back in the queue
+func (s *submissionStore) Revise(ctx context.Context, id primitive.ObjectID, author string, sub VariationSubmission) error {
+	res, err := s.coll.UpdateOne(ctx,
+		bson.M{"_id": id, "submitted_by": author, "status": bson.M{"$in": []string{submissionPending, submissionChangesRequested}}},
+		bson.M{
+			"$set": bson.M{
+				"season":        sub.Season,
+				"boss_id":       sub.BossID,
+				"boss_name":     sub.BossName,
+				"new_variation": sub.NewVariation,
+				"base_revision": sub.BaseRevision,
+				"variation":     sub.Variation,
+				"status":        submissionPending,
+				"submitted_at":  sub.SubmittedAt,
+			},
+			"$unset": bson.M{"reason": "", "reviewed_by": "", "reviewed_at": ""},
+		},
+	)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return errSubmissionNotFound
+	}
+	return nil
+}
+
+// Review moves a pending submission to its final status
+func (s *submissionStore) Review(ctx context.Context, id primitive.ObjectID, status, reason, reviewer string) error {
+	res, err := s.coll.UpdateOne(ctx,
+		bson.M{"_id": id, "status": submissionPending},
+		bson.M{"$set": bson.M{
+			"status":      status,
+			"reason":      reason,
+			"reviewed_by": reviewer,
+			"reviewed_at": time.Now(),
+		}},
+	)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return errSubmissionClosed
+	}
+	return nil
+}
+
+// Reopen returns a reviewed submission to the queue, used when applying an approval fails
+func (s *submissionStore) Reopen(ctx context.Context, id primitive.ObjectID) error {
+	_, err := s.coll.UpdateOne(ctx, bson.M{"_id": id}, bson.M{
+		"$set":   bson.M{"status": submissionPending},
+		"$unset": bson.M{"reason": "", "reviewed_by": "", "reviewed_at": ""},
+	})
+	return err
+}
+
+// rateLimiter allows a fixed number of events per key within a sliding window.
+// Keys without events in the window are dropped once per window.
+type rateLimiter struct {
+	mu        sync.Mutex
+	limit     int
+	window    time.Duration
+	events    map[string][]time.Time
+	lastSweep time.Time
+}
+
+func newRateLimiter(limit int, window time.Duration) *rateLimiter {
+	return &rateLimiter{limit: limit, window: window, events: make(map[string][]time.Time)}
+}
+
+// Allow records an event for key and reports whether it is within the limit
+func (l *rateLimiter) Allow(key string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(l.lastSweep) >= l.window {
+		l.sweep(now)
+	}
+	recent := l.events[key][:0]
+	for _, t := range l.events[key] {
+		if now.Sub(t) < l.window {
+			recent = append(recent, t)
+		}
+	}
+	if len(recent) >= l.limit {
+		l.events[key] = recent
+		return false
+	}
+	l.events[key] = append(recent, now)
+	return true
+}
+
+// sweep drops the keys whose newest event is outside the window
+func (l *rateLimiter) sweep(now time.Time) {
+	for key, times := range l.events {
+		if len(times) == 0 || now.Sub(times[len(times)-1]) >= l.window {
+			delete(l.events, key)
+		}
+	}
+	l.lastSweep = now
+}
+
+var anonSubmissionLimiter = newRateLimiter(anonSubmissionsPerHour, time.Hour)
+
+// trustedProxies are the addresses allowed to set X-Real-IP, from the
+// comma-separated IPs and CIDRs in TRUSTED_PROXIES
+var trustedProxies = parseTrustedProxies(getEnvOrDefault("TRUSTED_PROXIES", "127.0.0.1,::1"))
+
+func parseTrustedProxies(list string) []*net.IPNet {
+	var nets []*net.IPNet
+	for _, entry := range strings.Split(list, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if !strings.Contains(entry, "/") {
+			if ip := net.ParseIP(entry); ip != nil && ip.To4() != nil {
+				entry += "/32"
+			} else {
+				entry += "/128"
+			}
+		}
+		_, n, err := net.ParseCIDR(entry)
+		if err != nil {
+			log.Printf("warning: ignoring invalid TRUSTED_PROXIES entry %q", entry)
+			continue
+		}
+		nets = append(nets, n)
+	}
+	return nets
+}
+
+// clientIP returns the visitor address. The X-Real-IP set by nginx is only
+// used when the request comes from one of trustedProxies, so visitors reaching
+// the app directly cannot pick their own address.
+func clientIP(r *http.Request, trusted []*net.IPNet) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	if ip := strings.TrimSpace(r.Header.Get("X-Real-IP")); ip != "" {
+		if remote := net.ParseIP(host); remote != nil {
+			for _, n := range trusted {
+				if n.Contains(remote) {
+					return ip
+				}
+			}
+		}
+	}
+	return host
+}
+
+// submitVariation queues a variation proposed by an author or anonymous visitor
+func (a *App) submitVariation(w http.ResponseWriter, r *http.Request, season, bossID, submissionID string, variation Variation, creating bool, expected int) {
+	author := getUsernameFromRequest(r)
+	if author == "" && !anonSubmissionLimiter.Allow(clientIP(r, trustedProxies)) {
+		http.Error(w, "too many submissions, try again later", http.StatusTooManyRequests)
+		return
+	}
+
+	target, ok := a.store.FindSeason(season)
+	if !ok {
+		http.Error(w, errSeasonNotFound.Error(), http.StatusNotFound)
+		return
+	}
+	boss := findBossByID(&target, bossID)
+	if boss == nil {
+		http.Error(w, errBossNotFound.Error(), http.StatusNotFound)
+		return
+	}
+	if !creating && findVariationIndex(boss, variation.ID) < 0 {
+		http.Error(w, errVariationNotFound.Error(), http.StatusNotFound)
+		return
+	}
+
+	sub := VariationSubmission{
+		Season:       season,
+		BossID:       bossID,
+		BossName:     boss.Name,
+		NewVariation: creating,
+		BaseRevision: expected,
+		Variation:    variation,
+		Status:       submissionPending,
+		SubmittedBy:  author,
+		SubmittedAt:  time.Now(),
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	var err error
+	if submissionID != "" {
+		id, perr := primitive.ObjectIDFromHex(submissionID)
+		if perr != nil || author == "" {
+			http.Error(w, "invalid submission_id", http.StatusBadRequest)
+			return
+		}
+		sub.ID = id
+		err = a.submissions.Revise(ctx, id, author, sub)
+	} else {
+		err = a.submissions.Create(ctx, &sub)
+	}
+	if errors.Is(err, errSubmissionNotFound) {
+		http.Error(w, "submission not found or already reviewed", http.StatusNotFound)
+		return
+	} else if err != nil {
+		log.Printf("Error saving submission: %v", err)
+		http.Error(w, "failed to submit variation", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]string{"status": submissionPending, "submission_id": sub.ID.Hex()})
+}
+
+// submissionsHandler lists the signed-in author's own submissions with review feedback
+func (a *App) submissionsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	author := getUsernameFromRequest(r)
+	if author == "" {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	subs, err := a.submissions.List(ctx, bson.M{"submitted_by": author})
+	if err != nil {
+		log.Printf("Error listing submissions: %v", err)
+		http.Error(w, "failed to load submissions", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(subs)
+}
+
+// adminSubmissionsHandler lists submissions by status (default pending) for mods and admins
+func (a *App) adminSubmissionsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	role := getRoleFromRequest(r)
+	if role != "admin" && role != "mod" {
+		http.Error(w, "forbidden", http.StatusForbidden)
+		return
+	}
+	status := r.URL.Query().Get("status")
+	if status == "" {
+		status = submissionPending
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	subs, err := a.submissions.List(ctx, bson.M{"status": status})
+	if err != nil {
+		log.Printf("Error listing submissions: %v", err)
+		http.Error(w, "failed to load submissions", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(subs)
+}
+
+// adminSubmissionReviewHandler approves, rejects or requests changes on a pending submission
+func (a *App) adminSubmissionReviewHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	role := getRoleFromRequest(r)
+	if role != "admin" && role != "mod" {
+		http.Error(w, "forbidden", http.StatusForbidden)
+		return
+	}
+	var req struct {
+		ID     string `json:"id"`
+		Action string `json:"action"` // "approve", "reject" or "request_changes"
+		Reason string `json:"reason"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	id, err := primitive.ObjectIDFromHex(req.ID)
+	if err != nil {
+		http.Error(w, "invalid submission id", http.StatusBadRequest)
+		return
+	}
+	req.Reason = strings.TrimSpace(req.Reason)
+
+	var status string
+	switch req.Action {
+	case "approve":
+		status = submissionApproved
+	case "reject":
+		status = submissionRejected
+	case "request_changes":
+		status = submissionChangesRequested
+	default:
+		http.Error(w, "action must be approve, reject or request_changes", http.StatusBadRequest)
+		return
+	}
+	if status != submissionApproved && req.Reason == "" {
+		http.Error(w, "reason required", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	sub, err := a.submissions.Get(ctx, id)
+	if errors.Is(err, errSubmissionNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	} else if err != nil {
+		log.Printf("Error loading submission: %v", err)
+		http.Error(w, "failed to load submission", http.StatusInternalServerError)
+		return
+	}
+
+	// closing the submission first means two reviewers cannot both apply it
+	err = a.submissions.Review(ctx, id, status, req.Reason, getUsernameFromRequest(r))
+	if errors.Is(err, errSubmissionClosed) {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	} else if err != nil {
+		log.Printf("Error reviewing submission: %v", err)
+		http.Error(w, "failed to review submission", http.StatusInternalServerError)
+		return
+	}
+	if status == submissionApproved {
+		// on failure, e.g. a revision conflict, the submission goes back to the queue
+		// so the reviewer can request changes instead
+		// the submitter is credited with the change, not the reviewer
+		stampVariation(&sub.Variation, sub.SubmittedBy, time.Now(), sub.NewVariation)
+		if err := a.applyVariation(r, sub.Season, sub.BossID, sub.Variation, sub.NewVariation, sub.BaseRevision); err != nil {
+			if rerr := a.submissions.Reopen(ctx, id); rerr != nil {
+				log.Printf("Error reopening submission %s: %v", req.ID, rerr)
+			}
+			writeVariationError(w, err)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": status})
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestClientIP(t *testing.T) {
+	trusted := parseTrustedProxies("127.0.0.1, 172.16.0.0/12, bogus")
+	if len(trusted) != 2 {
+		t.Fatalf("parsed %d trusted proxies, want 2", len(trusted))
+	}
+
+	tests := []struct {
+		name     string
+		remote   string
+		realIP   string
+		expected string
+	}{
+		{"direct visitor", "203.0.113.5:4321", "", "203.0.113.5"},
+		{"spoofed header", "203.0.113.5:4321", "198.51.100.7", "203.0.113.5"},
+		{"loopback proxy", "127.0.0.1:4321", "198.51.100.7", "198.51.100.7"},
+		{"compose network proxy", "172.18.0.3:4321", "198.51.100.7", "198.51.100.7"},
+		{"trusted proxy without header", "172.18.0.3:4321", "", "172.18.0.3"},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest("POST", "/api/boss/save-variation", nil)
+		r.RemoteAddr = tt.remote
+		if tt.realIP != "" {
+			r.Header.Set("X-Real-IP", tt.realIP)
+		}
+		if got := clientIP(r, trusted); got != tt.expected {
+			t.Errorf("%s: clientIP = %q, want %q", tt.name, got, tt.expected)
+		}
+	}
+}
+
+func TestRateLimiterDropsIdleKeys(t *testing.T) {
+	l := newRateLimiter(2, 20*time.Millisecond)
+	for i, want := range []bool{true, true, false} {
+		if got := l.Allow("203.0.113.5"); got != want {
+			t.Fatalf("event %d allowed = %v, want %v", i, got, want)
+		}
+	}
+	if !l.Allow("198.51.100.7") {
+		t.Fatal("limit shared between keys")
+	}
+
+	time.Sleep(25 * time.Millisecond)
+	if !l.Allow("192.0.2.1") {
+		t.Fatal("event refused after the window passed")
+	}
+	if len(l.events) != 1 {
+		t.Errorf("limiter still holds %d keys, want only the active one", len(l.events))
+	}
+}
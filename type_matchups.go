@@ -0,0 +1,184 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"pokemmoraids/typechart"
+)
+
+// typeMultiplier is one attacking type and its multiplier against a defender
+type typeMultiplier struct {
+	Type       string  `json:"type"`
+	Multiplier float64 `json:"multiplier"`
+}
+
+// Label formats the multiplier for display, e.g. "x0.25"
+func (t typeMultiplier) Label() string {
+	return "x" + strconv.FormatFloat(t.Multiplier, 'f', -1, 64)
+}
+
+// moveThreat lists the types a boss move hits super effectively
+type moveThreat struct {
+	Move           string   `json:"move"`
+	Type           string   `json:"type"`
+	SuperEffective []string `json:"super_effective"`
+}
+
+// bossMatchups summarises a boss's defensive type matchups and what its moves threaten
+type bossMatchups struct {
+	Types       []string         `json:"types"`
+	Weaknesses  []typeMultiplier `json:"weaknesses"`
+	Resistances []typeMultiplier `json:"resistances"`
+	Immunities  []string         `json:"immunities"`
+	MoveThreats []moveThreat     `json:"move_threats"`
+	MoveTypes   []string         `json:"move_types"`
+}
+
+// bossTypes returns the boss's recorded types, or those of its Pokémon in monster.json
+func (a *App) bossTypes(b RaidBoss) []string {
+	if types := typechart.NormalizeAll(b.Types); len(types) > 0 {
+		return types
+	}
+	if m, ok := a.gameData.bossMonster(b); ok {
+		return typechart.NormalizeAll(m.Types)
+	}
+	return nil
+}
+
+// pokemonTypes returns a checklist Pokémon's own types from monster.json,
+// falling back to the types recorded on the entry
+func (a *App) pokemonTypes(p PokemonChecklistEntry) []string {
+	if m, ok := a.gameData.monster(p.Name); ok {
+		if types := typechart.NormalizeAll(m.Types); len(types) > 0 {
+			return types
+		}
+	}
+	return typechart.NormalizeAll(p.Types)
+}
+
+// matchupsForBoss computes the boss's weaknesses, resistances and move threats.
+// Defensive lists are empty when the boss's types are unknown.
+func (a *App) matchupsForBoss(b RaidBoss) bossMatchups {
+	m := bossMatchups{
+		Types:       a.bossTypes(b),
+		Weaknesses:  []typeMultiplier{},
+		Resistances: []typeMultiplier{},
+		Immunities:  []string{},
+		MoveThreats: []moveThreat{},
+		MoveTypes:   []string{},
+	}
+	if len(m.Types) > 0 {
+		for _, t := range typechart.Types {
+			switch x := typechart.Effectiveness(t, m.Types...); {
+			case x == 0:
+				m.Immunities = append(m.Immunities, t)
+			case x > 1:
+				m.Weaknesses = append(m.Weaknesses, typeMultiplier{t, x})
+			case x < 1:
+				m.Resistances = append(m.Resistances, typeMultiplier{t, x})
+			}
+		}
+		// strongest weaknesses and resistances first
+		sort.SliceStable(m.Weaknesses, func(i, j int) bool { return m.Weaknesses[i].Multiplier > m.Weaknesses[j].Multiplier })
+		sort.SliceStable(m.Resistances, func(i, j int) bool { return m.Resistances[i].Multiplier < m.Resistances[j].Multiplier })
+	}
+
+	seen := map[string]bool{}
+	for _, mv := range b.Moves {
+		t, ok := typechart.Normalize(mv.Type)
+		if !ok {
+			continue
+		}
+		m.MoveThreats = append(m.MoveThreats, moveThreat{
+			Move:           strings.TrimSpace(mv.Name),
+			Type:           t,
+			SuperEffective: typechart.SuperEffective(t),
+		})
+		if !seen[t] {
+			seen[t] = true
+			m.MoveTypes = append(m.MoveTypes, t)
+		}
+	}
+	return m
+}
+
+// typeChartHandler returns the type chart, or with ?defend=Water,Ground the
+// multiplier of every attacking type against those types
+func (a *App) typeChartHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+
+	if defend := r.URL.Query().Get("defend"); defend != "" {
+		types := typechart.NormalizeAll(strings.Split(defend, ","))
+		if len(types) == 0 {
+			http.Error(w, "no known types in defend", http.StatusBadRequest)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"defend":      types,
+			"multipliers": typechart.Defensive(types...),
+		})
+		return
+	}
+
+	chart := make(map[string]map[string]float64, len(typechart.Types))
+	for _, t := range typechart.Types {
+		chart[t] = make(map[string]float64, len(typechart.Types))
+		for _, d := range typechart.Types {
+			chart[t][d] = typechart.Effectiveness(t, d)
+		}
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{"types": typechart.Types, "chart": chart})
+}
+
+// bossMatchupsHandler returns a boss's type matchups and the names of checklist
+// Pokémon that resist every one of its moves
+func (a *App) bossMatchupsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	current := a.store.Current()
+	boss := findBossByID(&current, r.URL.Query().Get("boss_id"))
+	if boss == nil {
+		http.Error(w, "boss not found", http.StatusNotFound)
+		return
+	}
+	m := a.matchupsForBoss(*boss)
+
+	resistant := []string{}
+	if a.mongoDB != nil && len(m.MoveTypes) > 0 {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		var doc ChecklistDocument
+		err := a.mongoDB.Collection("checklists").FindOne(ctx, bson.M{
+			"season":  a.getSeasonName(),
+			"user_id": checklistTemplateUser,
+		}).Decode(&doc)
+		if err == nil {
+			for _, p := range doc.Pokemon {
+				if typechart.Resists(m.MoveTypes, a.pokemonTypes(p)...) {
+					resistant = append(resistant, p.Name)
+				}
+			}
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"boss_id":           boss.ID,
+		"boss_name":         boss.Name,
+		"matchups":          m,
+		"resistant_pokemon": resistant,
+	})
+}
@@ -0,0 +1,108 @@
+// Package typechart implements the Gen 5 type chart used by PokeMMO: 17 types,
+// no Fairy, and Steel still resisting Ghost and Dark.
+package typechart
+
+import "strings"
+
+// Types lists every type in chart order
+var Types = []string{
+	"Normal", "Fire", "Water", "Electric", "Grass", "Ice", "Fighting", "Poison", "Ground",
+	"Flying", "Psychic", "Bug", "Rock", "Ghost", "Dragon", "Dark", "Steel",
+}
+
+// chart holds the non-neutral multipliers, attacking type -> defending type -> multiplier
+var chart = map[string]map[string]float64{
+	"Normal":   {"Rock": 0.5, "Ghost": 0, "Steel": 0.5},
+	"Fire":     {"Fire": 0.5, "Water": 0.5, "Grass": 2, "Ice": 2, "Bug": 2, "Rock": 0.5, "Dragon": 0.5, "Steel": 2},
+	"Water":    {"Fire": 2, "Water": 0.5, "Grass": 0.5, "Ground": 2, "Rock": 2, "Dragon": 0.5},
+	"Electric": {"Water": 2, "Electric": 0.5, "Grass": 0.5, "Ground": 0, "Flying": 2, "Dragon": 0.5},
+	"Grass":    {"Fire": 0.5, "Water": 2, "Grass": 0.5, "Poison": 0.5, "Ground": 2, "Flying": 0.5, "Bug": 0.5, "Rock": 2, "Dragon": 0.5, "Steel": 0.5},
+	"Ice":      {"Fire": 0.5, "Water": 0.5, "Grass": 2, "Ice": 0.5, "Ground": 2, "Flying": 2, "Dragon": 2, "Steel": 0.5},
+	"Fighting": {"Normal": 2, "Ice": 2, "Poison": 0.5, "Flying": 0.5, "Psychic": 0.5, "Bug": 0.5, "Rock": 2, "Ghost": 0, "Dark": 2, "Steel": 2},
+	"Poison":   {"Grass": 2, "Poison": 0.5, "Ground": 0.5, "Rock": 0.5, "Ghost": 0.5, "Steel": 0},
+	"Ground":   {"Fire": 2, "Electric": 2, "Grass": 0.5, "Poison": 2, "Flying": 0, "Bug": 0.5, "Rock": 2, "Steel": 2},
+	"Flying":   {"Electric": 0.5, "Grass": 2, "Fighting": 2, "Bug": 2, "Rock": 0.5, "Steel": 0.5},
+	"Psychic":  {"Fighting": 2, "Poison": 2, "Psychic": 0.5, "Dark": 0, "Steel": 0.5},
+	"Bug":      {"Fire": 0.5, "Grass": 2, "Fighting": 0.5, "Poison": 0.5, "Flying": 0.5, "Psychic": 2, "Ghost": 0.5, "Dark": 2, "Steel": 0.5},
+	"Rock":     {"Fire": 2, "Ice": 2, "Fighting": 0.5, "Ground": 0.5, "Flying": 2, "Bug": 2, "Steel": 0.5},
+	"Ghost":    {"Normal": 0, "Psychic": 2, "Ghost": 2, "Dark": 0.5, "Steel": 0.5},
+	"Dragon":   {"Dragon": 2, "Steel": 0.5},
+	"Dark":     {"Fighting": 0.5, "Psychic": 2, "Ghost": 2, "Dark": 0.5, "Steel": 0.5},
+	"Steel":    {"Fire": 0.5, "Water": 0.5, "Electric": 0.5, "Ice": 2, "Rock": 2, "Steel": 0.5},
+}
+
+// Normalize returns the canonical spelling of a type name such as "FIRE" or " fire "
+func Normalize(name string) (string, bool) {
+	name = strings.TrimSpace(name)
+	for _, t := range Types {
+		if strings.EqualFold(t, name) {
+			return t, true
+		}
+	}
+	return "", false
+}
+
+// NormalizeAll returns the canonical spelling of every known type in names, skipping unknown ones
+func NormalizeAll(names []string) []string {
+	out := make([]string, 0, len(names))
+	for _, n := range names {
+		if t, ok := Normalize(n); ok {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+// Effectiveness returns the multiplier of an attacking type against a Pokémon
+// with the given types. Unknown type names count as neutral.
+func Effectiveness(attack string, defend ...string) float64 {
+	a, ok := Normalize(attack)
+	if !ok {
+		return 1
+	}
+	m := 1.0
+	for _, d := range defend {
+		t, ok := Normalize(d)
+		if !ok {
+			continue
+		}
+		if v, ok := chart[a][t]; ok {
+			m *= v
+		}
+	}
+	return m
+}
+
+// Defensive returns the multiplier of every attacking type against the given types
+func Defensive(defend ...string) map[string]float64 {
+	out := make(map[string]float64, len(Types))
+	for _, a := range Types {
+		out[a] = Effectiveness(a, defend...)
+	}
+	return out
+}
+
+// SuperEffective lists the single types an attacking type hits for more than neutral damage
+func SuperEffective(attack string) []string {
+	var out []string
+	for _, d := range Types {
+		if Effectiveness(attack, d) > 1 {
+			out = append(out, d)
+		}
+	}
+	return out
+}
+
+// Resists reports whether a Pokémon with the given types takes less than
+// neutral damage from every attacking type. It is false when attacks is empty.
+func Resists(attacks []string, defend ...string) bool {
+	if len(attacks) == 0 {
+		return false
+	}
+	for _, a := range attacks {
+		if Effectiveness(a, defend...) >= 1 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,53 @@
+package typechart
+
+import "testing"
+
+func TestEffectiveness(t *testing.T) {
+	tests := []struct {
+		attack string
+		defend []string
+		want   float64
+	}{
+		{"Fire", []string{"Grass"}, 2},
+		{"FIRE", []string{"grass", "STEEL"}, 4},
+		{"Electric", []string{"Water", "Ground"}, 0},
+		{"Ghost", []string{"Steel"}, 0.5},
+		{"Dark", []string{"Steel"}, 0.5},
+		{"Ice", []string{"Fire", "Water"}, 0.25},
+		{"Dragon", []string{"Fairy"}, 1},
+		{"Shadow", []string{"Normal"}, 1},
+	}
+	for _, tt := range tests {
+		if got := Effectiveness(tt.attack, tt.defend...); got != tt.want {
+			t.Errorf("%s vs %v: got %v, want %v", tt.attack, tt.defend, got, tt.want)
+		}
+	}
+}
+
+func TestChartCoversKnownTypes(t *testing.T) {
+	for a, row := range chart {
+		if _, ok := Normalize(a); !ok {
+			t.Errorf("unknown attacking type %q", a)
+		}
+		for d := range row {
+			if _, ok := Normalize(d); !ok {
+				t.Errorf("%s: unknown defending type %q", a, d)
+			}
+		}
+	}
+	if len(chart) != len(Types) {
+		t.Errorf("chart has %d attacking types, want %d", len(chart), len(Types))
+	}
+}
+
+func TestResists(t *testing.T) {
+	if !Resists([]string{"Ice", "Water"}, "Water") {
+		t.Error("Water should resist Ice and Water")
+	}
+	if Resists([]string{"Ice", "Ghost"}, "Water") {
+		t.Error("Water should not resist Ghost")
+	}
+	if Resists(nil, "Water") {
+		t.Error("no attacks should not count as resisted")
+	}
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// FieldError describes one invalid field of a request payload
+type FieldError struct {
+	Field       string   `json:"field"`
+	Message     string   `json:"message"`
+	Suggestions []string `json:"suggestions,omitempty"`
+}
+
+// maxCreditsLength bounds the credits of a variation, in characters
+const maxCreditsLength = 200
+
+// validateVariation checks that a variation renders as a regular table: only
+// P1–P4 players, one known action per turn for every player, boss health that stays
+// within 0–100 and never increases, no more notes than turns and short credits. Field paths
+// are prefixed with prefix, e.g. "variations[2].".
+func validateVariation(v Variation, prefix string) []FieldError {
+	var errs []FieldError
+	add := func(field, format string, args ...interface{}) {
+		errs = append(errs, FieldError{Field: prefix + field, Message: fmt.Sprintf(format, args...)})
+	}
+
+	turns := len(v.HealthRemaining)
+	if turns == 0 {
+		add("health_remaining", "at least one turn is required")
+	}
+	for i, hp := range v.HealthRemaining {
+		switch {
+		case math.IsNaN(hp) || hp < 0 || hp > 100:
+			add(fmt.Sprintf("health_remaining[%d]", i), "must be between 0 and 100, got %v", hp)
+		case i > 0 && hp > v.HealthRemaining[i-1]:
+			add(fmt.Sprintf("health_remaining[%d]", i), "must not be higher than the previous turn (%v > %v)", hp, v.HealthRemaining[i-1])
+		}
+	}
+
+	keys := make([]string, 0, len(v.Players))
+	for k := range v.Players {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if !slices.Contains(playerPositions[:], k) {
+			add("players."+k, "unknown player position, expected one of P1, P2, P3, P4")
+			continue
+		}
+		if n := len(v.Players[k]); turns > 0 && n != turns {
+			add("players."+k, "has %d turns but health_remaining has %d", n, turns)
+		}
+		for t, p := range v.Players[k] {
+			field := fmt.Sprintf("players.%s[%d].", k, t)
+			if !slices.Contains(playerActions, p.Action) {
+				add(field+"action", "unknown action %q, expected one of %s", p.Action, strings.Join(playerActions, ", "))
+			}
+			if p.Target != "" && !slices.Contains(actionTargets, p.Target) {
+				add(field+"target", "unknown target %q, expected one of %s", p.Target, strings.Join(actionTargets, ", "))
+			}
+		}
+	}
+
+	if len(v.Notes) > turns {
+		add("notes", "has %d entries but there are only %d turns", len(v.Notes), turns)
+	}
+	if n := utf8.RuneCountInString(v.Credits); n > maxCreditsLength {
+		add("credits", "must be at most %d characters, got %d", maxCreditsLength, n)
+	}
+	return errs
+}
+
+// validateVariations validates every variation of a boss payload
+func validateVariations(vs []Variation) []FieldError {
+	var errs []FieldError
+	for i, v := range vs {
+		errs = append(errs, validateVariation(v, fmt.Sprintf("variations[%d].", i))...)
+	}
+	return errs
+}
+
+// the accepted weather, screen and stat parameters of phase effects
+var (
+	phaseWeatherNames = []string{"rain", "sun", "sand", "hail"}
+	phaseScreenNames  = []string{"reflect", "light_screen"}
+	phaseStatNames    = []string{"attack", "defense", "sp_attack", "sp_defense", "speed"}
+)
+
+// validatePhaseEffects checks each phase effect has a known kind, a name, a
+// health within 0–100 and the parameters its kind needs
+func validatePhaseEffects(phases []PhaseEffect) []FieldError {
+	var errs []FieldError
+	for i, pe := range phases {
+		add := func(field, format string, args ...interface{}) {
+			errs = append(errs, FieldError{Field: fmt.Sprintf("phase_effects[%d].%s", i, field), Message: fmt.Sprintf(format, args...)})
+		}
+		if pe.Health < 0 || pe.Health > 100 {
+			add("health", "must be between 0 and 100, got %d", pe.Health)
+		}
+		if strings.TrimSpace(pe.Name) == "" {
+			add("name", "is required")
+		}
+		switch pe.Kind {
+		case phaseWeather:
+			if !slices.Contains(phaseWeatherNames, pe.Params.Weather) {
+				add("params.weather", "must be one of %s", strings.Join(phaseWeatherNames, ", "))
+			}
+		case phaseScreen:
+			if !slices.Contains(phaseScreenNames, pe.Params.Screen) {
+				add("params.screen", "must be one of %s", strings.Join(phaseScreenNames, ", "))
+			}
+		case phaseStatus:
+			if strings.TrimSpace(pe.Params.Status) == "" {
+				add("params.status", "is required")
+			}
+		case phaseStatChange:
+			if len(pe.Params.Stats) == 0 {
+				add("params.stats", "at least one stat change is required")
+			}
+			for stat, n := range pe.Params.Stats {
+				if !slices.Contains(phaseStatNames, stat) {
+					add("params.stats."+stat, "unknown stat, expected one of %s", strings.Join(phaseStatNames, ", "))
+				} else if n < -6 || n > 6 || n == 0 {
+					add("params.stats."+stat, "must be a change of -6 to 6 stages, got %d", n)
+				}
+			}
+		case phaseHeal:
+			if pe.Params.Heal <= 0 || pe.Params.Heal > 100 {
+				add("params.heal", "must be between 1 and 100%%, got %d", pe.Params.Heal)
+			}
+		case phaseAdaptation, phaseCustom:
+		default:
+			add("kind", "unknown kind %q, expected one of %s", pe.Kind, strings.Join(phaseKinds, ", "))
+		}
+	}
+	return errs
+}
+
+// decodeField decodes a raw payload field into out, reporting a decode failure
+// as an error on field. An omitted or null field leaves out untouched.
+func decodeField(field string, raw json.RawMessage, out interface{}) []FieldError {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil
+	}
+	if err := json.Unmarshal(raw, out); err != nil {
+		return []FieldError{{Field: field, Message: err.Error()}}
+	}
+	return nil
+}
+
+// writeValidationErrors responds 400 with the field errors as JSON
+func writeValidationErrors(w http.ResponseWriter, errs []FieldError) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusBadRequest)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"error":  "validation failed",
+		"fields": errs,
+	})
+}
@@ -0,0 +1,64 @@
+package main
+
+import "testing"
+
+func TestValidateVariation(t *testing.T) {
+	turn := []Player{{Pokemon: "Golduck", Action: actionMove, Move: "Surf"}, {Pokemon: "Golduck", Action: actionMove, Move: "Surf"}}
+	tests := []struct {
+		name   string
+		v      Variation
+		fields []string
+	}{
+		{"valid", Variation{
+			Players:         map[string][]Player{"P1": turn, "P4": turn},
+			HealthRemaining: []float64{40, 0},
+			Notes:           []string{"", "done"},
+		}, nil},
+		{"trailing zero turns", Variation{
+			Players:         map[string][]Player{"P1": turn},
+			HealthRemaining: []float64{0, 0},
+		}, nil},
+		{"no turns", Variation{}, []string{"health_remaining"}},
+		{"unknown position", Variation{
+			Players:         map[string][]Player{"P5": turn},
+			HealthRemaining: []float64{50, 10},
+		}, []string{"players.P5"}},
+		{"ragged players", Variation{
+			Players:         map[string][]Player{"P1": turn, "P2": turn[:1]},
+			HealthRemaining: []float64{50, 10},
+		}, []string{"players.P2"}},
+		{"health out of range and increasing", Variation{
+			Players:         map[string][]Player{"P1": append(turn, turn...)},
+			HealthRemaining: []float64{120, 50, 60, -1},
+		}, []string{"health_remaining[0]", "health_remaining[2]", "health_remaining[3]"}},
+		{"unknown action and target", Variation{
+			Players:         map[string][]Player{"P1": {{Action: "dance", Target: "P5"}}},
+			HealthRemaining: []float64{10},
+		}, []string{"players.P1[0].action", "players.P1[0].target"}},
+		{"too many notes", Variation{
+			Players:         map[string][]Player{"P1": turn[:1]},
+			HealthRemaining: []float64{10},
+			Notes:           []string{"a", "b"},
+		}, []string{"notes"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := validateVariation(tt.v, "")
+			if len(errs) != len(tt.fields) {
+				t.Fatalf("got %+v, want errors for %v", errs, tt.fields)
+			}
+			for i, e := range errs {
+				if e.Field != tt.fields[i] {
+					t.Errorf("error %d on %q, want %q", i, e.Field, tt.fields[i])
+				}
+			}
+		})
+	}
+}
+
+func TestValidateVariationsPrefixesIndex(t *testing.T) {
+	errs := validateVariations([]Variation{{HealthRemaining: []float64{10}}, {}})
+	if len(errs) != 1 || errs[0].Field != "variations[1].health_remaining" {
+		t.Fatalf("got %+v", errs)
+	}
+}
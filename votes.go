@@ -0,0 +1,330 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// VariationVote is one user's up (1) or down (-1) vote on a variation
+type VariationVote struct {
+	BossID      string    `json:"boss_id" bson:"boss_id"`
+	VariationID string    `json:"variation_id" bson:"variation_id"`
+	Username    string    `json:"username" bson:"username"`
+	Vote        int       `json:"vote" bson:"vote"`
+	VotedAt     time.Time `json:"voted_at" bson:"voted_at"`
+}
+
+// VoteTally counts the votes of a variation
+type VoteTally struct {
+	Up   int `json:"up" bson:"up"`
+	Down int `json:"down" bson:"down"`
+}
+
+// Score is the net vote count variations are ranked by
+func (t VoteTally) Score() int {
+	return t.Up - t.Down
+}
+
+// VariationPin is the variation admins and mods keep at the top of a boss page
+type VariationPin struct {
+	BossID      string    `json:"boss_id" bson:"boss_id"`
+	VariationID string    `json:"variation_id" bson:"variation_id"`
+	PinnedBy    string    `json:"pinned_by" bson:"pinned_by"`
+	PinnedAt    time.Time `json:"pinned_at" bson:"pinned_at"`
+}
+
+// voteStore keeps votes in the variation_votes collection and pins in variation_pins
+type voteStore struct {
+	votes *mongo.Collection
+	pins  *mongo.Collection
+}
+
+func newVoteStore(db *mongo.Database) *voteStore {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	votes := db.Collection("variation_votes")
+	_, err := votes.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "variation_id", Value: 1}, {Key: "username", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "boss_id", Value: 1}}},
+	})
+	if err != nil {
+		log.Printf("warning: failed to create variation_votes indexes: %v", err)
+	}
+	pins := db.Collection("variation_pins")
+	_, err = pins.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "boss_id", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		log.Printf("warning: failed to create variation_pins index: %v", err)
+	}
+	return &voteStore{votes: votes, pins: pins}
+}
+
+// Vote records a user's vote on a variation, replacing their earlier one; 0 removes it
+func (s *voteStore) Vote(ctx context.Context, v VariationVote) error {
+	filter := bson.M{"variation_id": v.VariationID, "username": v.Username}
+	if v.Vote == 0 {
+		_, err := s.votes.DeleteOne(ctx, filter)
+		return err
+	}
+	_, err := s.votes.ReplaceOne(ctx, filter, v, options.Replace().SetUpsert(true))
+	return err
+}
+
+// Tallies counts the votes of each variation of a boss
+func (s *voteStore) Tallies(ctx context.Context, bossID string) (map[string]VoteTally, error) {
+	cursor, err := s.votes.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"boss_id": bossID}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":  "$variation_id",
+			"up":   bson.M{"$sum": bson.M{"$cond": bson.A{bson.M{"$gt": bson.A{"$vote", 0}}, 1, 0}}},
+			"down": bson.M{"$sum": bson.M{"$cond": bson.A{bson.M{"$lt": bson.A{"$vote", 0}}, 1, 0}}},
+		}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var rows []struct {
+		VariationID string `bson:"_id"`
+		VoteTally   `bson:",inline"`
+	}
+	if err := cursor.All(ctx, &rows); err != nil {
+		return nil, err
+	}
+	tallies := make(map[string]VoteTally, len(rows))
+	for _, row := range rows {
+		tallies[row.VariationID] = row.VoteTally
+	}
+	return tallies, nil
+}
+
+// UserVotes returns username's votes on the variations of a boss
+func (s *voteStore) UserVotes(ctx context.Context, bossID, username string) (map[string]int, error) {
+	votes := map[string]int{}
+	if username == "" {
+		return votes, nil
+	}
+	cursor, err := s.votes.Find(ctx, bson.M{"boss_id": bossID, "username": username})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var rows []VariationVote
+	if err := cursor.All(ctx, &rows); err != nil {
+		return nil, err
+	}
+	for _, v := range rows {
+		votes[v.VariationID] = v.Vote
+	}
+	return votes, nil
+}
+
+// Pinned returns the variation pinned on a boss, or ""
+func (s *voteStore) Pinned(ctx context.Context, bossID string) (string, error) {
+	var pin VariationPin
+	err := s.pins.FindOne(ctx, bson.M{"boss_id": bossID}).Decode(&pin)
+	if err == mongo.ErrNoDocuments {
+		return "", nil
+	} else if err != nil {
+		return "", err
+	}
+	return pin.VariationID, nil
+}
+
+// Pin pins a variation on its boss, replacing any earlier pin; an empty variation unpins
+func (s *voteStore) Pin(ctx context.Context, pin VariationPin) error {
+	filter := bson.M{"boss_id": pin.BossID}
+	if pin.VariationID == "" {
+		_, err := s.pins.DeleteOne(ctx, filter)
+		return err
+	}
+	_, err := s.pins.ReplaceOne(ctx, filter, pin, options.Replace().SetUpsert(true))
+	return err
+}
+
+// rankVariations returns the variations with the pinned one first and the rest by
+// score, keeping the bosses.json order for ties. The first is recommended when it is
+// pinned or has a positive score. Variation numbers are not changed.
+func rankVariations(vs []Variation, tallies map[string]VoteTally, pinned string) []Variation {
+	ranked := make([]Variation, len(vs))
+	copy(ranked, vs)
+	for i := range ranked {
+		ranked[i].Votes = tallies[ranked[i].ID]
+		ranked[i].Pinned = pinned != "" && ranked[i].ID == pinned
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		if ranked[i].Pinned != ranked[j].Pinned {
+			return ranked[i].Pinned
+		}
+		return ranked[i].Votes.Score() > ranked[j].Votes.Score()
+	})
+	if len(ranked) > 0 && (ranked[0].Pinned || ranked[0].Votes.Score() > 0) {
+		ranked[0].Recommended = true
+	}
+	return ranked
+}
+
+// rankBoss orders a boss's variations by rank for the boss page and fills in
+// username's votes; errors are logged and leave the bosses.json order
+func (a *App) rankBoss(ctx context.Context, boss *RaidBoss, username string) {
+	tallies, err := a.votes.Tallies(ctx, boss.ID)
+	if err != nil {
+		log.Printf("Error loading votes for %s: %v", boss.ID, err)
+		return
+	}
+	pinned, err := a.votes.Pinned(ctx, boss.ID)
+	if err != nil {
+		log.Printf("Error loading pin for %s: %v", boss.ID, err)
+	}
+	boss.Variations = rankVariations(boss.Variations, tallies, pinned)
+	mine, err := a.votes.UserVotes(ctx, boss.ID, username)
+	if err != nil {
+		log.Printf("Error loading votes of %s: %v", username, err)
+		return
+	}
+	for i := range boss.Variations {
+		boss.Variations[i].UserVote = mine[boss.Variations[i].ID]
+	}
+}
+
+// votesHandler returns the ranking of a boss's variations with the signed-in
+// user's votes (GET) or records the user's vote on a variation (POST)
+func (a *App) votesHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	username := getUsernameFromRequest(r)
+
+	switch r.Method {
+	case http.MethodGet:
+		current := a.store.Current()
+		found := findBossByID(&current, r.URL.Query().Get("boss_id"))
+		if found == nil {
+			http.Error(w, errBossNotFound.Error(), http.StatusNotFound)
+			return
+		}
+		boss := *found
+		a.rankBoss(ctx, &boss, username)
+		ranking := make([]map[string]interface{}, len(boss.Variations))
+		for i, v := range boss.Variations {
+			ranking[i] = map[string]interface{}{
+				"variation_id": v.ID,
+				"variation":    v.Index,
+				"up":           v.Votes.Up,
+				"down":         v.Votes.Down,
+				"score":        v.Votes.Score(),
+				"pinned":       v.Pinned,
+				"recommended":  v.Recommended,
+				"user_vote":    v.UserVote,
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"boss_id": boss.ID, "variations": ranking})
+	case http.MethodPost:
+		if username == "" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		var req struct {
+			BossID      string `json:"boss_id"`
+			VariationID string `json:"variation_id"`
+			Vote        int    `json:"vote"` // 1, -1, or 0 to remove the vote
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "invalid request body", http.StatusBadRequest)
+			return
+		}
+		if req.Vote < -1 || req.Vote > 1 {
+			http.Error(w, "vote must be 1, -1 or 0", http.StatusBadRequest)
+			return
+		}
+		current := a.store.Current()
+		boss := findBossByID(&current, req.BossID)
+		if boss == nil {
+			http.Error(w, errBossNotFound.Error(), http.StatusNotFound)
+			return
+		}
+		if findVariationIndex(boss, req.VariationID) < 0 {
+			http.Error(w, errVariationNotFound.Error(), http.StatusNotFound)
+			return
+		}
+		vote := VariationVote{BossID: boss.ID, VariationID: req.VariationID, Username: username, Vote: req.Vote, VotedAt: time.Now()}
+		if err := a.votes.Vote(ctx, vote); err != nil {
+			log.Printf("Error saving vote: %v", err)
+			http.Error(w, "failed to save vote", http.StatusInternalServerError)
+			return
+		}
+		tallies, err := a.votes.Tallies(ctx, boss.ID)
+		if err != nil {
+			log.Printf("Error loading votes: %v", err)
+			http.Error(w, "failed to load votes", http.StatusInternalServerError)
+			return
+		}
+		t := tallies[req.VariationID]
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"variation_id": req.VariationID,
+			"up":           t.Up,
+			"down":         t.Down,
+			"score":        t.Score(),
+			"user_vote":    req.Vote,
+		})
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// adminVariationPinHandler pins a variation to the top of its boss page, or
+// unpins the boss's variation when variation_id is empty (admins and mods)
+func (a *App) adminVariationPinHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	role := getRoleFromRequest(r)
+	if role != "admin" && role != "mod" {
+		http.Error(w, "forbidden", http.StatusForbidden)
+		return
+	}
+	var req struct {
+		BossID      string `json:"boss_id"`
+		VariationID string `json:"variation_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	current := a.store.Current()
+	boss := findBossByID(&current, req.BossID)
+	if boss == nil {
+		http.Error(w, errBossNotFound.Error(), http.StatusNotFound)
+		return
+	}
+	if req.VariationID != "" && findVariationIndex(boss, req.VariationID) < 0 {
+		http.Error(w, errVariationNotFound.Error(), http.StatusNotFound)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	pin := VariationPin{BossID: boss.ID, VariationID: req.VariationID, PinnedBy: getUsernameFromRequest(r), PinnedAt: time.Now()}
+	if err := a.votes.Pin(ctx, pin); err != nil {
+		log.Printf("Error pinning variation: %v", err)
+		http.Error(w, "failed to pin variation", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": "success", "boss_id": boss.ID, "variation_id": req.VariationID})
+}
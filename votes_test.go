@@ -0,0 +1,42 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestRankVariations(t *testing.T) {
+	vs := []Variation{{ID: "v1", Index: 1}, {ID: "v2", Index: 2}, {ID: "v3", Index: 3}, {ID: "v4", Index: 4}}
+	tallies := map[string]VoteTally{"v2": {Up: 1, Down: 3}, "v3": {Up: 4, Down: 1}, "v4": {Up: 3}}
+
+	ids := func(ranked []Variation) []string {
+		out := make([]string, len(ranked))
+		for i, v := range ranked {
+			out[i] = v.ID
+		}
+		return out
+	}
+	ranked := rankVariations(vs, tallies, "")
+	if got, want := ids(ranked), []string{"v3", "v4", "v1", "v2"}; !slices.Equal(got, want) {
+		t.Errorf("by score: got %v, want %v", got, want)
+	}
+	if !ranked[0].Recommended || ranked[1].Recommended || ranked[0].Index != 3 {
+		t.Errorf("by score: top %+v", ranked[0])
+	}
+	if vs[0].ID != "v1" {
+		t.Error("rankVariations reordered its input")
+	}
+
+	ranked = rankVariations(vs, tallies, "v2")
+	if got, want := ids(ranked), []string{"v2", "v3", "v4", "v1"}; !slices.Equal(got, want) {
+		t.Errorf("pinned: got %v, want %v", got, want)
+	}
+	if !ranked[0].Pinned || !ranked[0].Recommended {
+		t.Errorf("pinned: top %+v", ranked[0])
+	}
+
+	ranked = rankVariations(vs, nil, "")
+	if got, want := ids(ranked), []string{"v1", "v2", "v3", "v4"}; !slices.Equal(got, want) || ranked[0].Recommended {
+		t.Errorf("no votes: got %v, recommended %v", got, ranked[0].Recommended)
+	}
+}